@@ -267,8 +267,8 @@ func ToV3Parameter(components *openapi3.Components, parameter *openapi2.Paramete
 			Enum:            parameter.Enum,
 			Min:             parameter.Minimum,
 			Max:             parameter.Maximum,
-			ExclusiveMin:    parameter.ExclusiveMin,
-			ExclusiveMax:    parameter.ExclusiveMax,
+			ExclusiveMin:    openapi3.ExclusiveBound{IsTrue: parameter.ExclusiveMin},
+			ExclusiveMax:    openapi3.ExclusiveBound{IsTrue: parameter.ExclusiveMax},
 			MinLength:       parameter.MinLength,
 			MaxLength:       parameter.MaxLength,
 			Default:         parameter.Default,
@@ -493,8 +493,8 @@ func ToV3SchemaRef(schema *openapi2.SchemaRef) *openapi3.SchemaRef {
 		Example:              schema.Value.Example,
 		ExternalDocs:         schema.Value.ExternalDocs,
 		UniqueItems:          schema.Value.UniqueItems,
-		ExclusiveMin:         schema.Value.ExclusiveMin,
-		ExclusiveMax:         schema.Value.ExclusiveMax,
+		ExclusiveMin:         openapi3.ExclusiveBound{IsTrue: schema.Value.ExclusiveMin},
+		ExclusiveMax:         openapi3.ExclusiveBound{IsTrue: schema.Value.ExclusiveMax},
 		ReadOnly:             schema.Value.ReadOnly,
 		WriteOnly:            schema.Value.WriteOnly,
 		AllowEmptyValue:      schema.Value.AllowEmptyValue,
@@ -834,6 +834,8 @@ func FromV3SchemaRef(schema *openapi3.SchemaRef, components *openapi3.Components
 		if schema.Value.Type.Is("string") && schema.Value.Format == "binary" {
 			paramType := &openapi3.Types{"file"}
 			required := false
+			minimum, exclusiveMin := fromV3Minimum(schema.Value)
+			maximum, exclusiveMax := fromV3Maximum(schema.Value)
 
 			value, _ := schema.Value.Extensions["x-formData-name"]
 			originalName, _ := value.(string)
@@ -849,10 +851,10 @@ func FromV3SchemaRef(schema *openapi3.SchemaRef, components *openapi3.Components
 				Description:  schema.Value.Description,
 				Type:         paramType,
 				Enum:         schema.Value.Enum,
-				Minimum:      schema.Value.Min,
-				Maximum:      schema.Value.Max,
-				ExclusiveMin: schema.Value.ExclusiveMin,
-				ExclusiveMax: schema.Value.ExclusiveMax,
+				Minimum:      minimum,
+				Maximum:      maximum,
+				ExclusiveMin: exclusiveMin,
+				ExclusiveMax: exclusiveMax,
 				MinLength:    schema.Value.MinLength,
 				MaxLength:    schema.Value.MaxLength,
 				Default:      schema.Value.Default,
@@ -868,6 +870,8 @@ func FromV3SchemaRef(schema *openapi3.SchemaRef, components *openapi3.Components
 		}
 	}
 
+	minimum, exclusiveMin := fromV3Minimum(schema.Value)
+	maximum, exclusiveMax := fromV3Maximum(schema.Value)
 	v2Schema := &openapi2.Schema{
 		Extensions:           schema.Value.Extensions,
 		Type:                 schema.Value.Type,
@@ -879,15 +883,15 @@ func FromV3SchemaRef(schema *openapi3.SchemaRef, components *openapi3.Components
 		Example:              schema.Value.Example,
 		ExternalDocs:         schema.Value.ExternalDocs,
 		UniqueItems:          schema.Value.UniqueItems,
-		ExclusiveMin:         schema.Value.ExclusiveMin,
-		ExclusiveMax:         schema.Value.ExclusiveMax,
+		ExclusiveMin:         exclusiveMin,
+		ExclusiveMax:         exclusiveMax,
 		ReadOnly:             schema.Value.ReadOnly,
 		WriteOnly:            schema.Value.WriteOnly,
 		AllowEmptyValue:      schema.Value.AllowEmptyValue,
 		Deprecated:           schema.Value.Deprecated,
 		XML:                  schema.Value.XML,
-		Min:                  schema.Value.Min,
-		Max:                  schema.Value.Max,
+		Min:                  minimum,
+		Max:                  maximum,
 		MultipleOf:           schema.Value.MultipleOf,
 		MinLength:            schema.Value.MinLength,
 		MaxLength:            schema.Value.MaxLength,
@@ -1006,6 +1010,8 @@ func FromV3RequestBodyFormData(mediaType *openapi3.MediaType) openapi2.Parameter
 		if val.Items != nil {
 			v2Items, _ = FromV3SchemaRef(val.Items, nil)
 		}
+		minimum, exclusiveMin := fromV3Minimum(val)
+		maximum, exclusiveMax := fromV3Maximum(val)
 		parameter := &openapi2.Parameter{
 			Name:         propName,
 			Description:  val.Description,
@@ -1013,16 +1019,16 @@ func FromV3RequestBodyFormData(mediaType *openapi3.MediaType) openapi2.Parameter
 			In:           "formData",
 			Extensions:   stripNonExtensions(val.Extensions),
 			Enum:         val.Enum,
-			ExclusiveMin: val.ExclusiveMin,
-			ExclusiveMax: val.ExclusiveMax,
+			ExclusiveMin: exclusiveMin,
+			ExclusiveMax: exclusiveMax,
 			MinLength:    val.MinLength,
 			MaxLength:    val.MaxLength,
 			Default:      val.Default,
 			Items:        v2Items,
 			MinItems:     val.MinItems,
 			MaxItems:     val.MaxItems,
-			Maximum:      val.Max,
-			Minimum:      val.Min,
+			Maximum:      maximum,
+			Minimum:      minimum,
 			Pattern:      val.Pattern,
 			// CollectionFormat: val.CollectionFormat,
 			// Format:          val.Format,
@@ -1297,6 +1303,24 @@ func stripNonExtensions(extensions map[string]any) map[string]any {
 	return extensions
 }
 
+// fromV3Minimum returns the minimum of schema and whether it is exclusive,
+// turning a numeric exclusiveMinimum into an exclusive minimum.
+func fromV3Minimum(schema *openapi3.Schema) (*float64, bool) {
+	if x := schema.ExclusiveMin.Value; x != nil && (schema.Min == nil || *x >= *schema.Min) {
+		return x, true
+	}
+	return schema.Min, schema.ExclusiveMin.IsTrue
+}
+
+// fromV3Maximum returns the maximum of schema and whether it is exclusive,
+// turning a numeric exclusiveMaximum into an exclusive maximum.
+func fromV3Maximum(schema *openapi3.Schema) (*float64, bool) {
+	if x := schema.ExclusiveMax.Value; x != nil && (schema.Max == nil || *x <= *schema.Max) {
+		return x, true
+	}
+	return schema.Max, schema.ExclusiveMax.IsTrue
+}
+
 func addPathExtensions(doc2 *openapi2.T, path string, extensions map[string]any) {
 	if doc2.Paths == nil {
 		doc2.Paths = make(map[string]*openapi2.PathItem)
@@ -859,3 +859,16 @@ const exampleRequestBodyV2 = `{
 	"swagger": "2.0"
 }
 `
+
+func TestFromV3SchemaNumericExclusiveBounds(t *testing.T) {
+	schema := &openapi3.SchemaRef{Value: &openapi3.Schema{
+		Type:         &openapi3.Types{"number"},
+		Min:          openapi3.Float64Ptr(0),
+		ExclusiveMin: openapi3.ExclusiveBound{Value: openapi3.Float64Ptr(1)},
+		ExclusiveMax: openapi3.ExclusiveBound{Value: openapi3.Float64Ptr(10)},
+	}}
+	v2, _ := FromV3SchemaRef(schema, nil)
+	data, err := json.Marshal(v2)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"number","minimum":1,"exclusiveMinimum":true,"maximum":10,"exclusiveMaximum":true}`, string(data))
+}
@@ -10,7 +10,8 @@ import (
 type Callback struct {
 	Extensions map[string]any `json:"-" yaml:"-"`
 
-	m map[string]*PathItem
+	m        map[string]*PathItem
+	position *Position
 }
 
 // NewCallback builds a Callback object with path items in insertion order.
@@ -35,8 +36,9 @@ func WithCallback(cb string, pathItem *PathItem) NewCallbackOption {
 }
 
 // Validate returns an error if Callback does not comply with the OpenAPI spec.
-func (callback *Callback) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (callback *Callback) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, callback.position) }()
 
 	keys := make([]string, 0, callback.Len())
 	for key := range callback.Map() {
@@ -0,0 +1,131 @@
+package openapi3
+
+import "reflect"
+
+// Clone returns a deep copy of the document, for changing it while keeping the original.
+// Values shared within the document, such as the values of references,
+// are shared within the copy too, and cycles are kept. References stay resolved.
+// Locations and positions are shared with the original.
+func (doc *T) Clone() *T { return clone(doc) }
+
+// Clone returns a deep copy of the components, see T.Clone.
+func (components *Components) Clone() *Components { return clone(components) }
+
+// Clone returns a deep copy of the paths, see T.Clone.
+func (paths *Paths) Clone() *Paths { return clone(paths) }
+
+// Clone returns a deep copy of the path item, see T.Clone.
+func (pathItem *PathItem) Clone() *PathItem { return clone(pathItem) }
+
+// Clone returns a deep copy of the operation, see T.Clone.
+func (operation *Operation) Clone() *Operation { return clone(operation) }
+
+// Clone returns a deep copy of the parameter, see T.Clone.
+func (parameter *Parameter) Clone() *Parameter { return clone(parameter) }
+
+// Clone returns a deep copy of the request body, see T.Clone.
+func (requestBody *RequestBody) Clone() *RequestBody { return clone(requestBody) }
+
+// Clone returns a deep copy of the response, see T.Clone.
+func (response *Response) Clone() *Response { return clone(response) }
+
+// Clone returns a deep copy of the schema, see T.Clone.
+func (schema *Schema) Clone() *Schema { return clone(schema) }
+
+// Clone returns a deep copy of the reference and of its value, see T.Clone.
+func (x *SchemaRef) Clone() *SchemaRef { return clone(x) }
+
+func clone[V any](v *V) *V {
+	if v == nil {
+		return nil
+	}
+	c := &cloner{copies: make(map[pointerIndexKey]reflect.Value)}
+	return c.clone(reflect.ValueOf(v)).Interface().(*V)
+}
+
+// cloner copies values, keeping the pointers they share.
+type cloner struct {
+	// copies maps the pointers, maps and slices copied to their copy.
+	copies map[pointerIndexKey]reflect.Value
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := pointerIndexKey{t: v.Type(), p: v.Pointer()}
+		if copied, ok := c.copies[key]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		c.copies[key] = copied
+		copied.Elem().Set(c.clone(v.Elem()))
+		c.unexported(copied.Interface())
+		return copied
+
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		// Unexported fields are set as is
+		copied.Set(v)
+		for i := 0; i < copied.NumField(); i++ {
+			if copied.Type().Field(i).IsExported() {
+				copied.Field(i).Set(c.clone(v.Field(i)))
+			}
+		}
+		return copied
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := pointerIndexKey{t: v.Type(), p: v.Pointer()}
+		if copied, ok := c.copies[key]; ok {
+			return copied
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		c.copies[key] = copied
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), c.clone(iter.Value()))
+		}
+		return copied
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(c.clone(v.Elem()))
+		return copied
+	}
+	return v
+}
+
+// unexported copies the unexported fields of v, a copy, holding values of the document.
+func (c *cloner) unexported(v any) {
+	switch x := v.(type) {
+	case *T:
+		x.visited = visitedComponent{}
+	case *Paths:
+		x.m = c.clone(reflect.ValueOf(x.m)).Interface().(map[string]*PathItem)
+	case *Responses:
+		x.m = c.clone(reflect.ValueOf(x.m)).Interface().(map[string]*ResponseRef)
+	case *Callback:
+		x.m = c.clone(reflect.ValueOf(x.m)).Interface().(map[string]*PathItem)
+	case *Schema:
+		x.dynamicRefTarget = c.clone(reflect.ValueOf(x.dynamicRefTarget)).Interface().(*SchemaRef)
+		x.dynamicAnchors = c.clone(reflect.ValueOf(x.dynamicAnchors)).Interface().(map[string]*Schema)
+	}
+}
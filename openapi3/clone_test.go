@@ -0,0 +1,69 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	loader := NewLoader()
+	doc, err := loader.LoadFromData([]byte(walkSpec))
+	require.NoError(t, err)
+
+	cloned := doc.Clone()
+	require.NotSame(t, doc, cloned)
+	require.NoError(t, cloned.Validate(context.Background()))
+
+	expected, err := json.Marshal(doc)
+	require.NoError(t, err)
+	data, err := json.Marshal(cloned)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(data))
+
+	// Shared values stay shared, and cycles are kept
+	node := cloned.Components.Schemas["Node"]
+	require.NotSame(t, doc.Components.Schemas["Node"].Value, node.Value)
+	require.Same(t, node.Value, node.Value.Properties["children"].Value.Items.Value)
+	post := cloned.Paths.Value("/trees").Post
+	require.Same(t, node.Value, post.RequestBody.Value.Content.Get("application/json").Schema.Value)
+	require.Same(t, cloned.Components.Responses["Created"].Value, post.Responses.Status(201).Value)
+	require.Equal(t, "#/components/schemas/Node", post.RequestBody.Value.Content.Get("application/json").Schema.Ref)
+
+	// The copy is independent of the document
+	node.Value.Description = "a node"
+	cloned.Paths.Set("/forests", &PathItem{})
+	post.Callbacks["onGrow"].Value.Set("{$request.body#/other}", &PathItem{})
+	cloned.Tags[0].Name = "forests"
+	require.Empty(t, doc.Components.Schemas["Node"].Value.Description)
+	require.Nil(t, doc.Paths.Value("/forests"))
+	require.Equal(t, 1, doc.Paths.Value("/trees").Post.Callbacks["onGrow"].Value.Len())
+	require.Equal(t, "trees", doc.Tags[0].Name)
+	require.Equal(t, "a node", post.RequestBody.Value.Content.Get("application/json").Schema.Value.Description)
+}
+
+func TestCloneComponents(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(walkSpec))
+	require.NoError(t, err)
+
+	schema := doc.Components.Schemas["Node"].Value.Clone()
+	require.Same(t, schema, schema.Properties["children"].Value.Items.Value)
+	schema.Properties["children"].Value.Items.Value.Type = &Types{TypeString}
+	require.Equal(t, &Types{TypeObject}, doc.Components.Schemas["Node"].Value.Type)
+
+	ref := doc.Paths.Value("/trees").Post.RequestBody.Value.Content.Get("application/json").Schema.Clone()
+	require.Equal(t, "#/components/schemas/Node", ref.Ref)
+	require.NotSame(t, doc.Components.Schemas["Node"].Value, ref.Value)
+
+	response := doc.Components.Responses["Created"].Value.Clone()
+	require.Equal(t, doc.Components.Responses["Created"].Value, response)
+	require.NotSame(t, doc.Components.Responses["Created"].Value.Headers["Location"], response.Headers["Location"])
+
+	operation := doc.Paths.Value("/trees").Post.Clone()
+	require.Equal(t, 1, operation.Callbacks["onGrow"].Value.Len())
+
+	var nilDoc *T
+	require.Nil(t, nilDoc.Clone())
+}
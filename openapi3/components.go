@@ -15,6 +15,7 @@ type (
 	Headers         map[string]*HeaderRef
 	Links           map[string]*LinkRef
 	ParametersMap   map[string]*ParameterRef
+	PathItems       map[string]*PathItem
 	RequestBodies   map[string]*RequestBodyRef
 	ResponseBodies  map[string]*ResponseRef
 	Schemas         map[string]*SchemaRef
@@ -35,6 +36,7 @@ type Components struct {
 	Examples        Examples        `json:"examples,omitempty" yaml:"examples,omitempty"`
 	Links           Links           `json:"links,omitempty" yaml:"links,omitempty"`
 	Callbacks       Callbacks       `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
+	PathItems       PathItems       `json:"pathItems,omitempty" yaml:"pathItems,omitempty"` // OpenAPI 3.1
 }
 
 func NewComponents() Components {
@@ -52,7 +54,7 @@ func (components Components) MarshalJSON() ([]byte, error) {
 
 // MarshalYAML returns the YAML encoding of Components.
 func (components Components) MarshalYAML() (any, error) {
-	m := make(map[string]any, 9+len(components.Extensions))
+	m := make(map[string]any, 10+len(components.Extensions))
 	for k, v := range components.Extensions {
 		m[k] = v
 	}
@@ -83,6 +85,9 @@ func (components Components) MarshalYAML() (any, error) {
 	if x := components.Callbacks; len(x) != 0 {
 		m["callbacks"] = x
 	}
+	if x := components.PathItems; len(x) != 0 {
+		m["pathItems"] = x
+	}
 	return m, nil
 }
 
@@ -103,6 +108,7 @@ func (components *Components) UnmarshalJSON(data []byte) error {
 	delete(x.Extensions, "examples")
 	delete(x.Extensions, "links")
 	delete(x.Extensions, "callbacks")
+	delete(x.Extensions, "pathItems")
 	if len(x.Extensions) == 0 {
 		x.Extensions = nil
 	}
@@ -249,9 +255,34 @@ func (components *Components) Validate(ctx context.Context, opts ...ValidationOp
 		}
 	}
 
+	for _, k := range componentNames(components.PathItems) {
+		if err = ValidateIdentifier(k); err != nil {
+			return fmt.Errorf("path item %q: %w", k, err)
+		}
+	}
+	if err = components.PathItems.Validate(ctx); err != nil {
+		return err
+	}
+
 	return validateExtensions(ctx, components.Extensions)
 }
 
+// Validate returns an error if PathItems does not comply with the OpenAPI spec.
+func (pathItems PathItems) Validate(ctx context.Context, opts ...ValidationOption) error {
+	ctx = WithValidationOptions(ctx, opts...)
+
+	for _, k := range componentNames(pathItems) {
+		v := pathItems[k]
+		if v == nil {
+			return fmt.Errorf("path item %q: %w", k, errMUSTPathItem)
+		}
+		if err := v.Validate(ctx); err != nil {
+			return fmt.Errorf("path item %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
 var _ jsonpointer.JSONPointable = (*Schemas)(nil)
 
 // JSONLookup implements https://pkg.go.dev/github.com/go-openapi/jsonpointer#JSONPointable
@@ -368,3 +399,16 @@ func (m Callbacks) JSONLookup(token string) (any, error) {
 		return v.Value, nil
 	}
 }
+
+var _ jsonpointer.JSONPointable = (*PathItems)(nil)
+
+// JSONLookup implements https://pkg.go.dev/github.com/go-openapi/jsonpointer#JSONPointable
+func (m PathItems) JSONLookup(token string) (any, error) {
+	if v, ok := m[token]; !ok || v == nil {
+		return nil, fmt.Errorf("no path item %q", token)
+	} else if ref := v.Ref; ref != "" {
+		return &Ref{Ref: ref}, nil
+	} else {
+		return v, nil
+	}
+}
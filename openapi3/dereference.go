@@ -0,0 +1,408 @@
+package openapi3
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mohae/deepcopy"
+)
+
+// DefaultDereferenceAnnotation is the extension in which Dereference records
+// the reference an inlined value was reached through.
+const DefaultDereferenceAnnotation = "x-original-ref"
+
+// CycleStrategy is how Dereference handles references to a value from within itself.
+type CycleStrategy int
+
+const (
+	// CyclesFail fails with a *CycleError.
+	CyclesFail CycleStrategy = iota
+	// CyclesStop inlines cyclic values up to the maximum depth, then replaces
+	// them with empty values annotated with their reference, such as the empty schema.
+	// Values are then copied wherever they are inlined, which can take
+	// exponential time and space for documents with many intertwined cycles.
+	CyclesStop
+	// CyclesKeepRef keeps the cyclic references, pointing to their inlined value.
+	CyclesKeepRef
+)
+
+// CycleError is returned by Dereference for cyclic references with CyclesFail.
+type CycleError struct {
+	Ref string
+}
+
+var _ error = (*CycleError)(nil)
+
+func (err *CycleError) Error() string {
+	return fmt.Sprintf("cannot dereference cyclic reference %q", err.Ref)
+}
+
+// DereferenceOption allows tweaking how references are inlined.
+type DereferenceOption func(*dereferenceOptions)
+
+type dereferenceOptions struct {
+	cycles     CycleStrategy
+	maxDepth   int
+	annotation string
+}
+
+// DereferenceCycles sets how cyclic references are handled, failing by default.
+// With CyclesStop, cyclic values are inlined maxDepth times within themselves.
+func DereferenceCycles(strategy CycleStrategy, maxDepth int) DereferenceOption {
+	return func(options *dereferenceOptions) {
+		options.cycles = strategy
+		options.maxDepth = maxDepth
+	}
+}
+
+// DereferenceAnnotation sets the extension recording the reference of inlined values,
+// DefaultDereferenceAnnotation by default. References are not recorded when empty.
+func DereferenceAnnotation(extension string) DereferenceOption {
+	return func(options *dereferenceOptions) {
+		options.annotation = extension
+	}
+}
+
+// Dereference returns a deep copy of the document with the values of its
+// references inlined. The document must have its references resolved,
+// see Loader.ResolveRefsIn. It is left untouched.
+//
+// Values are copied once per reference, the copy being shared by the places
+// referring to them, and annotated with the reference:
+//
+//	schema:
+//	  $ref: '#/components/schemas/Pet'
+//
+// becomes
+//
+//	schema:
+//	  type: object
+//	  x-original-ref: '#/components/schemas/Pet'
+//	  ...
+func (doc *T) Dereference(opts ...DereferenceOption) (*T, error) {
+	d := newDereferencer(opts)
+	v, err := d.copy(reflect.ValueOf(doc))
+	if err != nil {
+		return nil, err
+	}
+	if err := d.relinkSchemas(); err != nil {
+		return nil, err
+	}
+	return v.Interface().(*T), nil
+}
+
+// Dereference returns a deep copy of the schema with the values of its
+// references inlined, see T.Dereference.
+func (x *SchemaRef) Dereference(opts ...DereferenceOption) (*SchemaRef, error) {
+	d := newDereferencer(opts)
+	v, err := d.copy(reflect.ValueOf(x))
+	if err != nil {
+		return nil, err
+	}
+	if err := d.relinkSchemas(); err != nil {
+		return nil, err
+	}
+	return v.Interface().(*SchemaRef), nil
+}
+
+type dereferencer struct {
+	dereferenceOptions
+	// copies maps the values being copied to their copies, innermost last.
+	copies map[uintptr][]valueCopy
+	// depth is the number of references being inlined.
+	depth int
+	// inlined holds the values inlined by reference, unless cycles are cut.
+	inlined map[inlinedKey]reflect.Value
+	// schemas lists the copied schemas, whose unexported fields still refer to the original.
+	schemas []schemaCopy
+	// schemaCopies maps the copied schemas to their first copy.
+	schemaCopies map[*Schema]*Schema
+}
+
+type schemaCopy struct {
+	original, copy *Schema
+}
+
+type inlinedKey struct {
+	value uintptr
+	ref   string
+}
+
+type valueCopy struct {
+	value reflect.Value
+	depth int
+}
+
+func newDereferencer(opts []DereferenceOption) *dereferencer {
+	d := &dereferencer{
+		dereferenceOptions: dereferenceOptions{annotation: DefaultDereferenceAnnotation},
+		copies:             make(map[uintptr][]valueCopy),
+		inlined:            make(map[inlinedKey]reflect.Value),
+		schemaCopies:       make(map[*Schema]*Schema),
+	}
+	for _, opt := range opts {
+		opt(&d.dereferenceOptions)
+	}
+	return d
+}
+
+var (
+	pathsType     = reflect.TypeOf(&Paths{})
+	responsesType = reflect.TypeOf(&Responses{})
+	callbackType  = reflect.TypeOf(&Callback{})
+)
+
+func (d *dereferencer) copy(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			if isRefType(v.Elem().Type()) {
+				return d.copyRef(v)
+			}
+			return d.copyPtr(v, "")
+		}
+		c := reflect.New(v.Type().Elem())
+		value, err := d.copy(v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		c.Elem().Set(value)
+		return c, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := d.copy(iter.Value())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			m.SetMapIndex(iter.Key(), value)
+		}
+		return m, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			value, err := d.copy(v.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			s.Index(i).Set(value)
+		}
+		return s, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(reflect.ValueOf(deepcopy.Copy(v.Interface())))
+		return c, nil
+	}
+	return v, nil
+}
+
+// isRefType tells whether t is one of the reference types, such as SchemaRef.
+func isRefType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	ref, ok := t.FieldByName("Ref")
+	if !ok || ref.Type.Kind() != reflect.String {
+		return false
+	}
+	value, ok := t.FieldByName("Value")
+	return ok && value.Type.Kind() == reflect.Ptr
+}
+
+// copyRef returns a reference holding a copy of the value of the reference v.
+func (d *dereferencer) copyRef(v reflect.Value) (reflect.Value, error) {
+	ref := v.Elem().FieldByName("Ref").String()
+	if ref == "" {
+		return d.copyPtr(v, "")
+	}
+	value := v.Elem().FieldByName("Value")
+	if value.IsNil() {
+		return reflect.Value{}, foundUnresolvedRef(ref)
+	}
+
+	c := reflect.New(v.Type().Elem())
+	if copies := d.copies[value.Pointer()]; len(copies) != 0 {
+		switch d.cycles {
+		case CyclesFail:
+			return reflect.Value{}, &CycleError{Ref: ref}
+		case CyclesKeepRef:
+			c.Elem().FieldByName("Ref").SetString(ref)
+			c.Elem().FieldByName("Value").Set(copies[len(copies)-1].value)
+			return c, nil
+		case CyclesStop:
+			if len(copies) > d.maxDepth {
+				empty := reflect.New(value.Type().Elem())
+				d.annotate(empty, ref)
+				c.Elem().FieldByName("Value").Set(empty)
+				return c, nil
+			}
+		}
+	}
+
+	// Copies do not depend on where they are inlined, unless cycles are cut
+	key := inlinedKey{value: value.Pointer(), ref: ref}
+	if copied, ok := d.inlined[key]; ok {
+		c.Elem().FieldByName("Value").Set(copied)
+		return c, nil
+	}
+	d.depth++
+	copied, err := d.copyPtr(value, ref)
+	d.depth--
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if d.cycles != CyclesStop {
+		d.inlined[key] = copied
+	}
+	c.Elem().FieldByName("Value").Set(copied)
+	return c, nil
+}
+
+// copyPtr returns a copy of v, a pointer to a struct, annotated with ref unless empty.
+func (d *dereferencer) copyPtr(v reflect.Value, ref string) (reflect.Value, error) {
+	key := v.Pointer()
+	if copies := d.copies[key]; len(copies) != 0 && ref == "" {
+		// Values holding themselves without references, unless inlined again
+		if last := copies[len(copies)-1]; last.depth == d.depth {
+			return last.value, nil
+		}
+	}
+
+	c := reflect.New(v.Type().Elem())
+	c.Elem().Set(v.Elem())
+	d.copies[key] = append(d.copies[key], valueCopy{value: c, depth: d.depth})
+	if schema, ok := v.Interface().(*Schema); ok {
+		d.schemas = append(d.schemas, schemaCopy{original: schema, copy: c.Interface().(*Schema)})
+		if _, ok := d.schemaCopies[schema]; !ok {
+			d.schemaCopies[schema] = c.Interface().(*Schema)
+		}
+	}
+	defer func() {
+		d.copies[key] = d.copies[key][:len(d.copies[key])-1]
+	}()
+
+	switch v.Type() {
+	case pathsType, responsesType, callbackType:
+		// Their items are not exported
+		items, err := d.copy(v.MethodByName("Map").Call(nil)[0])
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		fresh := reflect.New(v.Type().Elem())
+		fresh.Elem().FieldByName("Extensions").Set(c.Elem().FieldByName("Extensions"))
+		c.Elem().Set(fresh.Elem())
+		iter := items.MapRange()
+		for iter.Next() {
+			c.MethodByName("Set").Call([]reflect.Value{iter.Key(), iter.Value()})
+		}
+	}
+
+	if err := d.copyFields(c.Elem()); err != nil {
+		return reflect.Value{}, err
+	}
+	if ref != "" {
+		d.annotate(c, ref)
+	}
+	if pathItem, ok := c.Interface().(*PathItem); ok {
+		// Path items hold the value of their reference
+		if pathItem.Ref != "" {
+			d.annotate(c, pathItem.Ref)
+			pathItem.Ref = ""
+		}
+	}
+	return c, nil
+}
+
+// copyFields replaces the exported fields of the struct v with copies.
+func (d *dereferencer) copyFields(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			// Embedded values, such as the Parameter of a Header
+			if err := d.copyFields(field); err != nil {
+				return err
+			}
+			continue
+		}
+		copied, err := d.copy(field)
+		if err != nil {
+			return err
+		}
+		field.Set(copied)
+	}
+	return nil
+}
+
+// relinkSchemas points the statically resolved $dynamicRef and the $dynamicAnchor
+// of the copied schemas to copies, copying the schemas they refer to that are not.
+func (d *dereferencer) relinkSchemas() error {
+	// Copying schemas appends to d.schemas
+	for i := 0; i < len(d.schemas); i++ {
+		original, c := d.schemas[i].original, d.schemas[i].copy
+		if ref := original.dynamicRefTarget; ref != nil {
+			target, err := d.schemaCopy(ref.Value)
+			if err != nil {
+				return err
+			}
+			c.dynamicRefTarget = &SchemaRef{Ref: ref.Ref, Value: target}
+		}
+		if anchors := original.dynamicAnchors; anchors != nil {
+			c.dynamicAnchors = make(map[string]*Schema, len(anchors))
+			for anchor, schema := range anchors {
+				copied, err := d.schemaCopy(schema)
+				if err != nil {
+					return err
+				}
+				c.dynamicAnchors[anchor] = copied
+			}
+		}
+	}
+	return nil
+}
+
+// schemaCopy returns the first copy of schema, copying it if it has none.
+func (d *dereferencer) schemaCopy(schema *Schema) (*Schema, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if c, ok := d.schemaCopies[schema]; ok {
+		return c, nil
+	}
+	c, err := d.copyPtr(reflect.ValueOf(schema), "")
+	if err != nil {
+		return nil, err
+	}
+	return c.Interface().(*Schema), nil
+}
+
+// annotate records ref in the extensions of v, a pointer to a struct.
+func (d *dereferencer) annotate(v reflect.Value, ref string) {
+	if d.annotation == "" {
+		return
+	}
+	extensions := v.Elem().FieldByName("Extensions")
+	if !extensions.IsValid() {
+		return
+	}
+	if extensions.IsNil() {
+		extensions.Set(reflect.ValueOf(make(map[string]any, 1)))
+	}
+	extensions.SetMapIndex(reflect.ValueOf(d.annotation), reflect.ValueOf(ref))
+}
@@ -0,0 +1,195 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const dereferenceSpec = `
+openapi: 3.0.3
+info: {title: Trees, version: 1.0.0}
+paths:
+  /trees/{id}:
+    parameters: [{$ref: '#/components/parameters/Id'}]
+    get:
+      responses:
+        '200': {$ref: '#/components/responses/Tree'}
+components:
+  parameters:
+    Id: {name: id, in: path, required: true, schema: {type: string}}
+  responses:
+    Tree:
+      description: a tree
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/Tree'}
+  schemas:
+    Tree:
+      type: object
+      properties:
+        name: {$ref: '#/components/schemas/Name'}
+        root: {$ref: '#/components/schemas/Node'}
+    Name: {type: string, maxLength: 10}
+    Node:
+      type: object
+      properties:
+        children:
+          type: array
+          items: {$ref: '#/components/schemas/Node'}
+`
+
+func TestDereference(t *testing.T) {
+	loader := NewLoader()
+	doc, err := loader.LoadFromData([]byte(dereferenceSpec))
+	require.NoError(t, err)
+
+	_, err = doc.Dereference()
+	require.EqualError(t, err, `cannot dereference cyclic reference "#/components/schemas/Node"`)
+
+	dereferenced, err := doc.Dereference(DereferenceCycles(CyclesKeepRef, 0))
+	require.NoError(t, err)
+	require.NoError(t, dereferenced.Validate(context.Background()))
+
+	pathItem := dereferenced.Paths.Value("/trees/{id}")
+	require.Empty(t, pathItem.Parameters[0].Ref)
+	require.Equal(t, "#/components/parameters/Id", pathItem.Parameters[0].Value.Extensions["x-original-ref"])
+	response := pathItem.Get.Responses.Status(200)
+	require.Empty(t, response.Ref)
+	schema := response.Value.Content.Get("application/json").Schema
+	require.Empty(t, schema.Ref)
+	require.Equal(t, "#/components/schemas/Tree", schema.Value.Extensions["x-original-ref"])
+	require.Equal(t, uint64(10), *schema.Value.Properties["name"].Value.MaxLength)
+
+	node := schema.Value.Properties["root"]
+	require.Empty(t, node.Ref)
+	items := node.Value.Properties["children"].Value.Items
+	require.Equal(t, "#/components/schemas/Node", items.Ref)
+	require.Same(t, node.Value, items.Value)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "type": "object",
+  "x-original-ref": "#/components/schemas/Tree",
+  "properties": {
+    "name": {"type": "string", "maxLength": 10, "x-original-ref": "#/components/schemas/Name"},
+    "root": {
+      "type": "object",
+      "x-original-ref": "#/components/schemas/Node",
+      "properties": {
+        "children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}
+      }
+    }
+  }
+}`, string(data))
+
+	// Copies are independent of the document
+	*schema.Value.Properties["name"].Value.MaxLength = 20
+	require.Equal(t, uint64(10), *doc.Components.Schemas["Name"].Value.MaxLength)
+	require.Empty(t, doc.Components.Schemas["Name"].Value.Extensions)
+	require.Equal(t, "#/components/schemas/Tree", doc.Components.Responses["Tree"].Value.Content.Get("application/json").Schema.Ref)
+}
+
+func TestDereferenceCyclesStop(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(dereferenceSpec))
+	require.NoError(t, err)
+
+	node, err := doc.Components.Schemas["Tree"].Value.Properties["root"].Dereference(
+		DereferenceCycles(CyclesStop, 1),
+		DereferenceAnnotation("x-ref"),
+	)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(node)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "type": "object",
+  "x-ref": "#/components/schemas/Node",
+  "properties": {
+    "children": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "x-ref": "#/components/schemas/Node",
+        "properties": {
+          "children": {"type": "array", "items": {"x-ref": "#/components/schemas/Node"}}
+        }
+      }
+    }
+  }
+}`, string(data))
+
+	node, err = doc.Components.Schemas["Tree"].Value.Properties["root"].Dereference(
+		DereferenceCycles(CyclesStop, 0),
+		DereferenceAnnotation(""),
+	)
+	require.NoError(t, err)
+	require.NoError(t, node.Validate(context.Background()))
+	data, err = json.Marshal(node)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type": "object", "properties": {"children": {"type": "array", "items": {}}}}`, string(data))
+}
+
+func TestDereferenceDynamicRef(t *testing.T) {
+	loader := NewLoader()
+	doc, err := loader.LoadFromData([]byte(`
+openapi: 3.1.0
+info: {title: Trees, version: 1.0.0}
+components:
+  schemas:
+    Tree:
+      $id: https://example.com/schemas/tree
+      $dynamicAnchor: node
+      type: object
+      properties:
+        value: {}
+        children:
+          type: array
+          items: {$dynamicRef: '#node'}
+    StrictTree:
+      $id: https://example.com/schemas/strict-tree
+      $dynamicAnchor: node
+      allOf:
+      - $ref: tree
+      unevaluatedProperties: false
+`))
+	require.NoError(t, err)
+
+	dereferenced, err := doc.Dereference()
+	require.NoError(t, err)
+	require.NoError(t, dereferenced.Validate(loader.Context))
+
+	tree := dereferenced.Components.Schemas["Tree"].Value
+	strictTree := dereferenced.Components.Schemas["StrictTree"].Value
+	require.Same(t, strictTree, strictTree.dynamicAnchors["node"])
+	// Tree is copied once as a component and once where StrictTree refers to it
+	trees := []*Schema{tree, strictTree.AllOf[0].Value}
+	for _, copied := range trees {
+		anchor := copied.dynamicAnchors["node"]
+		require.True(t, anchor == trees[0] || anchor == trees[1])
+		target := copied.Properties["children"].Value.Items.Value.dynamicRefTarget.Value
+		require.True(t, target == trees[0] || target == trees[1])
+	}
+
+	// The original document is left untouched
+	doc.Components.Schemas["Tree"].Value.Properties["value"].Value.Type = &Types{TypeString}
+
+	value := map[string]any{
+		"value":    1,
+		"children": []any{map[string]any{"value": 2, "color": "red"}},
+	}
+	require.NoError(t, tree.VisitJSON(value))
+	err = strictTree.VisitJSON(value)
+	require.ErrorContains(t, err, `property "color" is unevaluated`)
+
+	// Schemas that $dynamicRef points to out of the dereferenced one are copied too
+	children, err := doc.Components.Schemas["Tree"].Value.Properties["children"].Dereference()
+	require.NoError(t, err)
+	target := children.Value.Items.Value.dynamicRefTarget.Value
+	require.NotNil(t, target)
+	require.NotSame(t, doc.Components.Schemas["Tree"].Value, target)
+	require.Equal(t, "https://example.com/schemas/tree", target.ID)
+}
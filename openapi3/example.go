@@ -15,6 +15,8 @@ type Example struct {
 	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
 	Value         any    `json:"value,omitempty" yaml:"value,omitempty"`
 	ExternalValue string `json:"externalValue,omitempty" yaml:"externalValue,omitempty"`
+
+	position *Position
 }
 
 func NewExample(value any) *Example {
@@ -71,8 +73,9 @@ func (example *Example) UnmarshalJSON(data []byte) error {
 }
 
 // Validate returns an error if Example does not comply with the OpenAPI spec.
-func (example *Example) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (example *Example) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, example.position) }()
 
 	if example.Value != nil && example.ExternalValue != "" {
 		return errors.New("value and externalValue are mutually exclusive")
@@ -50,8 +50,9 @@ func (header *Header) SerializationMethod() (*SerializationMethod, error) {
 }
 
 // Validate returns an error if Header does not comply with the OpenAPI spec.
-func (header *Header) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (header *Header) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, header.position) }()
 
 	if header.Name != "" {
 		return errors.New("header 'name' MUST NOT be specified, it is given in the corresponding headers map")
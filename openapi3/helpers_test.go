@@ -1,6 +1,7 @@
 package openapi3
 
 import (
+	"context"
 	"net/url"
 	"path/filepath"
 	"testing"
@@ -66,13 +67,13 @@ func TestReferencesComponentInRootDocument(t *testing.T) {
 
 	// Loading from a URL by mocking HTTP calls.
 	// Loads the data using the URI path from the testdata/ folder.
-	loader.ReadFromURIFunc = func(loader *Loader, url *url.URL) ([]byte, error) {
+	loader.ReadFromURIFunc = func(ctx context.Context, loader *Loader, url *url.URL) ([]byte, error) {
 		localURL := *url
 		localURL.Scheme = ""
 		localURL.Host = ""
 		localURL.Path = filepath.Join("testdata", localURL.Path)
 
-		return ReadFromFile(loader, &localURL)
+		return ReadFromFile(ctx, loader, &localURL)
 	}
 
 	u, _ := url.Parse("https://example.com/refsToRoot/openapi.yml")
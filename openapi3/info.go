@@ -11,7 +11,8 @@ import (
 type Info struct {
 	Extensions map[string]any `json:"-" yaml:"-"`
 
-	Title          string   `json:"title" yaml:"title"` // Required
+	Title          string   `json:"title" yaml:"title"`                         // Required
+	Summary        string   `json:"summary,omitempty" yaml:"summary,omitempty"` // OpenAPI 3.1
 	Description    string   `json:"description,omitempty" yaml:"description,omitempty"`
 	TermsOfService string   `json:"termsOfService,omitempty" yaml:"termsOfService,omitempty"`
 	Contact        *Contact `json:"contact,omitempty" yaml:"contact,omitempty"`
@@ -33,11 +34,14 @@ func (info *Info) MarshalYAML() (any, error) {
 	if info == nil {
 		return nil, nil
 	}
-	m := make(map[string]any, 6+len(info.Extensions))
+	m := make(map[string]any, 7+len(info.Extensions))
 	for k, v := range info.Extensions {
 		m[k] = v
 	}
 	m["title"] = info.Title
+	if x := info.Summary; x != "" {
+		m["summary"] = x
+	}
 	if x := info.Description; x != "" {
 		m["description"] = x
 	}
@@ -63,6 +67,7 @@ func (info *Info) UnmarshalJSON(data []byte) error {
 	}
 	_ = json.Unmarshal(data, &x.Extensions)
 	delete(x.Extensions, "title")
+	delete(x.Extensions, "summary")
 	delete(x.Extensions, "description")
 	delete(x.Extensions, "termsOfService")
 	delete(x.Extensions, "contact")
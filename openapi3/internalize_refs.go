@@ -343,7 +343,7 @@ func (doc *T) derefSchema(s *Schema, refNameResolver RefNameResolver, parentIsEx
 		return
 	}
 
-	for _, list := range []SchemaRefs{s.AllOf, s.AnyOf, s.OneOf} {
+	for _, list := range []SchemaRefs{s.AllOf, s.AnyOf, s.OneOf, s.PrefixItems} {
 		for _, s2 := range list {
 			isExternal := doc.addSchemaToSpec(s2, refNameResolver, parentIsExternal)
 			if s2 != nil {
@@ -352,14 +352,16 @@ func (doc *T) derefSchema(s *Schema, refNameResolver RefNameResolver, parentIsEx
 		}
 	}
 
-	for _, name := range componentNames(s.Properties) {
-		s2 := s.Properties[name]
-		isExternal := doc.addSchemaToSpec(s2, refNameResolver, parentIsExternal)
-		if s2 != nil {
-			doc.derefSchema(s2.Value, refNameResolver, isExternal || parentIsExternal)
+	for _, schemas := range []Schemas{s.Defs, s.Properties, s.PatternProperties, s.DependentSchemas} {
+		for _, name := range componentNames(schemas) {
+			s2 := schemas[name]
+			isExternal := doc.addSchemaToSpec(s2, refNameResolver, parentIsExternal)
+			if s2 != nil {
+				doc.derefSchema(s2.Value, refNameResolver, isExternal || parentIsExternal)
+			}
 		}
 	}
-	for _, ref := range []*SchemaRef{s.Not, s.AdditionalProperties.Schema, s.Items} {
+	for _, ref := range []*SchemaRef{s.Not, s.AdditionalProperties.Schema, s.Items, s.Contains, s.If, s.Then, s.Else, s.UnevaluatedItems, s.UnevaluatedProperties, s.PropertyNames, s.ContentSchema} {
 		isExternal := doc.addSchemaToSpec(ref, refNameResolver, parentIsExternal)
 		if ref != nil {
 			doc.derefSchema(ref.Value, refNameResolver, isExternal || parentIsExternal)
@@ -540,7 +542,10 @@ func (doc *T) InternalizeRefs(ctx context.Context, refNameResolver func(*T, Comp
 				doc.derefPaths(cbValue, refNameResolver, isExternal)
 			}
 		}
+
+		doc.derefPaths(components.PathItems, refNameResolver, false)
 	}
 
 	doc.derefPaths(doc.Paths.Map(), refNameResolver, false)
+	doc.derefPaths(doc.Webhooks, refNameResolver, false)
 }
@@ -117,7 +117,9 @@ paths:
 	require.NoError(t, err)
 
 	err = doc.Validate(sl.Context)
-	require.ErrorContains(t, err, `found unresolved ref: "#"`)
+	// "$ref": "#" now resolves to the root of the draft-04 meta-schema,
+	// whose "enum" property is an array without "items" as OpenAPI 3.0 requires.
+	require.ErrorContains(t, err, `when schema type is 'array', schema 'items' must be non-null`)
 }
 
 func TestIssue495WithDraft04Bis(t *testing.T) {
@@ -153,5 +155,7 @@ paths:
 	require.NoError(t, err)
 
 	err = doc.Validate(sl.Context)
-	require.ErrorContains(t, err, `found unresolved ref: "#"`)
+	// "$ref": "#" now resolves to the root of the draft-04 meta-schema,
+	// whose "enum" property is an array without "items" as OpenAPI 3.0 requires.
+	require.ErrorContains(t, err, `when schema type is 'array', schema 'items' must be non-null`)
 }
@@ -11,8 +11,9 @@ import (
 type License struct {
 	Extensions map[string]any `json:"-" yaml:"-"`
 
-	Name string `json:"name" yaml:"name"` // Required
-	URL  string `json:"url,omitempty" yaml:"url,omitempty"`
+	Name       string `json:"name" yaml:"name"` // Required
+	URL        string `json:"url,omitempty" yaml:"url,omitempty"`
+	Identifier string `json:"identifier,omitempty" yaml:"identifier,omitempty"` // OpenAPI 3.1
 }
 
 // MarshalJSON returns the JSON encoding of License.
@@ -26,7 +27,7 @@ func (license License) MarshalJSON() ([]byte, error) {
 
 // MarshalYAML returns the YAML encoding of License.
 func (license License) MarshalYAML() (any, error) {
-	m := make(map[string]any, 2+len(license.Extensions))
+	m := make(map[string]any, 3+len(license.Extensions))
 	for k, v := range license.Extensions {
 		m[k] = v
 	}
@@ -34,6 +35,9 @@ func (license License) MarshalYAML() (any, error) {
 	if x := license.URL; x != "" {
 		m["url"] = x
 	}
+	if x := license.Identifier; x != "" {
+		m["identifier"] = x
+	}
 	return m, nil
 }
 
@@ -47,6 +51,7 @@ func (license *License) UnmarshalJSON(data []byte) error {
 	_ = json.Unmarshal(data, &x.Extensions)
 	delete(x.Extensions, "name")
 	delete(x.Extensions, "url")
+	delete(x.Extensions, "identifier")
 	if len(x.Extensions) == 0 {
 		x.Extensions = nil
 	}
@@ -62,5 +67,9 @@ func (license *License) Validate(ctx context.Context, opts ...ValidationOption)
 		return errors.New("value of license name must be a non-empty string")
 	}
 
+	if license.URL != "" && license.Identifier != "" {
+		return errors.New("license url and identifier are mutually exclusive")
+	}
+
 	return validateExtensions(ctx, license.Extensions)
 }
@@ -18,6 +18,8 @@ type Link struct {
 	Parameters   map[string]any `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 	Server       *Server        `json:"server,omitempty" yaml:"server,omitempty"`
 	RequestBody  any            `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+
+	position *Position
 }
 
 // MarshalJSON returns the JSON encoding of Link.
@@ -80,8 +82,9 @@ func (link *Link) UnmarshalJSON(data []byte) error {
 }
 
 // Validate returns an error if Link does not comply with the OpenAPI spec.
-func (link *Link) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (link *Link) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, link.position) }()
 
 	if link.OperationID == "" && link.OperationRef == "" {
 		return errors.New("missing operationId or operationRef on link")
@@ -4,6 +4,7 @@
 package openapi3_test
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"net/url"
@@ -20,7 +21,7 @@ var circularResSpecs embed.FS
 func TestLoadCircularRefFromFile(t *testing.T) {
 	loader := openapi3.NewLoader()
 	loader.IsExternalRefsAllowed = true
-	loader.ReadFromURIFunc = func(loader *openapi3.Loader, uri *url.URL) ([]byte, error) {
+	loader.ReadFromURIFunc = func(ctx context.Context, loader *openapi3.Loader, uri *url.URL) ([]byte, error) {
 		return circularResSpecs.ReadFile(uri.Path)
 	}
 
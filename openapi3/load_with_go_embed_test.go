@@ -4,6 +4,7 @@
 package openapi3_test
 
 import (
+	"context"
 	"embed"
 	"fmt"
 	"net/url"
@@ -17,7 +18,7 @@ var fs embed.FS
 func Example() {
 	loader := openapi3.NewLoader()
 	loader.IsExternalRefsAllowed = true
-	loader.ReadFromURIFunc = func(loader *openapi3.Loader, uri *url.URL) ([]byte, error) {
+	loader.ReadFromURIFunc = func(ctx context.Context, loader *openapi3.Loader, uri *url.URL) ([]byte, error) {
 		return fs.ReadFile(uri.Path)
 	}
 
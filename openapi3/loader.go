@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"path"
@@ -31,6 +32,38 @@ type Loader struct {
 	// ReadFromURIFunc allows overriding the any file/URL reading func
 	ReadFromURIFunc ReadFromURIFunc
 
+	// FS is where local paths are read from, rather than from the operating system.
+	// Absolute paths are relative to its root.
+	FS fs.FS
+
+	// URIRewrites reads documents from local directories or file systems
+	// instead of from their URI.
+	URIRewrites []URIRewrite
+
+	// RefPolicy restricts the documents read, including the root document
+	// when loaded from a location.
+	RefPolicy *RefPolicy
+
+	// Limits bounds the resources loading takes.
+	Limits *LoaderLimits
+
+	// PrefetchConcurrency is the number of external documents read and parsed
+	// concurrently, ahead of the resolution of the references to them.
+	// Values below 2 disable prefetching.
+	PrefetchConcurrency int
+
+	// IncludePositions records where components, operations, parameters and schemas
+	// are defined in the loaded documents. See Schema.Position for instance.
+	IncludePositions bool
+
+	// StrictDecoding fails loading documents with fields that are neither
+	// defined by the specification nor extensions, or with keys defined more than once
+	// in the same mapping. The error is a MultiError of *UnknownFieldError
+	// or of *DuplicateKeyError.
+	// Documents without an openapi field are taken to only hold components,
+	// whose fields get checked as they are referred to.
+	StrictDecoding bool
+
 	Context context.Context
 
 	rootDir      string
@@ -43,6 +76,15 @@ type Loader struct {
 	visitedRefs map[string]struct{}
 	visitedPath []string
 	backtrack   map[string][]func(value any)
+
+	schemaIDs *schemaIdentifiers
+
+	positions map[string]positions
+
+	readDocuments map[string]struct{}
+	refCount      int64
+
+	prefetcher *prefetcher
 }
 
 // NewLoader returns an empty Loader
@@ -57,11 +99,15 @@ func (loader *Loader) resetVisitedPathItemRefs() {
 	loader.visitedRefs = make(map[string]struct{})
 	loader.visitedPath = nil
 	loader.backtrack = make(map[string][]func(value any))
+	loader.schemaIDs = nil
+	loader.readDocuments = nil
+	loader.refCount = 0
 }
 
 // LoadFromURI loads a spec from a remote URL
 func (loader *Loader) LoadFromURI(location *url.URL) (*T, error) {
 	loader.resetVisitedPathItemRefs()
+	defer loader.stopPrefetch()
 	return loader.loadFromURIInternal(location)
 }
 
@@ -103,18 +149,64 @@ func (loader *Loader) loadSingleElementFromURI(ref string, rootPath *url.URL, el
 	if err != nil {
 		return nil, err
 	}
+	if err := loader.checkDuplicateKeys(resolvedPath, data); err != nil {
+		return nil, err
+	}
 	if err := unmarshal(data, element); err != nil {
 		return nil, err
 	}
+	loader.recordPositions(resolvedPath, data, element)
+	if err := loader.checkUnknownFields(resolvedPath, "", element); err != nil {
+		return nil, err
+	}
 
 	return resolvedPath, nil
 }
 
 func (loader *Loader) readURL(location *url.URL) ([]byte, error) {
-	if f := loader.ReadFromURIFunc; f != nil {
-		return f(loader, location)
+	ctx := loader.context()
+	if loader.RefPolicy != nil {
+		if err := loader.checkRefPolicy(ctx, location); err != nil {
+			return nil, err
+		}
+		if err := loader.countDocument(location); err != nil {
+			return nil, err
+		}
+	}
+	if document := loader.prefetched(location); document != nil {
+		return document.data, document.err
+	}
+	return loader.fetch(ctx, location)
+}
+
+// fetch reads the document at location within the policy and limits.
+// It is safe for concurrent use.
+func (loader *Loader) fetch(ctx context.Context, location *url.URL) ([]byte, error) {
+	policy := loader.RefPolicy
+	if policy != nil {
+		if err := loader.checkRefPolicy(ctx, location); err != nil {
+			return nil, err
+		}
+	}
+
+	read, err := loader.reader(location)
+	if err != nil {
+		return nil, err
+	}
+	data, err := read(ctx, loader, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy != nil {
+		if err := policy.checkSize(location, int64(len(data))); err != nil {
+			return nil, err
+		}
 	}
-	return DefaultReadFromURI(loader, location)
+	if err := loader.checkInputLimits(data, location); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
 // LoadFromStdin loads a spec from stdin
@@ -128,7 +220,7 @@ func (loader *Loader) LoadFromIoReader(reader io.Reader) (*T, error) {
 		return nil, fmt.Errorf("invalid reader: %v", reader)
 	}
 
-	data, err := io.ReadAll(reader)
+	data, err := loader.readAllLimited(reader, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -138,10 +230,22 @@ func (loader *Loader) LoadFromIoReader(reader io.Reader) (*T, error) {
 // LoadFromData loads a spec from a byte array
 func (loader *Loader) LoadFromData(data []byte) (*T, error) {
 	loader.resetVisitedPathItemRefs()
+	if err := loader.checkInputLimits(data, nil); err != nil {
+		return nil, err
+	}
+	if err := loader.checkDuplicateKeys(nil, data); err != nil {
+		return nil, err
+	}
 	doc := &T{}
 	if err := unmarshal(data, doc); err != nil {
 		return nil, err
 	}
+	loader.recordPositions(nil, data, doc)
+	if err := loader.checkUnknownFields(nil, "", doc); err != nil {
+		return nil, err
+	}
+	loader.startPrefetch(nil, data)
+	defer loader.stopPrefetch()
 	if err := loader.ResolveRefsIn(doc, nil); err != nil {
 		return nil, err
 	}
@@ -152,6 +256,10 @@ func (loader *Loader) LoadFromData(data []byte) (*T, error) {
 // elements and returns a *T with all resolved data or an error if unable to load data or resolve refs.
 func (loader *Loader) LoadFromDataWithPath(data []byte, location *url.URL) (*T, error) {
 	loader.resetVisitedPathItemRefs()
+	if err := loader.checkInputLimits(data, location); err != nil {
+		return nil, err
+	}
+	defer loader.stopPrefetch()
 	return loader.loadFromDataWithPathInternal(data, location)
 }
 
@@ -165,10 +273,20 @@ func (loader *Loader) loadFromDataWithPathInternal(data []byte, location *url.UR
 		return doc, nil
 	}
 
-	doc := &T{}
+	if err := loader.checkDuplicateKeys(location, data); err != nil {
+		return nil, err
+	}
+	loader.startPrefetch(location, data)
+	doc := loader.prefetchedDoc(location)
+	if doc == nil {
+		doc = &T{}
+		if err := unmarshal(data, doc); err != nil {
+			return nil, err
+		}
+	}
 	loader.visitedDocuments[uri] = doc
-
-	if err := unmarshal(data, doc); err != nil {
+	loader.recordPositions(location, data, doc)
+	if err := loader.checkUnknownFields(location, "", doc); err != nil {
 		return nil, err
 	}
 
@@ -191,6 +309,8 @@ func (loader *Loader) ResolveRefsIn(doc *T, location *url.URL) (err error) {
 		loader.resetVisitedPathItemRefs()
 	}
 
+	loader.indexSchemaIdentifiers(doc, location)
+
 	if components := doc.Components; components != nil {
 		for _, name := range componentNames(components.Headers) {
 			component := components.Headers[name]
@@ -240,6 +360,15 @@ func (loader *Loader) ResolveRefsIn(doc *T, location *url.URL) (err error) {
 				return
 			}
 		}
+		for _, name := range componentNames(components.PathItems) {
+			pathItem := components.PathItems[name]
+			if pathItem == nil {
+				continue
+			}
+			if err = loader.resolvePathItemRef(doc, pathItem, location); err != nil {
+				return
+			}
+		}
 	}
 
 	// Visit all operations
@@ -254,6 +383,17 @@ func (loader *Loader) ResolveRefsIn(doc *T, location *url.URL) (err error) {
 		}
 	}
 
+	// Visit all webhooks
+	for _, name := range componentNames(doc.Webhooks) {
+		pathItem := doc.Webhooks[name]
+		if pathItem == nil {
+			continue
+		}
+		if err = loader.resolvePathItemRef(doc, pathItem, location); err != nil {
+			return
+		}
+	}
+
 	return
 }
 
@@ -317,13 +457,17 @@ func isSingleRefElement(ref string) bool {
 	return !strings.Contains(ref, "#")
 }
 
-func (loader *Loader) visitRef(ref string) {
+func (loader *Loader) visitRef(ref string) error {
+	if err := loader.countRef(); err != nil {
+		return err
+	}
 	if loader.visitedRefs == nil {
 		loader.visitedRefs = make(map[string]struct{})
 		loader.backtrack = make(map[string][]func(value any))
 	}
 	loader.visitedPath = append(loader.visitedPath, ref)
 	loader.visitedRefs[ref] = struct{}{}
+	return nil
 }
 
 func (loader *Loader) unvisitRef(ref string, value any) {
@@ -470,6 +614,10 @@ func (loader *Loader) resolveComponent(doc *T, ref string, path *url.URL, resolv
 		if err := codec(cursor, resolved); err != nil {
 			return nil, nil, fmt.Errorf("bad data in %q (expecting %s)", ref, readableType(resolved))
 		}
+		loader.recordPositionsAt(componentPath, fragment, resolved)
+		if err := loader.checkUnknownFields(componentPath, fragment, resolved); err != nil {
+			return nil, nil, err
+		}
 		return componentDoc, componentPath, nil
 
 	default:
@@ -617,7 +765,9 @@ func (loader *Loader) resolveHeaderRef(doc *T, component *HeaderRef, documentPat
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var header Header
 			if documentPath, err = loader.loadSingleElementFromURI(ref, documentPath, &header); err != nil {
@@ -671,7 +821,9 @@ func (loader *Loader) resolveParameterRef(doc *T, component *ParameterRef, docum
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var param Parameter
 			if documentPath, err = loader.loadSingleElementFromURI(ref, documentPath, &param); err != nil {
@@ -736,7 +888,9 @@ func (loader *Loader) resolveRequestBodyRef(doc *T, component *RequestBodyRef, d
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var requestBody RequestBody
 			if documentPath, err = loader.loadSingleElementFromURI(ref, documentPath, &requestBody); err != nil {
@@ -803,7 +957,9 @@ func (loader *Loader) resolveResponseRef(doc *T, component *ResponseRef, documen
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var resp Response
 			if documentPath, err = loader.loadSingleElementFromURI(ref, documentPath, &resp); err != nil {
@@ -883,12 +1039,26 @@ func (loader *Loader) resolveSchemaRef(doc *T, component *SchemaRef, documentPat
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
-		if isSingleRefElement(ref) {
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
+		base, ok := loader.schemaIdentifiers().bases[component]
+		if !ok {
+			base = documentPath
+		}
+		identified, refPath, err := loader.resolveSchemaIdentifier(doc, ref, base, visited)
+		if err != nil {
+			return err
+		}
+		if identified != nil {
+			component.Value = identified
+			component.setRefPath(refPath)
+		} else if isSingleRefElement(ref) {
 			var schema Schema
 			if documentPath, err = loader.loadSingleElementFromURI(ref, documentPath, &schema); err != nil {
 				return err
 			}
+			loader.indexSchemaResource(doc, &schema, documentPath)
 			component.Value = &schema
 			component.setRefPath(documentPath)
 		} else {
@@ -914,11 +1084,33 @@ func (loader *Loader) resolveSchemaRef(doc *T, component *SchemaRef, documentPat
 	}
 
 	// ResolveRefs referred schemas
+	if err := loader.resolveDynamicRef(doc, value, documentPath, visited); err != nil {
+		return err
+	}
+	for _, name := range componentNames(value.Defs) {
+		v := value.Defs[name]
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
 	if v := value.Items; v != nil {
 		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
 			return err
 		}
 	}
+	for _, v := range value.PrefixItems {
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
+	for _, v := range []*SchemaRef{value.Contains, value.UnevaluatedItems, value.UnevaluatedProperties} {
+		if v == nil {
+			continue
+		}
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
 	for _, name := range componentNames(value.Properties) {
 		v := value.Properties[name]
 		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
@@ -935,6 +1127,36 @@ func (loader *Loader) resolveSchemaRef(doc *T, component *SchemaRef, documentPat
 			return err
 		}
 	}
+	for _, v := range []*SchemaRef{value.If, value.Then, value.Else} {
+		if v == nil {
+			continue
+		}
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
+	for _, name := range componentNames(value.DependentSchemas) {
+		v := value.DependentSchemas[name]
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range componentNames(value.PatternProperties) {
+		v := value.PatternProperties[pattern]
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
+	if v := value.PropertyNames; v != nil {
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
+	if v := value.ContentSchema; v != nil {
+		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
+			return err
+		}
+	}
 	for _, v := range value.AllOf {
 		if err := loader.resolveSchemaRef(doc, v, documentPath, visited); err != nil {
 			return err
@@ -969,7 +1191,9 @@ func (loader *Loader) resolveSecuritySchemeRef(doc *T, component *SecurityScheme
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var scheme SecurityScheme
 			if _, err = loader.loadSingleElementFromURI(ref, documentPath, &scheme); err != nil {
@@ -1009,7 +1233,9 @@ func (loader *Loader) resolveExampleRef(doc *T, component *ExampleRef, documentP
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var example Example
 			if _, err = loader.loadSingleElementFromURI(ref, documentPath, &example); err != nil {
@@ -1053,7 +1279,9 @@ func (loader *Loader) resolveCallbackRef(doc *T, component *CallbackRef, documen
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var resolved Callback
 			if documentPath, err = loader.loadSingleElementFromURI(ref, documentPath, &resolved); err != nil {
@@ -1109,7 +1337,9 @@ func (loader *Loader) resolveLinkRef(doc *T, component *LinkRef, documentPath *u
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var link Link
 			if _, err = loader.loadSingleElementFromURI(ref, documentPath, &link); err != nil {
@@ -1152,7 +1382,9 @@ func (loader *Loader) resolvePathItemRef(doc *T, pathItem *PathItem, documentPat
 		}) {
 			return nil
 		}
-		loader.visitRef(ref)
+		if err := loader.visitRef(ref); err != nil {
+			return err
+		}
 		if isSingleRefElement(ref) {
 			var p PathItem
 			if documentPath, err = loader.loadSingleElementFromURI(ref, documentPath, &p); err != nil {
@@ -1211,3 +1443,7 @@ func (loader *Loader) resolvePathItemRef(doc *T, pathItem *PathItem, documentPat
 func unescapeRefString(ref string) string {
 	return strings.Replace(strings.Replace(ref, "~1", "/", -1), "~0", "~", -1)
 }
+
+func escapeRefString(ref string) string {
+	return strings.Replace(strings.Replace(ref, "~", "~0", -1), "/", "~1", -1)
+}
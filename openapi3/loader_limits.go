@@ -0,0 +1,154 @@
+package openapi3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// LoaderLimits bounds the resources loading documents takes, which matters when
+// loading documents from untrusted sources.
+// The zero value of each field sets no limit.
+type LoaderLimits struct {
+	// MaxInputBytes limits the size of each document.
+	MaxInputBytes int64
+
+	// MaxDepth limits the nesting of objects and arrays in each document.
+	MaxDepth int64
+
+	// MaxAliases limits the number of YAML aliases decoding each document expands,
+	// counting aliases within aliases as many times as they are expanded.
+	MaxAliases int64
+
+	// MaxRefs limits the number of references a load resolves,
+	// counting a reference each time it gets resolved.
+	MaxRefs int64
+}
+
+// LimitError is returned when loading exceeds one of the Loader.Limits.
+type LimitError struct {
+	// Limit names the exceeded field of LoaderLimits.
+	Limit string
+	// Max is the value of that field.
+	Max int64
+	// Location is the location of the document exceeding the limit, if any.
+	Location string
+}
+
+var _ error = (*LimitError)(nil)
+
+func (err *LimitError) Error() string {
+	msg := fmt.Sprintf("%s limit of %d exceeded", err.Limit, err.Max)
+	if err.Location != "" {
+		msg = fmt.Sprintf("%q: %s", err.Location, msg)
+	}
+	return msg
+}
+
+// readAllLimited reads reader, the document at location if any, up to the
+// MaxInputBytes limit and, for documents at a location, the RefPolicy size limit.
+func (loader *Loader) readAllLimited(reader io.Reader, location *url.URL) ([]byte, error) {
+	if loader == nil {
+		return io.ReadAll(reader)
+	}
+	var max int64
+	if limits := loader.Limits; limits != nil {
+		max = limits.MaxInputBytes
+	}
+	var policy *RefPolicy
+	if location != nil {
+		policy = loader.refPolicy()
+	}
+	if policy != nil && policy.MaxDocumentBytes > 0 && (max <= 0 || policy.MaxDocumentBytes < max) {
+		max = policy.MaxDocumentBytes
+	}
+	if max > 0 {
+		// Read one more byte than allowed to detect inputs that are too large
+		reader = io.LimitReader(reader, max+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		if err := policy.checkSize(location, int64(len(data))); err != nil {
+			return nil, err
+		}
+	}
+	if err := loader.checkInputLimits(data, location); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// checkInputLimits checks data, the document at location, against the limits
+// before it gets decoded.
+func (loader *Loader) checkInputLimits(data []byte, location *url.URL) error {
+	limits := loader.Limits
+	if limits == nil {
+		return nil
+	}
+	exceeded := func(limit string, max int64) error {
+		err := &LimitError{Limit: limit, Max: max}
+		if location != nil {
+			err.Location = location.String()
+		}
+		return err
+	}
+
+	if limits.MaxInputBytes > 0 && int64(len(data)) > limits.MaxInputBytes {
+		return exceeded("MaxInputBytes", limits.MaxInputBytes)
+	}
+	if limits.MaxDepth <= 0 && limits.MaxAliases <= 0 {
+		return nil
+	}
+
+	depth, aliases, err := measureDocument(data)
+	if err != nil {
+		// Let decoding report syntax errors
+		if depth, err = jsonDepth(data); err != nil {
+			return nil
+		}
+	}
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return exceeded("MaxDepth", limits.MaxDepth)
+	}
+	if limits.MaxAliases > 0 && aliases > limits.MaxAliases {
+		return exceeded("MaxAliases", limits.MaxAliases)
+	}
+	return nil
+}
+
+// jsonDepth returns the nesting depth of the objects and arrays of a JSON document.
+func jsonDepth(data []byte) (int64, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var depth, maxDepth int64
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return maxDepth, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if depth++; depth > maxDepth {
+				maxDepth = depth
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// countRef checks the MaxRefs limit before resolving one more reference.
+func (loader *Loader) countRef() error {
+	loader.refCount++
+	if limits := loader.Limits; limits != nil && limits.MaxRefs > 0 && loader.refCount > limits.MaxRefs {
+		return &LimitError{Limit: "MaxRefs", Max: limits.MaxRefs}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package openapi3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireLimitError(t *testing.T, err error, limit string) {
+	t.Helper()
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr), "%v", err)
+	require.Equal(t, limit, limitErr.Limit)
+}
+
+func TestLoaderLimitsInputBytes(t *testing.T) {
+	spec := []byte(`{"openapi": "3.0.3", "info": {"title": "Pets", "version": "1.0.0"}, "paths": {}}`)
+
+	loader := NewLoader()
+	loader.Limits = &LoaderLimits{MaxInputBytes: int64(len(spec))}
+	_, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	_, err = loader.LoadFromIoReader(bytes.NewReader(spec))
+	require.NoError(t, err)
+
+	loader.Limits.MaxInputBytes--
+	_, err = loader.LoadFromData(spec)
+	require.EqualError(t, err, `MaxInputBytes limit of 79 exceeded`)
+	_, err = loader.LoadFromIoReader(bytes.NewReader(spec))
+	requireLimitError(t, err, "MaxInputBytes")
+}
+
+func TestLoaderLimitsDepth(t *testing.T) {
+	nested := strings.Repeat(`{"items": `, 50) + `{}` + strings.Repeat(`}`, 50)
+	spec := []byte(`{"openapi": "3.0.3", "info": {"title": "Deep", "version": "1.0.0"}, "paths": {},
+		"components": {"schemas": {"Deep": ` + nested + `}}}`)
+
+	loader := NewLoader()
+	loader.Limits = &LoaderLimits{MaxDepth: 54}
+	_, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+
+	loader.Limits.MaxDepth = 53
+	_, err = loader.LoadFromData(spec)
+	requireLimitError(t, err, "MaxDepth")
+}
+
+func TestLoaderLimitsAliases(t *testing.T) {
+	spec := []byte(`
+openapi: 3.0.3
+info: {title: Laughs, version: 1.0.0}
+paths: {}
+x-a: &a [lol, lol, lol]
+x-b: &b [*a, *a, *a]
+x-c: &c [*b, *b, *b]
+x-d: [*c, *c, *c]
+`)
+
+	loader := NewLoader()
+	// x-b expands 3 aliases, x-c 3 + 3*3 and x-d 3 + 3*12
+	loader.Limits = &LoaderLimits{MaxAliases: 3 + 12 + 39}
+	_, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+
+	loader.Limits.MaxAliases--
+	_, err = loader.LoadFromData(spec)
+	requireLimitError(t, err, "MaxAliases")
+
+	// Recursive aliases never fit
+	loader.Limits.MaxAliases = 1000
+	_, err = loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: Recursive, version: 1.0.0}
+paths: {}
+x-a: &a [*a]
+`))
+	requireLimitError(t, err, "MaxAliases")
+}
+
+func TestLoaderLimitsRefs(t *testing.T) {
+	spec := []byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    A: {$ref: '#/components/schemas/B'}
+    B: {$ref: '#/components/schemas/C'}
+    C: {type: string}
+`)
+
+	loader := NewLoader()
+	// B is resolved twice: as a component and through A
+	loader.Limits = &LoaderLimits{MaxRefs: 3}
+	_, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+
+	loader.Limits.MaxRefs = 2
+	_, err = loader.LoadFromData(spec)
+	require.EqualError(t, err, `MaxRefs limit of 2 exceeded`)
+}
+
+func TestLoaderLimitsExternalDocuments(t *testing.T) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
+		if location.Path == "components.yml" {
+			return []byte(`
+openapi: 3.0.3
+info: {title: Components, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pet: {type: object, properties: {tags: {type: array, items: {type: string}}}}
+`), nil
+		}
+		return nil, fmt.Errorf("unexpected location %q", location)
+	}
+	loader.Limits = &LoaderLimits{MaxDepth: 6}
+	_, err := loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pet: {$ref: 'components.yml#/components/schemas/Pet'}
+`))
+	require.ErrorContains(t, err, `"components.yml": MaxDepth limit of 6 exceeded`)
+}
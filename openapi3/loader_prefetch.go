@@ -0,0 +1,228 @@
+package openapi3
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// prefetcher reads and parses, ahead of the resolution and concurrently,
+// the external documents that a load refers to.
+// Resolution still visits references in order and only picks prefetched
+// documents up as it reaches them, so loading stays deterministic.
+type prefetcher struct {
+	loader  *Loader
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers chan struct{}
+	wg      sync.WaitGroup
+
+	mu        sync.Mutex
+	documents map[string]*prefetchedDocument
+}
+
+type prefetchedDocument struct {
+	done chan struct{}
+	data []byte
+	err  error
+	// doc is the parsed OpenAPI document, if data is one.
+	doc *T
+}
+
+// startPrefetch starts prefetching the documents that data, read from location,
+// refers to. It is a no-op unless Loader.PrefetchConcurrency is above 1.
+func (loader *Loader) startPrefetch(location *url.URL, data []byte) {
+	if loader.PrefetchConcurrency < 2 || !loader.IsExternalRefsAllowed || loader.prefetcher != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(loader.context())
+	p := &prefetcher{
+		loader:    loader,
+		ctx:       ctx,
+		cancel:    cancel,
+		workers:   make(chan struct{}, loader.PrefetchConcurrency),
+		documents: make(map[string]*prefetchedDocument),
+	}
+	loader.prefetcher = p
+	if location != nil {
+		// The root document is already read
+		p.documents[location.String()] = nil
+	}
+	var root any
+	if err := unmarshal(data, &root); err == nil {
+		p.discover(location, root)
+	}
+}
+
+// stopPrefetch cancels the reads still in progress and waits for them.
+func (loader *Loader) stopPrefetch() {
+	if p := loader.prefetcher; p != nil {
+		p.cancel()
+		p.wg.Wait()
+		loader.prefetcher = nil
+	}
+}
+
+// prefetched returns the prefetched document at location, waiting for it to be read.
+// It returns nil for documents not being prefetched.
+func (loader *Loader) prefetched(location *url.URL) *prefetchedDocument {
+	p := loader.prefetcher
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	document := p.documents[location.String()]
+	p.mu.Unlock()
+	if document == nil {
+		return nil
+	}
+	<-document.done
+	return document
+}
+
+// prefetchedDoc returns the parsed OpenAPI document at location, if prefetched.
+func (loader *Loader) prefetchedDoc(location *url.URL) *T {
+	if document := loader.prefetched(location); document != nil && document.err == nil {
+		return document.doc
+	}
+	return nil
+}
+
+// discover schedules the reads of the documents that root, the document at location, refers to.
+func (p *prefetcher) discover(location *url.URL, root any) {
+	refs := make(map[string]struct{})
+	collectExternalRefs(root, refs)
+
+	// Schedule in a stable order so that limits apply deterministically
+	uris := make([]string, 0, len(refs))
+	for ref := range refs {
+		uris = append(uris, ref)
+	}
+	sort.Strings(uris)
+	for _, ref := range uris {
+		resolved, err := resolvePathWithRef(ref, location)
+		if err != nil {
+			continue
+		}
+		resolved.Fragment = ""
+		p.schedule(resolved)
+	}
+}
+
+func (p *prefetcher) schedule(location *url.URL) {
+	uri := location.String()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.documents[uri]; ok {
+		return
+	}
+	if policy := p.loader.RefPolicy; policy != nil && policy.MaxDocuments > 0 && len(p.documents) >= policy.MaxDocuments {
+		return
+	}
+	document := &prefetchedDocument{done: make(chan struct{})}
+	p.documents[uri] = document
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer close(document.done)
+		select {
+		case p.workers <- struct{}{}:
+		case <-p.ctx.Done():
+			document.err = p.ctx.Err()
+			return
+		}
+		defer func() { <-p.workers }()
+
+		if document.data, document.err = p.loader.fetch(p.ctx, location); document.err != nil {
+			return
+		}
+		var root any
+		if err := unmarshal(document.data, &root); err != nil {
+			// Let the resolution report the error
+			return
+		}
+		// Parse OpenAPI documents, as opposed to for instance standalone schemas
+		if m, ok := root.(map[string]any); ok {
+			if _, ok := m["openapi"]; ok {
+				doc := &T{}
+				if err := unmarshal(document.data, doc); err == nil {
+					document.doc = doc
+				}
+			}
+		}
+		p.discover(location, root)
+	}()
+}
+
+// collectExternalRefs adds the "$ref" values of v that refer to other documents to refs.
+// Values holding raw data, such as examples and extensions, are skipped
+// as the loader does not resolve their "$ref"s.
+func collectExternalRefs(v any, refs map[string]struct{}) {
+	collectExternalRefsIn(v, false, refs)
+}
+
+// rawDataKeys are the keys of values holding raw data rather than OpenAPI or JSON Schema objects.
+var rawDataKeys = map[string]struct{}{
+	"default": {},
+	"const":   {},
+	"enum":    {},
+	"example": {},
+	// Of Example Objects
+	"value": {},
+}
+
+// namedValuesKeys are the keys of objects mapping names to values,
+// which can be any name, including those of rawDataKeys.
+var namedValuesKeys = map[string]struct{}{
+	"$defs":             {},
+	"callbacks":         {},
+	"content":           {},
+	"definitions":       {},
+	"dependentSchemas":  {},
+	"encoding":          {},
+	"examples":          {},
+	"headers":           {},
+	"links":             {},
+	"parameters":        {},
+	"pathItems":         {},
+	"paths":             {},
+	"patternProperties": {},
+	"properties":        {},
+	"requestBodies":     {},
+	"responses":         {},
+	"schemas":           {},
+	"securitySchemes":   {},
+	"variables":         {},
+	"webhooks":          {},
+}
+
+func collectExternalRefsIn(v any, named bool, refs map[string]struct{}) {
+	switch v := v.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok && ref != "" && !strings.HasPrefix(ref, "#") && !named {
+			refs[ref] = struct{}{}
+		}
+		for key, value := range v {
+			if named {
+				collectExternalRefsIn(value, false, refs)
+				continue
+			}
+			if _, ok := rawDataKeys[key]; ok || strings.HasPrefix(key, "x-") {
+				continue
+			}
+			if _, ok := value.([]any); ok && key == "examples" {
+				// Of schemas, as opposed to Example Objects by name
+				continue
+			}
+			_, ok := namedValuesKeys[key]
+			collectExternalRefsIn(value, ok, refs)
+		}
+	case []any:
+		for _, value := range v {
+			collectExternalRefsIn(value, false, refs)
+		}
+	}
+}
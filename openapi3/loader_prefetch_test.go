@@ -0,0 +1,172 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// prefetchSources serves a root document referring to documents that
+// refer to one more document each, counting the concurrent reads.
+type prefetchSources struct {
+	mu                sync.Mutex
+	inFlight, maxSeen int
+	reads             map[string]int
+}
+
+func (sources *prefetchSources) read(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
+	sources.mu.Lock()
+	sources.inFlight++
+	if sources.inFlight > sources.maxSeen {
+		sources.maxSeen = sources.inFlight
+	}
+	sources.reads[location.Path]++
+	sources.mu.Unlock()
+	defer func() {
+		sources.mu.Lock()
+		sources.inFlight--
+		sources.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(10 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var name string
+	switch path := location.Path; {
+	case path == "openapi.json":
+		schemas := make([]string, 0, 20)
+		for i := 0; i < 20; i++ {
+			schemas = append(schemas, fmt.Sprintf(`"S%d": {"$ref": "schemas/s%d.json#/components/schemas/S"}`, i, i))
+		}
+		return []byte(`{"openapi": "3.0.3", "info": {"title": "Many", "version": "1"}, "paths": {},
+			"components": {"schemas": {` + strings.Join(schemas, ",") + `}}}`), nil
+	case strings.HasPrefix(path, "schemas/s"):
+		name = strings.TrimSuffix(strings.TrimPrefix(path, "schemas/s"), ".json")
+		return []byte(`{"openapi": "3.0.3", "info": {"title": "` + name + `", "version": "1"}, "paths": {},
+			"components": {"schemas": {"S": {"type": "object", "properties": {"leaf": {"$ref": "leaves/l` + name + `.json"}}}}}}`), nil
+	case strings.HasPrefix(path, "schemas/leaves/l"):
+		name = strings.TrimSuffix(strings.TrimPrefix(path, "schemas/leaves/l"), ".json")
+		return []byte(`{"type": "string", "description": "` + name + `"}`), nil
+	}
+	return nil, fmt.Errorf("unexpected location %q", location)
+}
+
+func loadWithPrefetch(t *testing.T, concurrency int) (*T, *prefetchSources) {
+	sources := &prefetchSources{reads: make(map[string]int)}
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = sources.read
+	loader.PrefetchConcurrency = concurrency
+	doc, err := loader.LoadFromURI(&url.URL{Path: "openapi.json"})
+	require.NoError(t, err)
+	return doc, sources
+}
+
+func TestLoaderPrefetch(t *testing.T) {
+	sequential, sources := loadWithPrefetch(t, 0)
+	require.Equal(t, 1, sources.maxSeen)
+
+	concurrent, sources := loadWithPrefetch(t, 4)
+	require.Greater(t, sources.maxSeen, 1)
+	require.LessOrEqual(t, sources.maxSeen, 4)
+	require.Len(t, sources.reads, 41)
+	for path, reads := range sources.reads {
+		require.Equal(t, 1, reads, path)
+	}
+
+	require.Equal(t, "7", concurrent.Components.Schemas["S7"].Value.Properties["leaf"].Value.Description)
+	expected, err := json.Marshal(sequential)
+	require.NoError(t, err)
+	actual, err := json.Marshal(concurrent)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(actual))
+}
+
+func TestLoaderPrefetchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sources := &prefetchSources{reads: make(map[string]int)}
+	loader := NewLoader()
+	loader.Context = ctx
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = sources.read
+	loader.PrefetchConcurrency = 4
+	_, err := loader.LoadFromURI(&url.URL{Path: "openapi.json"})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestReadFromHTTPHonoursContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	location, err := url.Parse(ts.URL + "/openapi.json")
+	require.NoError(t, err)
+	loader := NewLoader()
+	loader.Context = ctx
+	_, err = loader.LoadFromURI(location)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCollectExternalRefsSkipsRawData(t *testing.T) {
+	var root any
+	err := unmarshal([]byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+x-extension: {$ref: 'extension.json'}
+paths:
+  /pets:
+    get:
+      parameters:
+      - $ref: 'parameters.json#/Limit'
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'schemas.json#/Pets'}
+              example: {$ref: 'example.json'}
+              examples:
+                one: {$ref: 'examples.json#/One'}
+                two:
+                  value: {$ref: 'value.json'}
+components:
+  schemas:
+    Pet:
+      type: object
+      default: {$ref: 'default.json'}
+      enum: [{$ref: 'enum.json'}]
+      examples: [{$ref: 'examples.json'}]
+      properties:
+        example: {$ref: 'property.json'}
+        x-tag: {$ref: 'tag.json'}
+`), &root)
+	require.NoError(t, err)
+
+	refs := make(map[string]struct{})
+	collectExternalRefs(root, refs)
+	require.Equal(t, map[string]struct{}{
+		"parameters.json#/Limit": {},
+		"schemas.json#/Pets":     {},
+		"examples.json#/One":     {},
+		"property.json":          {},
+		"tag.json":               {},
+	}, refs)
+}
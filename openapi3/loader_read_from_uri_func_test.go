@@ -1,6 +1,7 @@
 package openapi3
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -13,7 +14,7 @@ import (
 func TestLoaderReadFromURIFunc(t *testing.T) {
 	loader := NewLoader()
 	loader.IsExternalRefsAllowed = true
-	loader.ReadFromURIFunc = func(loader *Loader, url *url.URL) ([]byte, error) {
+	loader.ReadFromURIFunc = func(ctx context.Context, loader *Loader, url *url.URL) ([]byte, error) {
 		return os.ReadFile(filepath.Join("testdata", filepath.FromSlash(url.Path)))
 	}
 	doc, err := loader.LoadFromFile("recursiveRef/openapi.yml")
@@ -37,6 +38,7 @@ type multipleSourceLoaderExample struct {
 }
 
 func (l *multipleSourceLoaderExample) LoadFromURI(
+	ctx context.Context,
 	loader *Loader,
 	location *url.URL,
 ) ([]byte, error) {
@@ -0,0 +1,275 @@
+package openapi3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrRefSchemeNotAllowed is returned when a document URI has a scheme
+	// not in RefPolicy.AllowedSchemes.
+	ErrRefSchemeNotAllowed = errors.New("scheme is not allowed")
+	// ErrRefHostNotAllowed is returned when a document URI has a host
+	// not in RefPolicy.AllowedHosts.
+	ErrRefHostNotAllowed = errors.New("host is not allowed")
+	// ErrRefPrivateAddress is returned when a document host is or resolves to
+	// a loopback, private, link-local or unspecified IP address
+	// and RefPolicy.BlockPrivateNetworks is set.
+	ErrRefPrivateAddress = errors.New("host has a private network address")
+	// ErrRefOutsideRootDir is returned when a local document is outside of RefPolicy.RootDir.
+	ErrRefOutsideRootDir = errors.New("path is outside of the root directory")
+	// ErrRefTooManyDocuments is returned when loading would read more than RefPolicy.MaxDocuments.
+	ErrRefTooManyDocuments = errors.New("too many documents")
+	// ErrRefDocumentTooLarge is returned when a document is larger than RefPolicy.MaxDocumentBytes.
+	ErrRefDocumentTooLarge = errors.New("document is too large")
+)
+
+// RefPolicyError is returned when Loader.RefPolicy does not allow reading a document.
+// Err is one of the ErrRef* errors.
+type RefPolicyError struct {
+	Location string
+	Err      error
+}
+
+var _ error = (*RefPolicyError)(nil)
+
+func (err *RefPolicyError) Error() string {
+	return fmt.Sprintf("reading %q is not allowed: %v", err.Location, err.Err)
+}
+
+func (err *RefPolicyError) Unwrap() error {
+	return err.Err
+}
+
+// RefPolicy restricts the documents a Loader reads, which matters when
+// loading documents from untrusted sources.
+// The zero value of each field sets no restriction.
+type RefPolicy struct {
+	// AllowedSchemes lists the URI schemes documents can be read from,
+	// such as "https" or "file". Paths without a scheme have the "file" scheme.
+	AllowedSchemes []string
+
+	// AllowedHosts lists the hosts remote documents can be read from.
+	// A "*." prefix matches any subdomain, so "*.example.com" matches "api.example.com"
+	// but not "example.com".
+	AllowedHosts []string
+
+	// BlockPrivateNetworks rejects remote documents whose host is or resolves to
+	// a loopback, private, link-local or unspecified IP address.
+	// ReadFromHTTP also checks the address it connects to, after any redirection.
+	BlockPrivateNetworks bool
+
+	// RootDir confines local documents to a directory and its subdirectories.
+	RootDir string
+
+	// MaxDocuments limits the number of distinct documents read by a load,
+	// the root document included.
+	MaxDocuments int
+
+	// MaxDocumentBytes limits the size of each document.
+	MaxDocumentBytes int64
+}
+
+// checkRefPolicy tells whether the policy allows reading the document at location.
+// Documents that URIRewrites map to local files are not checked, but for their count.
+func (loader *Loader) checkRefPolicy(ctx context.Context, location *url.URL) error {
+	if rewrite, _, err := loader.rewrite(location); err != nil || rewrite != nil {
+		return err
+	}
+	return loader.RefPolicy.check(ctx, location)
+}
+
+// countDocument checks the MaxDocuments limit before reading the document at location.
+func (loader *Loader) countDocument(location *url.URL) error {
+	uri := location.String()
+	if _, ok := loader.readDocuments[uri]; ok {
+		return nil
+	}
+	if max := loader.RefPolicy.MaxDocuments; max > 0 && len(loader.readDocuments) >= max {
+		return &RefPolicyError{Location: uri, Err: ErrRefTooManyDocuments}
+	}
+	if loader.readDocuments == nil {
+		loader.readDocuments = make(map[string]struct{})
+	}
+	loader.readDocuments[uri] = struct{}{}
+	return nil
+}
+
+func (loader *Loader) refPolicy() *RefPolicy {
+	if loader == nil {
+		return nil
+	}
+	return loader.RefPolicy
+}
+
+func (loader *Loader) context() context.Context {
+	if loader.Context == nil {
+		return context.Background()
+	}
+	return loader.Context
+}
+
+func (policy *RefPolicy) check(ctx context.Context, location *url.URL) error {
+	scheme := strings.ToLower(location.Scheme)
+	if scheme == "" {
+		scheme = "file"
+	}
+	if len(policy.AllowedSchemes) != 0 && !policy.allowsScheme(scheme) {
+		return &RefPolicyError{Location: location.String(), Err: ErrRefSchemeNotAllowed}
+	}
+
+	if location.Host == "" {
+		if policy.RootDir != "" && scheme == "file" {
+			return policy.checkPath(location)
+		}
+		return nil
+	}
+	return policy.checkRemote(ctx, location)
+}
+
+func (policy *RefPolicy) allowsScheme(scheme string) bool {
+	for _, allowed := range policy.AllowedSchemes {
+		if strings.EqualFold(allowed, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy *RefPolicy) checkRemote(ctx context.Context, location *url.URL) error {
+	host := strings.ToLower(location.Hostname())
+	if len(policy.AllowedHosts) != 0 && !policy.allowsHost(host) {
+		return &RefPolicyError{Location: location.String(), Err: ErrRefHostNotAllowed}
+	}
+
+	if !policy.BlockPrivateNetworks {
+		return nil
+	}
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return err
+		}
+		ips = ips[:0]
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if isPrivateIP(ip) {
+			return &RefPolicyError{Location: location.String(), Err: ErrRefPrivateAddress}
+		}
+	}
+	return nil
+}
+
+func (policy *RefPolicy) allowsHost(host string) bool {
+	for _, allowed := range policy.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix := strings.TrimPrefix(allowed, "*"); suffix != allowed {
+			if strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+func (policy *RefPolicy) checkPath(location *url.URL) error {
+	root, err := realPath(policy.RootDir)
+	if err != nil {
+		return err
+	}
+	path, err := realPath(filepath.FromSlash(location.Path))
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return &RefPolicyError{Location: location.String(), Err: ErrRefOutsideRootDir}
+	}
+	return nil
+}
+
+// realPath returns the absolute path of path, with symbolic links evaluated
+// when path exists.
+func realPath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real, nil
+	}
+	return path, nil
+}
+
+func (policy *RefPolicy) checkSize(location *url.URL, size int64) error {
+	if policy.MaxDocumentBytes > 0 && size > policy.MaxDocumentBytes {
+		return &RefPolicyError{Location: location.String(), Err: ErrRefDocumentTooLarge}
+	}
+	return nil
+}
+
+// httpClient returns a copy of cl that applies the policy to redirections
+// and to the addresses it connects to.
+func (policy *RefPolicy) httpClient(cl *http.Client) *http.Client {
+	guarded := *cl
+	checkRedirect := cl.CheckRedirect
+	guarded.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := policy.check(req.Context(), req.URL); err != nil {
+			return err
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+
+	if !policy.BlockPrivateNetworks {
+		return &guarded
+	}
+	roundTripper := cl.Transport
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+	transport, ok := roundTripper.(*http.Transport)
+	if !ok {
+		// Only the host names are checked then
+		return &guarded
+	}
+	transport = transport.Clone()
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok && isPrivateIP(tcpAddr.IP) {
+			conn.Close()
+			return nil, &RefPolicyError{Location: addr, Err: ErrRefPrivateAddress}
+		}
+		return conn, nil
+	}
+	guarded.Transport = transport
+	return &guarded
+}
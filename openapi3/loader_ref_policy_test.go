@@ -0,0 +1,159 @@
+package openapi3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefPolicyLocalDocuments(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policy   *RefPolicy
+		expected error
+	}{
+		{
+			name:   "no restriction",
+			policy: &RefPolicy{},
+		},
+		{
+			name:   "allowed",
+			policy: &RefPolicy{AllowedSchemes: []string{"file"}, RootDir: "testdata/positions", MaxDocuments: 2, MaxDocumentBytes: 1 << 10},
+		},
+		{
+			name:     "scheme",
+			policy:   &RefPolicy{AllowedSchemes: []string{"https"}},
+			expected: ErrRefSchemeNotAllowed,
+		},
+		{
+			name:     "root directory",
+			policy:   &RefPolicy{RootDir: "testdata/recursiveRef"},
+			expected: ErrRefOutsideRootDir,
+		},
+		{
+			name:     "documents",
+			policy:   &RefPolicy{MaxDocuments: 1},
+			expected: ErrRefTooManyDocuments,
+		},
+		{
+			name:     "bytes",
+			policy:   &RefPolicy{MaxDocumentBytes: 100},
+			expected: ErrRefDocumentTooLarge,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			loader := NewLoader()
+			loader.IsExternalRefsAllowed = true
+			loader.RefPolicy = tc.policy
+			_, err := loader.LoadFromFile("testdata/positions/openapi.yml")
+			if tc.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expected)
+			var policyErr *RefPolicyError
+			require.True(t, errors.As(err, &policyErr))
+		})
+	}
+}
+
+func TestRefPolicyRootDirTraversal(t *testing.T) {
+	spec := []byte(`
+openapi: 3.0.3
+info: {title: Traversal, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Secret:
+      $ref: '../../../../../../etc/passwd'
+`)
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.RefPolicy = &RefPolicy{RootDir: "testdata/positions"}
+	_, err := loader.LoadFromDataWithPath(spec, &url.URL{Path: "testdata/positions/traversal.yml"})
+	require.ErrorIs(t, err, ErrRefOutsideRootDir)
+	require.ErrorContains(t, err, `reading "../../../../etc/passwd" is not allowed: path is outside of the root directory`)
+}
+
+func TestRefPolicyRemoteDocuments(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect.yml":
+			http.Redirect(w, r, strings.Replace(r.Host, "127.0.0.1", "http://localhost", 1)+"/components.yml", http.StatusFound)
+		case "/components.yml":
+			fmt.Fprint(w, `
+openapi: 3.0.3
+info: {title: Components, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pet: {type: object}
+`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	load := func(policy *RefPolicy, document string) error {
+		spec := []byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pet:
+      $ref: '` + ts.URL + `/` + document + `#/components/schemas/Pet'
+`)
+		loader := NewLoader()
+		loader.IsExternalRefsAllowed = true
+		loader.RefPolicy = policy
+		_, err := loader.LoadFromData(spec)
+		return err
+	}
+
+	err := load(&RefPolicy{AllowedSchemes: []string{"http"}, AllowedHosts: []string{"127.0.0.1"}}, "components.yml")
+	require.NoError(t, err)
+
+	err = load(&RefPolicy{AllowedSchemes: []string{"https"}}, "components.yml")
+	require.ErrorIs(t, err, ErrRefSchemeNotAllowed)
+
+	err = load(&RefPolicy{AllowedHosts: []string{"*.example.com"}}, "components.yml")
+	require.ErrorIs(t, err, ErrRefHostNotAllowed)
+
+	err = load(&RefPolicy{BlockPrivateNetworks: true}, "components.yml")
+	require.ErrorIs(t, err, ErrRefPrivateAddress)
+
+	err = load(&RefPolicy{MaxDocumentBytes: 10}, "components.yml")
+	require.ErrorIs(t, err, ErrRefDocumentTooLarge)
+
+	// Redirections are checked too
+	err = load(&RefPolicy{AllowedHosts: []string{"127.0.0.1"}}, "redirect.yml")
+	require.ErrorIs(t, err, ErrRefHostNotAllowed)
+}
+
+func TestRefPolicyHTTPClientChecksConnections(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	policy := &RefPolicy{BlockPrivateNetworks: true}
+	client := policy.httpClient(http.DefaultClient)
+	defer client.CloseIdleConnections()
+	_, err := client.Get(ts.URL)
+	require.ErrorIs(t, err, ErrRefPrivateAddress)
+}
+
+func TestRefPolicyAllowedHosts(t *testing.T) {
+	policy := &RefPolicy{AllowedHosts: []string{"example.com", "*.example.org"}}
+	require.True(t, policy.allowsHost("example.com"))
+	require.False(t, policy.allowsHost("api.example.com"))
+	require.True(t, policy.allowsHost("api.example.org"))
+	require.False(t, policy.allowsHost("example.org"))
+	require.False(t, policy.allowsHost("evilexample.org"))
+}
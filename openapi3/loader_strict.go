@@ -0,0 +1,241 @@
+package openapi3
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnknownFieldError is returned by a Loader with StrictDecoding for fields
+// that are neither defined by the specification nor extensions.
+type UnknownFieldError struct {
+	// Location is the location of the document, empty for documents loaded from data.
+	Location string
+	// Pointer is the JSON pointer of the field within the document.
+	Pointer string
+	// Field is the name of the field.
+	Field string
+	// Position is where the field is defined, if known.
+	Position *Position
+}
+
+var _ error = (*UnknownFieldError)(nil)
+
+func (err *UnknownFieldError) Error() string {
+	if err.Position != nil {
+		return fmt.Sprintf("%s: unknown field %q at #%s", err.Position, err.Field, err.Pointer)
+	}
+	return fmt.Sprintf("unknown field %q at %s#%s", err.Field, err.Location, err.Pointer)
+}
+
+// DuplicateKeyError is returned by a Loader with StrictDecoding for keys
+// defined more than once in the same mapping.
+type DuplicateKeyError struct {
+	// Location is the location of the document, empty for documents loaded from data.
+	Location string
+	// Pointer is the JSON pointer of the key within the document.
+	Pointer string
+	// Key is the duplicated key.
+	Key string
+	// Position is where the key is defined again.
+	Position *Position
+	// Previous is where the key is first defined.
+	Previous *Position
+}
+
+var _ error = (*DuplicateKeyError)(nil)
+
+func (err *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%s: duplicate key %q at #%s, first defined at %s", err.Position, err.Key, err.Pointer, err.Previous)
+}
+
+// checkDuplicateKeys fails with the keys data, the document at location,
+// defines more than once, when decoding strictly.
+func (loader *Loader) checkDuplicateKeys(location *url.URL, data []byte) error {
+	if !loader.StrictDecoding {
+		return nil
+	}
+	var errs MultiError
+	for _, err := range duplicateKeys(data, positionsKey(location)) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkUnknownFields fails with the unknown fields of element, decoded from
+// the value at pointer in the document at location, when decoding strictly.
+// The positions of the document must be indexed already.
+func (loader *Loader) checkUnknownFields(location *url.URL, pointer string, element any) error {
+	if !loader.StrictDecoding {
+		return nil
+	}
+	key := positionsKey(location)
+	fields := &unknownFields{
+		location: key,
+		index:    loader.positions[key],
+	}
+	fields.walk(strings.TrimSuffix(pointer, "/"), reflect.ValueOf(element))
+	if len(fields.errs) != 0 {
+		return fields.errs
+	}
+	return nil
+}
+
+// unknownFields collects the unknown fields of decoded values.
+// Decoding keeps the unknown fields of objects in their Extensions,
+// and those next to a "$ref" along their reference.
+type unknownFields struct {
+	location string
+	index    positions
+	errs     MultiError
+}
+
+func (fields *unknownFields) report(pointer, field string) {
+	pointer += "/" + escapeRefString(field)
+	fields.errs = append(fields.errs, &UnknownFieldError{
+		Location: fields.location,
+		Pointer:  pointer,
+		Field:    field,
+		Position: fields.index[pointer],
+	})
+}
+
+func (fields *unknownFields) walk(pointer string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			fields.walk(pointer, v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			fields.walk(pointer+"/"+strconv.Itoa(i), v.Index(i))
+		}
+	case reflect.Map:
+		// Values of any type hold raw data, such as extensions and examples
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() == reflect.Interface {
+			return
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fields.walk(pointer+"/"+escapeRefString(key), v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))
+		}
+	case reflect.Struct:
+		fields.object(pointer, v)
+	}
+}
+
+func (fields *unknownFields) object(pointer string, v reflect.Value) {
+	// References keep the names of their siblings
+	if ref := v.FieldByName("Ref"); ref.IsValid() && ref.Kind() == reflect.String && ref.String() != "" {
+		if extra := v.FieldByName("extra"); extra.IsValid() {
+			for i := 0; i < extra.Len(); i++ {
+				// OpenAPI 3.1 allows overriding the summary and description of references
+				switch field := extra.Index(i).String(); {
+				case strings.HasPrefix(field, "x-"), field == "summary", field == "description":
+				default:
+					fields.report(pointer, field)
+				}
+			}
+		}
+		return
+	}
+
+	if v.CanAddr() {
+		switch x := v.Addr().Interface().(type) {
+		case *T:
+			// Documents holding only components have no fields of their own
+			if x.OpenAPI == "" {
+				fields.fields(pointer, v, false)
+				return
+			}
+		case *Paths:
+			for _, path := range componentNames(x.Map()) {
+				if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "x-") {
+					fields.report(pointer, path)
+				}
+			}
+			fields.walk(pointer, reflect.ValueOf(x.Map()))
+			return
+		case *Responses:
+			for _, code := range componentNames(x.Map()) {
+				if !isResponseCode(code) {
+					fields.report(pointer, code)
+				}
+			}
+			fields.walk(pointer, reflect.ValueOf(x.Map()))
+			return
+		case *Callback:
+			fields.walk(pointer, reflect.ValueOf(x.Map()))
+			return
+		}
+	}
+	fields.fields(pointer, v, true)
+}
+
+// fields walks the fields of the struct v, reporting the unknown ones
+// kept in its Extensions if extensions is set.
+func (fields *unknownFields) fields(pointer string, v reflect.Value, extensions bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Name == "Extensions" {
+			if !extensions {
+				continue
+			}
+			names := make([]string, 0, v.Field(i).Len())
+			for _, key := range v.Field(i).MapKeys() {
+				if name := key.String(); !strings.HasPrefix(name, "x-") && !(t == schemaType && schemaAnnotations[name]) {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fields.report(pointer, name)
+			}
+			continue
+		}
+		switch name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name {
+		case "-":
+		case "":
+			// Embedded structs and the values of references
+			fields.walk(pointer, v.Field(i))
+		default:
+			fields.walk(pointer+"/"+escapeRefString(name), v.Field(i))
+		}
+	}
+}
+
+var schemaType = reflect.TypeOf(Schema{})
+
+// schemaAnnotations are the JSON Schema keywords that Schema keeps in its Extensions.
+var schemaAnnotations = map[string]bool{
+	"$comment":    true,
+	"definitions": true,
+}
+
+// isResponseCode tells whether key is a key of the Responses object.
+func isResponseCode(key string) bool {
+	if key == "default" {
+		return true
+	}
+	if len(key) != 3 || key[0] < '1' || key[0] > '5' {
+		return false
+	}
+	if key[1:] == "XX" {
+		return true
+	}
+	return '0' <= key[1] && key[1] <= '9' && '0' <= key[2] && key[2] <= '9'
+}
@@ -0,0 +1,124 @@
+package openapi3
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictDecodingUnknownFields(t *testing.T) {
+	spec := []byte(`
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+  x-audience: public
+paths:
+  /pets:
+    get:
+      sumary: List pets
+      responses:
+        defualt:
+          description: An error
+        '200':
+          description: Pets
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pets'
+                description: All the pets
+                type: array
+components:
+  schemas:
+    Pets:
+      $comment: Listed in pages
+      type: array
+      items:
+        type: object
+        additonalProperties: false
+`[1:])
+
+	loader := NewLoader()
+	_, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+
+	loader = NewLoader()
+	loader.StrictDecoding = true
+	_, err = loader.LoadFromData(spec)
+	require.Error(t, err)
+
+	var errs MultiError
+	require.True(t, errors.As(err, &errs))
+	var got []string
+	for _, err := range errs {
+		var fieldErr *UnknownFieldError
+		require.True(t, errors.As(err, &fieldErr))
+		got = append(got, err.Error())
+	}
+	require.Equal(t, []string{
+		`28:9: unknown field "additonalProperties" at #/components/schemas/Pets/items/additonalProperties`,
+		`9:7: unknown field "sumary" at #/paths/~1pets/get/sumary`,
+		`11:9: unknown field "defualt" at #/paths/~1pets/get/responses/defualt`,
+		`20:17: unknown field "type" at #/paths/~1pets/get/responses/200/content/application~1json/schema/type`,
+	}, got)
+}
+
+func TestStrictDecodingDuplicateKeys(t *testing.T) {
+	spec := []byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0", "title": "Animals"},
+  "paths": {}
+}`)
+
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	require.Equal(t, "Animals", doc.Info.Title)
+
+	loader = NewLoader()
+	loader.StrictDecoding = true
+	_, err = loader.LoadFromData(spec)
+	require.EqualError(t, err, `3:49: duplicate key "title" at #/info/title, first defined at 3:12`)
+
+	var keyErr *DuplicateKeyError
+	require.True(t, errors.As(err, &keyErr))
+	require.Equal(t, "/info/title", keyErr.Pointer)
+	require.Equal(t, &Position{Line: 3, Column: 12}, keyErr.Previous)
+
+	// Keys defined in place override merged ones
+	_, err = loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info: &info
+  title: Pets
+  version: 1.0.0
+x-info:
+  <<: *info
+  title: Animals
+paths: {}
+`[1:]))
+	require.NoError(t, err)
+
+	_, err = loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths: {}
+paths: {}
+`[1:]))
+	require.EqualError(t, err, `6:1: duplicate key "paths" at #/paths, first defined at 5:1`)
+}
+
+func TestStrictDecodingExternalComponents(t *testing.T) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.StrictDecoding = true
+	_, err := loader.LoadFromFile("testdata/strict/openapi.yml")
+	require.EqualError(t, err, `testdata/strict/components.yml:4:5: unknown field "requried" at #/schemas/Pet/requried`)
+
+	var fieldErr *UnknownFieldError
+	require.True(t, errors.As(err, &fieldErr))
+	require.Equal(t, "testdata/strict/components.yml", fieldErr.Location)
+	require.Equal(t, "requried", fieldErr.Field)
+}
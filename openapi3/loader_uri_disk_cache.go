@@ -0,0 +1,285 @@
+package openapi3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// ErrURINotCached is returned by URIDiskCache in offline mode for documents it does not hold.
+	ErrURINotCached = errors.New("document is not cached")
+	// ErrLockfileMismatch is returned by URIDiskCache when a document differs from the hash
+	// the lockfile records for it.
+	ErrLockfileMismatch = errors.New("document does not match the lockfile")
+	// ErrNotInLockfile is returned by URIDiskCache with FrozenLockfile
+	// for documents the lockfile does not record.
+	ErrNotInLockfile = errors.New("document is not in the lockfile")
+)
+
+// URIDiskCache reads remote HTTP URIs through a cache stored on disk,
+// which persists across processes. Cached documents are revalidated with
+// their ETag and Last-Modified headers, once per URIDiskCache value.
+// Its ReadFromURI method is a ReadFromURIFunc, which can be combined with
+// ReadFromFile using ReadFromURIs to read local files too:
+//
+//	cache := &openapi3.URIDiskCache{Dir: ".openapi-cache", Lockfile: "openapi.lock.json"}
+//	loader.ReadFromURIFunc = openapi3.ReadFromURIs(cache.ReadFromURI, openapi3.ReadFromFile)
+type URIDiskCache struct {
+	// Dir is the directory the documents are stored in.
+	Dir string
+
+	// Client fetches the documents, http.DefaultClient when nil.
+	Client *http.Client
+
+	// Offline serves the cached documents without revalidating them,
+	// and fails for documents not cached.
+	Offline bool
+
+	// Lockfile is the path of a manifest of the SHA-256 hashes of the documents read.
+	// Documents that differ from their recorded hash fail to be read, others are recorded.
+	Lockfile string
+
+	// FrozenLockfile fails to read documents the lockfile does not record.
+	FrozenLockfile bool
+
+	mu       sync.Mutex
+	manifest map[string]string
+	// fresh holds the documents fetched or revalidated by this value.
+	fresh map[string][]byte
+}
+
+// uriDiskCacheEntry is the metadata of a cached document.
+type uriDiskCacheEntry struct {
+	URI          string `json:"uri" yaml:"uri"`
+	ETag         string `json:"etag,omitempty" yaml:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty" yaml:"lastModified,omitempty"`
+}
+
+// ReadFromURI is a ReadFromURIFunc which reads remote HTTP URIs through the cache.
+func (cache *URIDiskCache) ReadFromURI(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
+	if location.Scheme == "" || location.Host == "" {
+		return nil, ErrURINotSupported
+	}
+	uri := location.String()
+
+	cache.mu.Lock()
+	data, ok := cache.fresh[uri]
+	cache.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	entry, data, err := cache.read(ctx, loader, location)
+	if err != nil {
+		return nil, err
+	}
+	// Check fetched documents before they replace the cached ones
+	if err := cache.lock(uri, data); err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		if err := cache.store(entry, data); err != nil {
+			return nil, err
+		}
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.fresh == nil {
+		cache.fresh = make(map[string][]byte)
+	}
+	cache.fresh[uri] = data
+	return data, nil
+}
+
+// read returns the document at location, from the cache or fetched,
+// along with the entry to store for fetched documents.
+func (cache *URIDiskCache) read(ctx context.Context, loader *Loader, location *url.URL) (*uriDiskCacheEntry, []byte, error) {
+	uri := location.String()
+	entry, data, err := cache.load(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cache.Offline {
+		if entry == nil {
+			return nil, nil, fmt.Errorf("reading %q: %w", uri, ErrURINotCached)
+		}
+		return nil, data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	client := cache.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if policy := loader.refPolicy(); policy != nil {
+		client = policy.httpClient(client)
+		defer client.CloseIdleConnections()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		return nil, data, nil
+	}
+	if resp.StatusCode > 399 {
+		return nil, nil, fmt.Errorf("error loading %q: request returned status code %d", uri, resp.StatusCode)
+	}
+	if data, err = loader.readAllLimited(resp.Body, location); err != nil {
+		return nil, nil, err
+	}
+
+	entry = &uriDiskCacheEntry{
+		URI:          uri,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	return entry, data, nil
+}
+
+// paths returns the paths of the metadata and of the contents of the entry for uri.
+func (cache *URIDiskCache) paths(uri string) (string, string) {
+	sum := sha256.Sum256([]byte(uri))
+	key := filepath.Join(cache.Dir, hex.EncodeToString(sum[:]))
+	return key + ".json", key + ".body"
+}
+
+// load returns the cached entry for uri, nil when there is none.
+func (cache *URIDiskCache) load(uri string) (*uriDiskCacheEntry, []byte, error) {
+	metaPath, bodyPath := cache.paths(uri)
+	meta, err := os.ReadFile(metaPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var entry uriDiskCacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URI != uri {
+		// Treat corrupted entries as missing
+		return nil, nil, nil
+	}
+	data, err := os.ReadFile(bodyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &entry, data, nil
+}
+
+func (cache *URIDiskCache) store(entry *uriDiskCacheEntry, data []byte) error {
+	if err := os.MkdirAll(cache.Dir, 0o755); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	metaPath, bodyPath := cache.paths(entry.URI)
+	// Write the contents first, so that metadata always describes complete contents
+	if err := writeFileAtomic(bodyPath, data); err != nil {
+		return err
+	}
+	return writeFileAtomic(metaPath, meta)
+}
+
+// lock checks data, the document at uri, against the lockfile, recording it if new.
+func (cache *URIDiskCache) lock(uri string, data []byte) error {
+	if cache.Lockfile == "" {
+		return nil
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.manifest == nil {
+		manifest, err := readLockfile(cache.Lockfile)
+		if err != nil {
+			return err
+		}
+		cache.manifest = manifest
+	}
+
+	sum := sha256.Sum256(data)
+	hash := "sha256:" + hex.EncodeToString(sum[:])
+	locked, ok := cache.manifest[uri]
+	switch {
+	case ok && locked != hash:
+		return fmt.Errorf("reading %q: %w: got %s, expected %s", uri, ErrLockfileMismatch, hash, locked)
+	case ok:
+		return nil
+	case cache.FrozenLockfile:
+		return fmt.Errorf("reading %q: %w", uri, ErrNotInLockfile)
+	}
+
+	cache.manifest[uri] = hash
+	manifest, err := json.MarshalIndent(cache.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(cache.Lockfile, append(manifest, '\n'))
+}
+
+func readLockfile(path string) (map[string]string, error) {
+	manifest := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid lockfile %q: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// writeFileAtomic writes data to path through a temporary file,
+// so that readers never see partial contents.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
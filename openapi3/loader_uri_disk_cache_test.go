@@ -0,0 +1,160 @@
+package openapi3
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// schemaServer serves a components document, revalidated with an ETag.
+type schemaServer struct {
+	mu           sync.Mutex
+	version      int
+	requests     int
+	notModifieds int
+}
+
+func (server *schemaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.requests++
+
+	etag := fmt.Sprintf(`"v%d"`, server.version)
+	if r.Header.Get("If-None-Match") == etag {
+		server.notModifieds++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	fmt.Fprintf(w, `
+openapi: 3.0.3
+info: {title: Common, version: %d.0.0}
+paths: {}
+components:
+  schemas:
+    Error: {type: object}
+`, server.version)
+}
+
+func loadWithDiskCache(cache *URIDiskCache, serverURL string) (*T, error) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = ReadFromURIs(cache.ReadFromURI, ReadFromFile)
+	return loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Error: {$ref: '` + serverURL + `/common.yaml#/components/schemas/Error'}
+`))
+}
+
+func TestURIDiskCache(t *testing.T) {
+	server := &schemaServer{version: 1}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	dir := t.TempDir()
+	lockfile := filepath.Join(dir, "openapi.lock.json")
+	newCache := func() *URIDiskCache {
+		return &URIDiskCache{Dir: filepath.Join(dir, "cache"), Lockfile: lockfile}
+	}
+
+	doc, err := loadWithDiskCache(newCache(), ts.URL)
+	require.NoError(t, err)
+	require.NotNil(t, doc.Components.Schemas["Error"].Value)
+	require.Equal(t, 1, server.requests)
+
+	lock, err := os.ReadFile(lockfile)
+	require.NoError(t, err)
+	require.Contains(t, string(lock), `"`+ts.URL+`/common.yaml": "sha256:`)
+
+	// A new cache on the same directory revalidates its entries
+	_, err = loadWithDiskCache(newCache(), ts.URL)
+	require.NoError(t, err)
+	require.Equal(t, 2, server.requests)
+	require.Equal(t, 1, server.notModifieds)
+
+	// Offline, entries are served without requests
+	offline := newCache()
+	offline.Offline = true
+	_, err = loadWithDiskCache(offline, ts.URL)
+	require.NoError(t, err)
+	require.Equal(t, 2, server.requests)
+
+	// Changed documents no longer match the lockfile
+	server.version = 2
+	_, err = loadWithDiskCache(newCache(), ts.URL)
+	require.ErrorIs(t, err, ErrLockfileMismatch)
+	// and do not replace the cached ones
+	_, err = loadWithDiskCache(offline, ts.URL)
+	require.NoError(t, err)
+
+	// Unless they get removed from the lockfile
+	err = os.Remove(lockfile)
+	require.NoError(t, err)
+	doc, err = loadWithDiskCache(newCache(), ts.URL)
+	require.NoError(t, err)
+	require.NotNil(t, doc.Components.Schemas["Error"].Value)
+}
+
+func TestURIDiskCacheOfflineMiss(t *testing.T) {
+	cache := &URIDiskCache{Dir: t.TempDir(), Offline: true}
+	_, err := loadWithDiskCache(cache, "https://schemas.example.com")
+	require.ErrorIs(t, err, ErrURINotCached)
+}
+
+func TestURIDiskCacheFrozenLockfile(t *testing.T) {
+	ts := httptest.NewServer(&schemaServer{version: 1})
+	defer ts.Close()
+
+	dir := t.TempDir()
+	lockfile := filepath.Join(dir, "openapi.lock.json")
+	err := os.WriteFile(lockfile, []byte(`{}`), 0o644)
+	require.NoError(t, err)
+
+	cache := &URIDiskCache{Dir: dir, Lockfile: lockfile, FrozenLockfile: true}
+	_, err = loadWithDiskCache(cache, ts.URL)
+	require.ErrorIs(t, err, ErrNotInLockfile)
+}
+
+func TestURIDiskCacheLimits(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `
+openapi: 3.0.3
+info: {title: Common, version: 1.0.0, description: %q}
+paths: {}
+`, strings.Repeat("a", 1<<10))
+	}))
+	defer ts.Close()
+
+	cache := &URIDiskCache{Dir: t.TempDir()}
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = cache.ReadFromURI
+	loader.Limits = &LoaderLimits{MaxInputBytes: 1 << 9}
+	_, err := loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Error: {$ref: '` + ts.URL + `/common.yaml#/components/schemas/Error'}
+`))
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, ts.URL+"/common.yaml", limitErr.Location)
+
+	// Documents exceeding the limits are not cached
+	entries, err := os.ReadDir(cache.Dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
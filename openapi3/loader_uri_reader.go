@@ -1,6 +1,7 @@
 package openapi3
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -12,8 +13,9 @@ import (
 )
 
 // ReadFromURIFunc defines a function which reads the contents of a resource
-// located at a URI.
-type ReadFromURIFunc func(loader *Loader, url *url.URL) ([]byte, error)
+// located at a URI. It should stop reading when ctx is done.
+// It is called concurrently when Loader.PrefetchConcurrency is above 1.
+type ReadFromURIFunc func(ctx context.Context, loader *Loader, url *url.URL) ([]byte, error)
 
 var uriMu = &sync.RWMutex{}
 
@@ -26,9 +28,9 @@ var ErrURINotSupported = errors.New("unsupported URI")
 // support the URI and returns ErrURINotSupported, the next function is checked
 // until a match is found, or the URI is not supported by any.
 func ReadFromURIs(readers ...ReadFromURIFunc) ReadFromURIFunc {
-	return func(loader *Loader, url *url.URL) ([]byte, error) {
+	return func(ctx context.Context, loader *Loader, url *url.URL) ([]byte, error) {
 		for i := range readers {
-			buf, err := readers[i](loader, url)
+			buf, err := readers[i](ctx, loader, url)
 			if err == ErrURINotSupported {
 				continue
 			} else if err != nil {
@@ -48,15 +50,21 @@ var DefaultReadFromURI = URIMapCache(ReadFromURIs(ReadFromHTTP(http.DefaultClien
 // read the contents from a remote HTTP URI. This client may be customized to
 // implement timeouts, RFC 7234 caching, etc.
 func ReadFromHTTP(cl *http.Client) ReadFromURIFunc {
-	return func(loader *Loader, location *url.URL) ([]byte, error) {
+	return func(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
 		if location.Scheme == "" || location.Host == "" {
 			return nil, ErrURINotSupported
 		}
-		req, err := http.NewRequest("GET", location.String(), nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", location.String(), nil)
 		if err != nil {
 			return nil, err
 		}
-		resp, err := cl.Do(req)
+		client := cl
+		policy := loader.refPolicy()
+		if policy != nil {
+			client = policy.httpClient(cl)
+			defer client.CloseIdleConnections()
+		}
+		resp, err := client.Do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -64,6 +72,20 @@ func ReadFromHTTP(cl *http.Client) ReadFromURIFunc {
 		if resp.StatusCode > 399 {
 			return nil, fmt.Errorf("error loading %q: request returned status code %d", location.String(), resp.StatusCode)
 		}
+		if policy != nil && policy.MaxDocumentBytes > 0 {
+			if err := policy.checkSize(location, resp.ContentLength); err != nil {
+				return nil, err
+			}
+			// Read one more byte than allowed to detect documents that are too large
+			data, err := io.ReadAll(io.LimitReader(resp.Body, policy.MaxDocumentBytes+1))
+			if err != nil {
+				return nil, err
+			}
+			if err := policy.checkSize(location, int64(len(data))); err != nil {
+				return nil, err
+			}
+			return data, nil
+		}
 		return io.ReadAll(resp.Body)
 	}
 }
@@ -75,11 +97,24 @@ func is_file(location *url.URL) bool {
 }
 
 // ReadFromFile is a ReadFromURIFunc which reads local file URIs.
-func ReadFromFile(loader *Loader, location *url.URL) ([]byte, error) {
+func ReadFromFile(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
 	if !is_file(location) {
 		return nil, ErrURINotSupported
 	}
-	return os.ReadFile(filepath.FromSlash(location.Path))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path := filepath.FromSlash(location.Path)
+	if policy := loader.refPolicy(); policy != nil && policy.MaxDocumentBytes > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := policy.checkSize(location, info.Size()); err != nil {
+			return nil, err
+		}
+	}
+	return os.ReadFile(path)
 }
 
 // URIMapCache returns a ReadFromURIFunc that caches the contents read from URI
@@ -88,13 +123,13 @@ func ReadFromFile(loader *Loader, location *url.URL) ([]byte, error) {
 // documents.
 func URIMapCache(reader ReadFromURIFunc) ReadFromURIFunc {
 	cache := map[string][]byte{}
-	return func(loader *Loader, location *url.URL) (buf []byte, err error) {
+	return func(ctx context.Context, loader *Loader, location *url.URL) (buf []byte, err error) {
 		if location.Scheme == "" || location.Scheme == "file" {
 			if !filepath.IsAbs(location.Path) {
 				// Do not cache relative file paths; this can cause trouble if
 				// the current working directory changes when processing
 				// multiple top-level documents.
-				return reader(loader, location)
+				return reader(ctx, loader, location)
 			}
 		}
 		uri := location.String()
@@ -105,7 +140,7 @@ func URIMapCache(reader ReadFromURIFunc) ReadFromURIFunc {
 			return
 		}
 		uriMu.RUnlock()
-		if buf, err = reader(loader, location); err != nil {
+		if buf, err = reader(ctx, loader, location); err != nil {
 			return
 		}
 		uriMu.Lock()
@@ -0,0 +1,130 @@
+package openapi3
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// URIRewrite reads the documents whose URI starts with Prefix from a local
+// directory or a file system, for instance to resolve canonical URLs
+// from vendored copies.
+// Documents keep their original URI, so that their relative references
+// resolve against it and get rewritten in turn.
+type URIRewrite struct {
+	// Prefix is matched against document URIs, such as "https://schemas.example.com/common/",
+	// up to a path segment boundary: "https://schemas.example.com/common" does not
+	// match "https://schemas.example.com/common-v2/pet.json".
+	Prefix string
+
+	// Dir is the local directory the rest of the URI is a path in.
+	Dir string
+
+	// FS is the file system the rest of the URI is a path in, when Dir is empty.
+	FS fs.FS
+}
+
+func (rewrite *URIRewrite) fs() fs.FS {
+	if rewrite.Dir != "" {
+		return os.DirFS(rewrite.Dir)
+	}
+	return rewrite.FS
+}
+
+// rewrite returns the rule matching the longest prefix of location
+// and the path to read in its file system.
+func (loader *Loader) rewrite(location *url.URL) (*URIRewrite, string, error) {
+	uri := location.String()
+	var matched *URIRewrite
+	for i := range loader.URIRewrites {
+		rewrite := &loader.URIRewrites[i]
+		if hasURIPrefix(uri, rewrite.Prefix) && (matched == nil || len(rewrite.Prefix) > len(matched.Prefix)) {
+			matched = rewrite
+		}
+	}
+	if matched == nil {
+		return nil, "", nil
+	}
+
+	rest, err := url.PathUnescape(strings.TrimPrefix(uri, matched.Prefix))
+	if err != nil {
+		return nil, "", err
+	}
+	name, err := fsPath(rest)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot rewrite %q with prefix %q: %w", uri, matched.Prefix, err)
+	}
+	return matched, name, nil
+}
+
+// hasURIPrefix tells whether uri starts with prefix, ending at a path segment boundary.
+func hasURIPrefix(uri, prefix string) bool {
+	if !strings.HasPrefix(uri, prefix) {
+		return false
+	}
+	if len(uri) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	switch uri[len(prefix)] {
+	case '/', '?', '#':
+		return true
+	}
+	return false
+}
+
+// fsPath returns the fs.FS path of a slash-separated path.
+func fsPath(p string) (string, error) {
+	name := path.Clean(strings.TrimPrefix(p, "/"))
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("invalid path %q", p)
+	}
+	return name, nil
+}
+
+// ReadFromFS returns a ReadFromURIFunc which reads local file URIs from fsys,
+// absolute paths being relative to its root.
+func ReadFromFS(fsys fs.FS) ReadFromURIFunc {
+	return func(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
+		if !is_file(location) {
+			return nil, ErrURINotSupported
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name, err := fsPath(location.Path)
+		if err != nil {
+			return nil, err
+		}
+		return fs.ReadFile(fsys, name)
+	}
+}
+
+// reader returns the ReadFromURIFunc reading the document at location.
+func (loader *Loader) reader(location *url.URL) (ReadFromURIFunc, error) {
+	rewrite, name, err := loader.rewrite(location)
+	if err != nil {
+		return nil, err
+	}
+	if rewrite != nil {
+		fsys := rewrite.fs()
+		return func(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return fs.ReadFile(fsys, name)
+		}, nil
+	}
+
+	read := DefaultReadFromURI
+	if f := loader.ReadFromURIFunc; f != nil {
+		read = f
+	}
+	if loader.FS != nil {
+		read = ReadFromURIs(ReadFromFS(loader.FS), read)
+	}
+	return read, nil
+}
@@ -0,0 +1,122 @@
+package openapi3
+
+import (
+	"net/url"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const uriRewritesSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Error:
+      $ref: 'https://schemas.example.com/common/v1/error.yaml#/components/schemas/Error'
+`
+
+func TestLoaderURIRewrites(t *testing.T) {
+	for name, rewrite := range map[string]URIRewrite{
+		"directory":   {Prefix: "https://schemas.example.com/common/", Dir: "testdata/uriRewrites/common"},
+		"file system": {Prefix: "https://schemas.example.com/", FS: os.DirFS("testdata/uriRewrites")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			loader := NewLoader()
+			loader.IsExternalRefsAllowed = true
+			loader.URIRewrites = []URIRewrite{
+				{Prefix: "https://schemas.example.com/common/v1/missing/", Dir: "testdata/missing"},
+				rewrite,
+			}
+			// Rewritten documents are local
+			loader.RefPolicy = &RefPolicy{AllowedSchemes: []string{"file"}, BlockPrivateNetworks: true}
+
+			doc, err := loader.LoadFromData([]byte(uriRewritesSpec))
+			require.NoError(t, err)
+			err = doc.Validate(loader.Context)
+			require.NoError(t, err)
+
+			errorSchema := doc.Components.Schemas["Error"]
+			require.Equal(t, "https://schemas.example.com/common/v1/error.yaml#/components/schemas/Error", errorSchema.RefPath().String())
+			// Relative references of rewritten documents resolve against their original URI
+			code := errorSchema.Value.Properties["code"]
+			require.Equal(t, "https://schemas.example.com/common/v1/types.yaml#/components/schemas/Code", code.RefPath().String())
+			require.Equal(t, float64(100), *code.Value.Min)
+		})
+	}
+}
+
+func TestLoaderURIRewritesTraversal(t *testing.T) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.URIRewrites = []URIRewrite{{Prefix: "https://schemas.example.com/common/", Dir: "testdata/uriRewrites/common"}}
+	_, err := loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Error:
+      $ref: 'https://schemas.example.com/common/%2E%2E/%2E%2E/positions/components.yml#/components/schemas/Pet'
+`))
+	require.ErrorContains(t, err, `cannot rewrite "https://schemas.example.com/common/%2E%2E/%2E%2E/positions/components.yml" with prefix "https://schemas.example.com/common/": invalid path "../../positions/components.yml"`)
+}
+
+func TestLoaderURIRewritesSegmentBoundary(t *testing.T) {
+	loader := NewLoader()
+	loader.URIRewrites = []URIRewrite{{Prefix: "https://a.com/api", Dir: "testdata/uriRewrites"}}
+	for uri, name := range map[string]string{
+		"https://a.com/api/common/v1/error.yaml": "common/v1/error.yaml",
+		"https://a.com/api":                      ".",
+		"https://a.com/api-v2/error.yaml":        "",
+		"https://a.com/apis/error.yaml":          "",
+	} {
+		location, err := url.Parse(uri)
+		require.NoError(t, err)
+		rewrite, path, err := loader.rewrite(location)
+		require.NoError(t, err)
+		if name == "" {
+			require.Nil(t, rewrite, uri)
+		} else {
+			require.NotNil(t, rewrite, uri)
+			require.Equal(t, name, path, uri)
+		}
+	}
+}
+
+func TestLoaderFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"specs/openapi.yml": {Data: []byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pet: {$ref: 'schemas/pet.yml'}
+`)},
+		"specs/schemas/pet.yml": {Data: []byte(`
+type: object
+properties:
+  owner: {$ref: '../people/owner.yml'}
+`)},
+		"specs/people/owner.yml": {Data: []byte(`{type: string}`)},
+	}
+
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.FS = fsys
+	doc, err := loader.LoadFromFile("specs/openapi.yml")
+	require.NoError(t, err)
+	owner := doc.Components.Schemas["Pet"].Value.Properties["owner"]
+	require.Equal(t, &Types{"string"}, owner.Value.Type)
+
+	// Files outside of the file system are not read
+	doc, err = loader.LoadFromURI(&url.URL{Path: "/specs/openapi.yml"})
+	require.NoError(t, err)
+	require.NotNil(t, doc.Components.Schemas["Pet"].Value)
+	_, err = loader.LoadFromFile("testdata/positions/openapi.yml")
+	require.ErrorContains(t, err, "file does not exist")
+}
@@ -0,0 +1,288 @@
+package openapi3
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lookup returns the value at pointer, a JSON pointer within the document,
+// as the type holding it in the document:
+//
+//	v, err := doc.Lookup("/paths/~1pets/get")
+//	...
+//	operation := v.(*Operation)
+//
+// Objects are returned as pointers, such as *Operation or *ResponseRef,
+// and references as their reference type, such as *SchemaRef.
+// Lookups go through the values of references, except for "$ref" tokens
+// which return the references themselves. Other values, such as strings,
+// are returned as is. The empty pointer returns the document.
+func (doc *T) Lookup(pointer string) (any, error) {
+	pointer = strings.TrimPrefix(pointer, "#")
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: it must start with a slash", pointer)
+	}
+
+	v := reflect.ValueOf(doc)
+	if pointer == "" {
+		return doc, nil
+	}
+	for i, token := range strings.Split(pointer[1:], "/") {
+		next, ok := lookupToken(v, unescapeRefString(token))
+		if ok {
+			switch next.Kind() {
+			case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+				ok = !next.IsNil()
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("no value at %q", "/"+strings.Join(strings.Split(pointer[1:], "/")[:i+1], "/"))
+		}
+		v = next
+	}
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		v = v.Addr()
+	}
+	return v.Interface(), nil
+}
+
+// lookupToken returns the value at token within v.
+func lookupToken(v reflect.Value, token string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		if isRefType(v.Type().Elem()) {
+			if token == "$ref" {
+				ref := v.Elem().FieldByName("Ref")
+				return ref, ref.String() != ""
+			}
+			if value, ok := lookupExtension(v.Elem(), token); ok {
+				return value, true
+			}
+			return lookupToken(v.Elem().FieldByName("Value"), token)
+		}
+		switch x := v.Interface().(type) {
+		case *Paths:
+			return lookupMapLike(x.Map(), x.Extensions, token)
+		case *Responses:
+			return lookupMapLike(x.Map(), x.Extensions, token)
+		case *Callback:
+			return lookupMapLike(x.Map(), x.Extensions, token)
+		}
+		return lookupToken(v.Elem(), token)
+
+	case reflect.Struct:
+		if field, ok := lookupField(v, token); ok {
+			if additional, ok := field.Interface().(AdditionalProperties); ok {
+				// Either a schema or a boolean
+				if additional.Schema != nil {
+					return reflect.ValueOf(additional.Schema), true
+				}
+				if additional.Has != nil {
+					return reflect.ValueOf(*additional.Has), true
+				}
+				return reflect.Value{}, false
+			}
+			return field, true
+		}
+		return lookupExtension(v, token)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		value := v.MapIndex(reflect.ValueOf(token).Convert(v.Type().Key()))
+		return value, value.IsValid()
+
+	case reflect.Slice:
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i >= v.Len() || token != strconv.Itoa(i) {
+			return reflect.Value{}, false
+		}
+		return v.Index(i), true
+	}
+	return reflect.Value{}, false
+}
+
+// lookupField returns the field of the struct v named token in JSON.
+func lookupField(v reflect.Value, token string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		switch name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name {
+		case "-":
+		case "":
+			if field.Anonymous {
+				if value, ok := lookupField(v.Field(i), token); ok {
+					return value, true
+				}
+			}
+		case token:
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func lookupExtension(v reflect.Value, token string) (reflect.Value, bool) {
+	extensions := v.FieldByName("Extensions")
+	if !extensions.IsValid() || extensions.IsNil() {
+		return reflect.Value{}, false
+	}
+	value := extensions.MapIndex(reflect.ValueOf(token))
+	return value, value.IsValid()
+}
+
+func lookupMapLike[V any](m map[string]V, extensions map[string]any, token string) (reflect.Value, bool) {
+	if value, ok := m[token]; ok {
+		return reflect.ValueOf(value), true
+	}
+	if value, ok := extensions[token]; ok {
+		return reflect.ValueOf(value), true
+	}
+	return reflect.Value{}, false
+}
+
+// PointerIndex maps the objects of a document to their JSON pointer.
+type PointerIndex struct {
+	pointers map[pointerIndexKey]string
+	// refs are the references whose value is yet to be indexed.
+	refs []pendingRef
+}
+
+type pointerIndexKey struct {
+	t reflect.Type
+	p uintptr
+}
+
+type pendingRef struct {
+	pointer string
+	value   reflect.Value
+}
+
+// NewPointerIndex indexes the objects of doc, such as *Operation, *SchemaRef or *Schema,
+// by their JSON pointer.
+//
+// The pointer of an object is where it is defined in the document, such as
+// "/components/schemas/Pet" for a schema referred to as "#/components/schemas/Pet",
+// and the first one in document order if it is defined in several places.
+// Objects only defined in other documents get the pointer of the first
+// reference to them. References and the value they hold share their pointer.
+// The index does not follow later changes of the document.
+func NewPointerIndex(doc *T) *PointerIndex {
+	index := &PointerIndex{pointers: make(map[pointerIndexKey]string)}
+	index.walk("", reflect.ValueOf(doc))
+	for len(index.refs) != 0 {
+		ref := index.refs[0]
+		index.refs = index.refs[1:]
+		index.walk(ref.pointer, ref.value)
+	}
+	return index
+}
+
+// Pointer returns the JSON pointer of node, a pointer to an object of the document,
+// and whether the document holds it.
+func (index *PointerIndex) Pointer(node any) (string, bool) {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return "", false
+	}
+	pointer, ok := index.pointers[pointerIndexKey{t: v.Type(), p: v.Pointer()}]
+	return pointer, ok
+}
+
+func (index *PointerIndex) walk(pointer string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		key := pointerIndexKey{t: v.Type(), p: v.Pointer()}
+		if _, ok := index.pointers[key]; ok {
+			return
+		}
+		index.pointers[key] = pointer
+
+		if isRefType(v.Type().Elem()) {
+			if ref := v.Elem().FieldByName("Ref"); ref.String() != "" {
+				// Index the values of references once those defined in the document are
+				index.refs = append(index.refs, pendingRef{pointer: pointer, value: v.Elem().FieldByName("Value")})
+				return
+			}
+			index.walk(pointer, v.Elem().FieldByName("Value"))
+			return
+		}
+		switch x := v.Interface().(type) {
+		case *Paths:
+			index.walk(pointer, reflect.ValueOf(x.Map()))
+			return
+		case *Responses:
+			index.walk(pointer, reflect.ValueOf(x.Map()))
+			return
+		case *Callback:
+			index.walk(pointer, reflect.ValueOf(x.Map()))
+			return
+		}
+		index.walk(pointer, v.Elem())
+
+	case reflect.Struct:
+		if additional, ok := v.Interface().(AdditionalProperties); ok {
+			index.walk(pointer, reflect.ValueOf(additional.Schema))
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			switch name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name {
+			case "-":
+			case "":
+				if field.Anonymous {
+					index.walk(pointer, v.Field(i))
+				}
+			default:
+				index.walk(pointer+"/"+escapeRefString(name), v.Field(i))
+			}
+		}
+
+	case reflect.Map:
+		// Values of any type hold raw data, such as extensions and examples
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() == reflect.Interface {
+			return
+		}
+		for _, key := range sortedMapKeys(v) {
+			index.walk(pointer+"/"+escapeRefString(key), v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			index.walk(pointer+"/"+strconv.Itoa(i), v.Index(i))
+		}
+	}
+}
+
+func sortedMapKeys(v reflect.Value) []string {
+	keys := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,138 @@
+package openapi3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const lookupSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+      - {name: limit, in: query, schema: {type: integer}}
+      responses:
+        '200':
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+        default: {$ref: '#/components/responses/Error'}
+components:
+  responses:
+    Error:
+      description: an error
+      headers:
+        X-Rate-Limit: {schema: {type: integer}}
+  schemas:
+    Pet:
+      type: object
+      additionalProperties: {type: string}
+      properties:
+        name: {type: string}
+        tags: {type: array, items: {type: string}}
+      x-internal: true
+`
+
+func TestLookup(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(lookupSpec))
+	require.NoError(t, err)
+
+	v, err := doc.Lookup("/paths/~1pets/get")
+	require.NoError(t, err)
+	require.Same(t, doc.Paths.Value("/pets").Get, v.(*Operation))
+
+	v, err = doc.Lookup("/paths/~1pets/get/responses/200")
+	require.NoError(t, err)
+	require.Same(t, doc.Paths.Value("/pets").Get.Responses.Status(200), v.(*ResponseRef))
+
+	v, err = doc.Lookup("#/paths/~1pets/get/responses/200/content/application~1json/schema/items")
+	require.NoError(t, err)
+	require.Same(t, doc.Components.Schemas["Pet"].Value, v.(*SchemaRef).Value)
+
+	// Lookups go through references
+	v, err = doc.Lookup("/paths/~1pets/get/responses/200/content/application~1json/schema/items/properties/name/type")
+	require.NoError(t, err)
+	require.Equal(t, &Types{"string"}, v)
+	v, err = doc.Lookup("/paths/~1pets/get/responses/default/$ref")
+	require.NoError(t, err)
+	require.Equal(t, "#/components/responses/Error", v)
+	v, err = doc.Lookup("/paths/~1pets/get/responses/default/headers/X-Rate-Limit")
+	require.NoError(t, err)
+	require.IsType(t, &HeaderRef{}, v)
+	v, err = doc.Lookup("/paths/~1pets/get/responses/default/headers/X-Rate-Limit/schema")
+	require.NoError(t, err)
+	require.IsType(t, &SchemaRef{}, v)
+
+	v, err = doc.Lookup("/paths/~1pets/get/parameters/0/name")
+	require.NoError(t, err)
+	require.Equal(t, "limit", v)
+	v, err = doc.Lookup("/paths/~1pets/get/operationId")
+	require.NoError(t, err)
+	require.Equal(t, "listPets", v)
+	v, err = doc.Lookup("/components/schemas/Pet/additionalProperties/type")
+	require.NoError(t, err)
+	require.Equal(t, &Types{"string"}, v)
+	v, err = doc.Lookup("/components/schemas/Pet/x-internal")
+	require.NoError(t, err)
+	require.Equal(t, true, v)
+	v, err = doc.Lookup("/info")
+	require.NoError(t, err)
+	require.Same(t, doc.Info, v)
+	v, err = doc.Lookup("")
+	require.NoError(t, err)
+	require.Same(t, doc, v)
+
+	_, err = doc.Lookup("/paths/~1pets/post/responses")
+	require.EqualError(t, err, `no value at "/paths/~1pets/post"`)
+	_, err = doc.Lookup("/paths/~1pets/get/parameters/01")
+	require.EqualError(t, err, `no value at "/paths/~1pets/get/parameters/01"`)
+	_, err = doc.Lookup("paths")
+	require.EqualError(t, err, `invalid JSON pointer "paths": it must start with a slash`)
+}
+
+func TestPointerIndex(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(lookupSpec))
+	require.NoError(t, err)
+	index := NewPointerIndex(doc)
+
+	for _, pointer := range []string{
+		"/paths/~1pets",
+		"/paths/~1pets/get",
+		"/paths/~1pets/get/parameters/0",
+		"/paths/~1pets/get/responses/200",
+		"/paths/~1pets/get/responses/200/content/application~1json",
+		"/components/responses/Error/headers/X-Rate-Limit",
+		"/components/schemas/Pet/properties/tags/items",
+		"/components/schemas/Pet/additionalProperties",
+	} {
+		v, err := doc.Lookup(pointer)
+		require.NoError(t, err)
+		got, ok := index.Pointer(v)
+		require.True(t, ok, pointer)
+		require.Equal(t, pointer, got)
+	}
+
+	// Values are indexed where they are defined
+	pet := doc.Paths.Value("/pets").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Value.Items
+	got, ok := index.Pointer(pet)
+	require.True(t, ok)
+	require.Equal(t, "/paths/~1pets/get/responses/200/content/application~1json/schema/items", got)
+	got, ok = index.Pointer(pet.Value)
+	require.True(t, ok)
+	require.Equal(t, "/components/schemas/Pet", got)
+	got, ok = index.Pointer(doc.Paths.Value("/pets").Get.Responses.Default().Value)
+	require.True(t, ok)
+	require.Equal(t, "/components/responses/Error", got)
+
+	_, ok = index.Pointer(&Schema{})
+	require.False(t, ok)
+	_, ok = index.Pointer(nil)
+	require.False(t, ok)
+}
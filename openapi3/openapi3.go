@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/go-openapi/jsonpointer"
 )
@@ -18,12 +19,16 @@ type T struct {
 	OpenAPI      string               `json:"openapi" yaml:"openapi"` // Required
 	Components   *Components          `json:"components,omitempty" yaml:"components,omitempty"`
 	Info         *Info                `json:"info" yaml:"info"`   // Required
-	Paths        *Paths               `json:"paths" yaml:"paths"` // Required
+	Paths        *Paths               `json:"paths" yaml:"paths"` // Required in OpenAPI 3.0
 	Security     SecurityRequirements `json:"security,omitempty" yaml:"security,omitempty"`
 	Servers      Servers              `json:"servers,omitempty" yaml:"servers,omitempty"`
 	Tags         Tags                 `json:"tags,omitempty" yaml:"tags,omitempty"`
 	ExternalDocs *ExternalDocs        `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
 
+	// OpenAPI 3.1
+	Webhooks          PathItems `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+	JSONSchemaDialect string    `json:"jsonSchemaDialect,omitempty" yaml:"jsonSchemaDialect,omitempty"`
+
 	visited visitedComponent
 	url     *url.URL
 }
@@ -49,6 +54,10 @@ func (doc *T) JSONLookup(token string) (any, error) {
 		return doc.Tags, nil
 	case "externalDocs":
 		return doc.ExternalDocs, nil
+	case "webhooks":
+		return doc.Webhooks, nil
+	case "jsonSchemaDialect":
+		return doc.JSONSchemaDialect, nil
 	}
 
 	v, _, err := jsonpointer.GetForToken(doc.Extensions, token)
@@ -78,7 +87,9 @@ func (doc *T) MarshalYAML() (any, error) {
 		m["components"] = x
 	}
 	m["info"] = doc.Info
-	m["paths"] = doc.Paths
+	if x := doc.Paths; x != nil || !doc.IsOpenAPI3_1() {
+		m["paths"] = x
+	}
 	if x := doc.Security; len(x) != 0 {
 		m["security"] = x
 	}
@@ -91,6 +102,12 @@ func (doc *T) MarshalYAML() (any, error) {
 	if x := doc.ExternalDocs; x != nil {
 		m["externalDocs"] = x
 	}
+	if x := doc.Webhooks; len(x) != 0 {
+		m["webhooks"] = x
+	}
+	if x := doc.JSONSchemaDialect; x != "" {
+		m["jsonSchemaDialect"] = x
+	}
 	return m, nil
 }
 
@@ -110,6 +127,8 @@ func (doc *T) UnmarshalJSON(data []byte) error {
 	delete(x.Extensions, "servers")
 	delete(x.Extensions, "tags")
 	delete(x.Extensions, "externalDocs")
+	delete(x.Extensions, "webhooks")
+	delete(x.Extensions, "jsonSchemaDialect")
 	if len(x.Extensions) == 0 {
 		x.Extensions = nil
 	}
@@ -117,6 +136,15 @@ func (doc *T) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// IsOpenAPI3_1 returns whether the document declares an OpenAPI 3.1.x version.
+func (doc *T) IsOpenAPI3_1() bool {
+	return isOpenAPI3_1Version(doc.OpenAPI)
+}
+
+func isOpenAPI3_1Version(version string) bool {
+	return version == "3.1" || strings.HasPrefix(version, "3.1.")
+}
+
 func (doc *T) AddOperation(path string, method string, operation *Operation) {
 	if doc.Paths == nil {
 		doc.Paths = NewPaths()
@@ -146,6 +174,15 @@ func (doc *T) Validate(ctx context.Context, opts ...ValidationOption) error {
 		return errors.New("value of openapi must be a non-empty string")
 	}
 
+	ctx = withOpenAPIVersion(ctx, doc.OpenAPI)
+
+	isOpenAPI3_1 := doc.IsOpenAPI3_1()
+	if !isOpenAPI3_1 {
+		if err := doc.validateNoOpenAPI3_1Fields(); err != nil {
+			return err
+		}
+	}
+
 	var wrap func(error) error
 
 	wrap = func(e error) error { return fmt.Errorf("invalid components: %w", e) }
@@ -169,8 +206,23 @@ func (doc *T) Validate(ctx context.Context, opts ...ValidationOption) error {
 		if err := v.Validate(ctx); err != nil {
 			return wrap(err)
 		}
-	} else {
+	} else if !isOpenAPI3_1 {
 		return wrap(errors.New("must be an object"))
+	} else if doc.Components == nil && len(doc.Webhooks) == 0 {
+		return errors.New("at least one of paths, components or webhooks must be present")
+	}
+
+	if v := doc.JSONSchemaDialect; v != "" {
+		if u, err := url.Parse(v); err != nil || !u.IsAbs() {
+			return fmt.Errorf("invalid jsonSchemaDialect: %q is not an absolute URI", v)
+		}
+	}
+
+	wrap = func(e error) error { return fmt.Errorf("invalid webhooks: %w", e) }
+	if v := doc.Webhooks; v != nil {
+		if err := v.Validate(ctx); err != nil {
+			return wrap(err)
+		}
 	}
 
 	wrap = func(e error) error { return fmt.Errorf("invalid security: %w", e) }
@@ -203,3 +255,29 @@ func (doc *T) Validate(ctx context.Context, opts ...ValidationOption) error {
 
 	return validateExtensions(ctx, doc.Extensions)
 }
+
+// validateNoOpenAPI3_1Fields returns an error if an OpenAPI 3.0 document uses fields
+// introduced with OpenAPI 3.1.
+func (doc *T) validateNoOpenAPI3_1Fields() error {
+	requires3_1 := func(field string) error {
+		return fmt.Errorf("%s requires openapi 3.1 (got %q)", field, doc.OpenAPI)
+	}
+	if len(doc.Webhooks) != 0 {
+		return requires3_1("webhooks")
+	}
+	if doc.JSONSchemaDialect != "" {
+		return requires3_1("jsonSchemaDialect")
+	}
+	if info := doc.Info; info != nil {
+		if info.Summary != "" {
+			return requires3_1("info.summary")
+		}
+		if license := info.License; license != nil && license.Identifier != "" {
+			return requires3_1("info.license.identifier")
+		}
+	}
+	if components := doc.Components; components != nil && len(components.PathItems) != 0 {
+		return requires3_1("components.pathItems")
+	}
+	return nil
+}
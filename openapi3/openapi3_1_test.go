@@ -0,0 +1,278 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI3_1Document(t *testing.T) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile("testdata/webhooks/openapi.yml")
+	require.NoError(t, err)
+	require.True(t, doc.IsOpenAPI3_1())
+
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	require.Nil(t, doc.Paths)
+	require.Equal(t, "Emits events to subscribers", doc.Info.Summary)
+	require.Equal(t, "MIT", doc.Info.License.Identifier)
+	require.Equal(t, "https://spec.openapis.org/oas/3.1/dialect/base", doc.JSONSchemaDialect)
+
+	require.Len(t, doc.Webhooks, 2)
+	newPet := doc.Webhooks["newPet"]
+	require.Equal(t, "#/components/pathItems/NewPet", newPet.Ref)
+	require.NotNil(t, newPet.Post)
+	require.Equal(t, doc.Components.Schemas["Pet"].Value, newPet.Post.RequestBody.Value.Content.Get("application/json").Schema.Value)
+
+	petRemoved := doc.Webhooks["petRemoved"]
+	require.Equal(t, "./pathItems.yml", petRemoved.Ref)
+	schema := petRemoved.Post.RequestBody.Value.Content.Get("application/json").Schema
+	require.Equal(t, "./schemas.yml#/RemovedPet", schema.Ref)
+	require.NotNil(t, schema.Value)
+	require.Contains(t, schema.Value.Properties, "removedAt")
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var raw map[string]any
+	err = json.Unmarshal(data, &raw)
+	require.NoError(t, err)
+	require.NotContains(t, raw, "paths")
+	require.Contains(t, raw, "webhooks")
+	require.Contains(t, raw, "jsonSchemaDialect")
+
+	v, err := doc.JSONLookup("webhooks")
+	require.NoError(t, err)
+	require.Equal(t, doc.Webhooks, v)
+}
+
+func TestOpenAPI3_1FieldsRequireVersion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{
+			name: "webhooks in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+paths: {}
+webhooks:
+  hook:
+    post:
+      responses: {"200": {description: ok}}
+`,
+			err: `webhooks requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "info summary in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v, summary: s}
+paths: {}
+`,
+			err: `info.summary requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "license identifier in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v, license: {name: MIT, identifier: MIT}}
+paths: {}
+`,
+			err: `info.license.identifier requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "missing paths in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+`,
+			err: `invalid paths: must be an object`,
+		},
+		{
+			name: "missing paths, components and webhooks in 3.1",
+			spec: `
+openapi: 3.1.0
+info: {title: t, version: v}
+`,
+			err: `at least one of paths, components or webhooks must be present`,
+		},
+		{
+			name: "only components in 3.1",
+			spec: `
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Nullable: {type: [string, "null"]}
+`,
+		},
+		{
+			name: "null type in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+paths: {}
+components:
+  schemas:
+    Nullable: {type: "null"}
+`,
+			err: `invalid components: schema "Nullable": unsupported 'type' value "null"`,
+		},
+		{
+			name: "numeric exclusiveMinimum in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+paths: {}
+components:
+  schemas:
+    Positive: {type: number, exclusiveMinimum: 0}
+`,
+			err: `invalid components: schema "Positive": numeric exclusiveMinimum requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "prefixItems in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+paths: {}
+components:
+  schemas:
+    Pair: {type: array, items: {}, prefixItems: [{type: string}]}
+`,
+			err: `invalid components: schema "Pair": prefixItems requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "if in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+paths: {}
+components:
+  schemas:
+    Conditional: {if: {type: string}, then: {minLength: 1}}
+`,
+			err: `invalid components: schema "Conditional": if requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "const in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+paths: {}
+components:
+  schemas:
+    Constant: {const: a}
+`,
+			err: `invalid components: schema "Constant": const requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "boolean schema in 3.0",
+			spec: `
+openapi: 3.0.3
+info: {title: t, version: v}
+paths: {}
+components:
+  schemas:
+    Object: {type: object, properties: {anything: true}}
+`,
+			err: `invalid components: schema "Object": boolean schema requires openapi 3.1 (got "3.0.3")`,
+		},
+		{
+			name: "null path item component",
+			spec: `
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  pathItems:
+    Empty: null
+`,
+			err: `invalid components: path item "Empty": invalid path item: value MUST be an object`,
+		},
+		{
+			name: "license url and identifier",
+			spec: `
+openapi: 3.1.0
+info: {title: t, version: v, license: {name: MIT, identifier: MIT, url: "https://opensource.org/licenses/MIT"}}
+paths: {}
+`,
+			err: `invalid info: license url and identifier are mutually exclusive`,
+		},
+		{
+			name: "relative jsonSchemaDialect",
+			spec: `
+openapi: 3.1.0
+info: {title: t, version: v}
+jsonSchemaDialect: dialect
+paths: {}
+`,
+			err: `invalid jsonSchemaDialect: "dialect" is not an absolute URI`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := NewLoader()
+			doc, err := loader.LoadFromData([]byte(tt.spec))
+			require.NoError(t, err)
+
+			err = doc.Validate(ctx)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestExclusiveBound(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Numeric:
+      type: number
+      exclusiveMinimum: 0
+      exclusiveMaximum: 10
+    Boolean:
+      type: number
+      minimum: 0
+      exclusiveMinimum: true
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.EqualError(t, err, `invalid components: schema "Boolean": boolean exclusiveMinimum requires openapi 3.0 (got "3.1.0")`)
+
+	numeric := doc.Components.Schemas["Numeric"].Value
+	require.Equal(t, Float64Ptr(0), numeric.ExclusiveMin.Value)
+	require.False(t, numeric.ExclusiveMin.IsTrue)
+	require.NoError(t, numeric.VisitJSON(5.0))
+	require.ErrorContains(t, numeric.VisitJSON(0.0), "number must be more than 0")
+	require.ErrorContains(t, numeric.VisitJSON(10.0), "number must be less than 10")
+
+	boolean := doc.Components.Schemas["Boolean"].Value
+	require.True(t, boolean.ExclusiveMin.IsTrue)
+	require.Nil(t, boolean.ExclusiveMin.Value)
+	require.ErrorContains(t, boolean.VisitJSON(0.0), "number must be more than 0")
+
+	data, err := json.Marshal(numeric)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"number","exclusiveMinimum":0,"exclusiveMaximum":10}`, string(data))
+
+	data, err = json.Marshal(boolean)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"number","minimum":0,"exclusiveMinimum":true}`, string(data))
+}
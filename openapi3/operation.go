@@ -48,6 +48,8 @@ type Operation struct {
 	Servers *Servers `json:"servers,omitempty" yaml:"servers,omitempty"`
 
 	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+
+	position *Position
 }
 
 var _ jsonpointer.JSONPointable = (*Operation)(nil)
@@ -189,8 +191,9 @@ func (operation *Operation) AddResponse(status int, response *Response) {
 }
 
 // Validate returns an error if Operation does not comply with the OpenAPI spec.
-func (operation *Operation) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (operation *Operation) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, operation.position) }()
 
 	if v := operation.Parameters; v != nil {
 		if err := v.Validate(ctx); err != nil {
@@ -87,6 +87,8 @@ type Parameter struct {
 	Example         any        `json:"example,omitempty" yaml:"example,omitempty"`
 	Examples        Examples   `json:"examples,omitempty" yaml:"examples,omitempty"`
 	Content         Content    `json:"content,omitempty" yaml:"content,omitempty"`
+
+	position *Position
 }
 
 var _ jsonpointer.JSONPointable = (*Parameter)(nil)
@@ -308,8 +310,9 @@ func (parameter *Parameter) SerializationMethod() (*SerializationMethod, error)
 }
 
 // Validate returns an error if Parameter does not comply with the OpenAPI spec.
-func (parameter *Parameter) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (parameter *Parameter) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, parameter.position) }()
 
 	if parameter.Name == "" {
 		return errors.New("parameter name can't be blank")
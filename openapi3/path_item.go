@@ -27,6 +27,8 @@ type PathItem struct {
 	Trace       *Operation `json:"trace,omitempty" yaml:"trace,omitempty"`
 	Servers     Servers    `json:"servers,omitempty" yaml:"servers,omitempty"`
 	Parameters  Parameters `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+
+	position *Position
 }
 
 // MarshalJSON returns the JSON encoding of PathItem.
@@ -202,8 +204,9 @@ func (pathItem *PathItem) SetOperation(method string, operation *Operation) {
 }
 
 // Validate returns an error if PathItem does not comply with the OpenAPI spec.
-func (pathItem *PathItem) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (pathItem *PathItem) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, pathItem.position) }()
 
 	operations := pathItem.Operations()
 
@@ -0,0 +1,462 @@
+package openapi3
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Position is where a value was found in the source of a document.
+type Position struct {
+	// File is the location of the document, empty for documents loaded from memory.
+	File string
+	// Line and Column start at 1.
+	Line   int
+	Column int
+}
+
+// String returns the position as "file:line:column".
+func (position *Position) String() string {
+	s := strconv.Itoa(position.Line) + ":" + strconv.Itoa(position.Column)
+	if position.File != "" {
+		s = position.File + ":" + s
+	}
+	return s
+}
+
+// PositionError is an error about a value found at Position.
+type PositionError struct {
+	Position *Position
+	Err      error
+}
+
+var _ error = (*PositionError)(nil)
+
+func (err *PositionError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Position, err.Err)
+}
+
+func (err *PositionError) Unwrap() error {
+	return err.Err
+}
+
+// withPosition adds position to err unless err already carries a more precise one.
+func withPosition(err error, position *Position) error {
+	if err == nil || position == nil {
+		return err
+	}
+	var positionErr *PositionError
+	if errors.As(err, &positionErr) {
+		return err
+	}
+	return &PositionError{Position: position, Err: err}
+}
+
+// Position returns where the schema was defined.
+// It is nil unless the schema was loaded with Loader.IncludePositions.
+func (schema *Schema) Position() *Position {
+	if schema == nil {
+		return nil
+	}
+	return schema.position
+}
+
+// Position returns where the parameter was defined.
+// It is nil unless the parameter was loaded with Loader.IncludePositions.
+func (parameter *Parameter) Position() *Position {
+	if parameter == nil {
+		return nil
+	}
+	return parameter.position
+}
+
+// Position returns where the operation was defined.
+// It is nil unless the operation was loaded with Loader.IncludePositions.
+func (operation *Operation) Position() *Position {
+	if operation == nil {
+		return nil
+	}
+	return operation.position
+}
+
+// Position returns where the path item was defined.
+// It is nil unless the path item was loaded with Loader.IncludePositions.
+func (pathItem *PathItem) Position() *Position {
+	if pathItem == nil {
+		return nil
+	}
+	return pathItem.position
+}
+
+// Position returns where the request body was defined.
+// It is nil unless the request body was loaded with Loader.IncludePositions.
+func (requestBody *RequestBody) Position() *Position {
+	if requestBody == nil {
+		return nil
+	}
+	return requestBody.position
+}
+
+// Position returns where the response was defined.
+// It is nil unless the response was loaded with Loader.IncludePositions.
+func (response *Response) Position() *Position {
+	if response == nil {
+		return nil
+	}
+	return response.position
+}
+
+// Position returns where the security scheme was defined.
+// It is nil unless the security scheme was loaded with Loader.IncludePositions.
+func (ss *SecurityScheme) Position() *Position {
+	if ss == nil {
+		return nil
+	}
+	return ss.position
+}
+
+// Position returns where the example was defined.
+// It is nil unless the example was loaded with Loader.IncludePositions.
+func (example *Example) Position() *Position {
+	if example == nil {
+		return nil
+	}
+	return example.position
+}
+
+// Position returns where the link was defined.
+// It is nil unless the link was loaded with Loader.IncludePositions.
+func (link *Link) Position() *Position {
+	if link == nil {
+		return nil
+	}
+	return link.position
+}
+
+// Position returns where the callback was defined.
+// It is nil unless the callback was loaded with Loader.IncludePositions.
+func (callback *Callback) Position() *Position {
+	if callback == nil {
+		return nil
+	}
+	return callback.position
+}
+
+// recordPositions indexes the positions of data, read from location,
+// and records them on element and the values it holds.
+// Positions are indexed too for StrictDecoding to report them.
+func (loader *Loader) recordPositions(location *url.URL, data []byte, element any) {
+	if !loader.IncludePositions && !loader.StrictDecoding {
+		return
+	}
+	file := positionsKey(location)
+	if loader.positions == nil {
+		loader.positions = make(map[string]positions)
+	}
+	index := positions(unmarshalPositions(data, file))
+	loader.positions[file] = index
+	if loader.IncludePositions {
+		index.element("", element)
+	}
+}
+
+// recordPositionsAt records on element the positions of the value
+// found at pointer in the already indexed document at location.
+func (loader *Loader) recordPositionsAt(location *url.URL, pointer string, element any) {
+	if !loader.IncludePositions {
+		return
+	}
+	if index, ok := loader.positions[positionsKey(location)]; ok {
+		index.element(strings.TrimSuffix(pointer, "/"), element)
+	}
+}
+
+func positionsKey(location *url.URL) string {
+	if location == nil {
+		return ""
+	}
+	return location.String()
+}
+
+// positions maps JSON pointers to the positions of the values of a document.
+type positions map[string]*Position
+
+func (index positions) set(pointer string, position **Position) {
+	if *position == nil {
+		*position = index[pointer]
+	}
+}
+
+func (index positions) element(pointer string, element any) {
+	switch v := element.(type) {
+	case *T:
+		index.doc(v)
+	case *Schema:
+		index.schema(pointer, v)
+	case *SchemaRef:
+		index.schemaRef(pointer, v)
+	case *Parameter:
+		index.parameter(pointer, v)
+	case *ParameterRef:
+		index.parameterRef(pointer, v)
+	case *Header:
+		index.parameter(pointer, &v.Parameter)
+	case *HeaderRef:
+		index.headerRef(pointer, v)
+	case *RequestBody:
+		index.requestBody(pointer, v)
+	case *RequestBodyRef:
+		index.requestBodyRef(pointer, v)
+	case *Response:
+		index.response(pointer, v)
+	case *ResponseRef:
+		index.responseRef(pointer, v)
+	case *SecurityScheme:
+		index.set(pointer, &v.position)
+	case *SecuritySchemeRef:
+		if v.Ref == "" && v.Value != nil {
+			index.set(pointer, &v.Value.position)
+		}
+	case *Example:
+		index.set(pointer, &v.position)
+	case *ExampleRef:
+		index.exampleRef(pointer, v)
+	case *Link:
+		index.set(pointer, &v.position)
+	case *LinkRef:
+		index.linkRef(pointer, v)
+	case *Callback:
+		index.callback(pointer, v)
+	case *CallbackRef:
+		index.callbackRef(pointer, v)
+	case *PathItem:
+		index.pathItem(pointer, v)
+	case *Operation:
+		index.operation(pointer, v)
+	}
+}
+
+func (index positions) doc(doc *T) {
+	if components := doc.Components; components != nil {
+		index.components("/components", components)
+	}
+	if doc.Paths != nil {
+		for path, pathItem := range doc.Paths.Map() {
+			index.pathItem("/paths/"+escapeRefString(path), pathItem)
+		}
+	}
+	for name, pathItem := range doc.Webhooks {
+		index.pathItem("/webhooks/"+escapeRefString(name), pathItem)
+	}
+}
+
+func (index positions) components(pointer string, components *Components) {
+	for name, v := range components.Schemas {
+		index.schemaRef(pointer+"/schemas/"+escapeRefString(name), v)
+	}
+	for name, v := range components.Parameters {
+		index.parameterRef(pointer+"/parameters/"+escapeRefString(name), v)
+	}
+	for name, v := range components.Headers {
+		index.headerRef(pointer+"/headers/"+escapeRefString(name), v)
+	}
+	for name, v := range components.RequestBodies {
+		index.requestBodyRef(pointer+"/requestBodies/"+escapeRefString(name), v)
+	}
+	for name, v := range components.Responses {
+		index.responseRef(pointer+"/responses/"+escapeRefString(name), v)
+	}
+	for name, v := range components.SecuritySchemes {
+		index.element(pointer+"/securitySchemes/"+escapeRefString(name), v)
+	}
+	for name, v := range components.Examples {
+		index.exampleRef(pointer+"/examples/"+escapeRefString(name), v)
+	}
+	for name, v := range components.Links {
+		index.linkRef(pointer+"/links/"+escapeRefString(name), v)
+	}
+	for name, v := range components.Callbacks {
+		index.callbackRef(pointer+"/callbacks/"+escapeRefString(name), v)
+	}
+	for name, v := range components.PathItems {
+		index.pathItem(pointer+"/pathItems/"+escapeRefString(name), v)
+	}
+}
+
+func (index positions) pathItem(pointer string, pathItem *PathItem) {
+	if pathItem == nil {
+		return
+	}
+	index.set(pointer, &pathItem.position)
+	for method, operation := range pathItem.Operations() {
+		index.operation(pointer+"/"+strings.ToLower(method), operation)
+	}
+	index.parameters(pointer+"/parameters", pathItem.Parameters)
+}
+
+func (index positions) operation(pointer string, operation *Operation) {
+	if operation == nil {
+		return
+	}
+	index.set(pointer, &operation.position)
+	index.parameters(pointer+"/parameters", operation.Parameters)
+	index.requestBodyRef(pointer+"/requestBody", operation.RequestBody)
+	if operation.Responses != nil {
+		for code, v := range operation.Responses.Map() {
+			index.responseRef(pointer+"/responses/"+escapeRefString(code), v)
+		}
+	}
+	for name, v := range operation.Callbacks {
+		index.callbackRef(pointer+"/callbacks/"+escapeRefString(name), v)
+	}
+}
+
+func (index positions) parameters(pointer string, parameters Parameters) {
+	for i, v := range parameters {
+		index.parameterRef(pointer+"/"+strconv.Itoa(i), v)
+	}
+}
+
+func (index positions) parameterRef(pointer string, ref *ParameterRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.parameter(pointer, ref.Value)
+	}
+}
+
+func (index positions) headerRef(pointer string, ref *HeaderRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.parameter(pointer, &ref.Value.Parameter)
+	}
+}
+
+func (index positions) parameter(pointer string, parameter *Parameter) {
+	index.set(pointer, &parameter.position)
+	index.schemaRef(pointer+"/schema", parameter.Schema)
+	index.examples(pointer+"/examples", parameter.Examples)
+	index.content(pointer+"/content", parameter.Content)
+}
+
+func (index positions) requestBodyRef(pointer string, ref *RequestBodyRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.requestBody(pointer, ref.Value)
+	}
+}
+
+func (index positions) requestBody(pointer string, requestBody *RequestBody) {
+	index.set(pointer, &requestBody.position)
+	index.content(pointer+"/content", requestBody.Content)
+}
+
+func (index positions) responseRef(pointer string, ref *ResponseRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.response(pointer, ref.Value)
+	}
+}
+
+func (index positions) response(pointer string, response *Response) {
+	index.set(pointer, &response.position)
+	for name, v := range response.Headers {
+		index.headerRef(pointer+"/headers/"+escapeRefString(name), v)
+	}
+	index.content(pointer+"/content", response.Content)
+	for name, v := range response.Links {
+		index.linkRef(pointer+"/links/"+escapeRefString(name), v)
+	}
+}
+
+func (index positions) content(pointer string, content Content) {
+	for mediaType, v := range content {
+		if v == nil {
+			continue
+		}
+		p := pointer + "/" + escapeRefString(mediaType)
+		index.schemaRef(p+"/schema", v.Schema)
+		index.examples(p+"/examples", v.Examples)
+		for name, encoding := range v.Encoding {
+			if encoding == nil {
+				continue
+			}
+			for header, h := range encoding.Headers {
+				index.headerRef(p+"/encoding/"+escapeRefString(name)+"/headers/"+escapeRefString(header), h)
+			}
+		}
+	}
+}
+
+func (index positions) examples(pointer string, examples Examples) {
+	for name, v := range examples {
+		index.exampleRef(pointer+"/"+escapeRefString(name), v)
+	}
+}
+
+func (index positions) exampleRef(pointer string, ref *ExampleRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.set(pointer, &ref.Value.position)
+	}
+}
+
+func (index positions) linkRef(pointer string, ref *LinkRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.set(pointer, &ref.Value.position)
+	}
+}
+
+func (index positions) callbackRef(pointer string, ref *CallbackRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.callback(pointer, ref.Value)
+	}
+}
+
+func (index positions) callback(pointer string, callback *Callback) {
+	index.set(pointer, &callback.position)
+	for expression, pathItem := range callback.Map() {
+		index.pathItem(pointer+"/"+escapeRefString(expression), pathItem)
+	}
+}
+
+func (index positions) schemaRef(pointer string, ref *SchemaRef) {
+	if ref != nil && ref.Ref == "" && ref.Value != nil {
+		index.schema(pointer, ref.Value)
+	}
+}
+
+func (index positions) schema(pointer string, schema *Schema) {
+	index.set(pointer, &schema.position)
+	for field, ref := range map[string]*SchemaRef{
+		"not":                   schema.Not,
+		"if":                    schema.If,
+		"then":                  schema.Then,
+		"else":                  schema.Else,
+		"items":                 schema.Items,
+		"contains":              schema.Contains,
+		"unevaluatedItems":      schema.UnevaluatedItems,
+		"additionalProperties":  schema.AdditionalProperties.Schema,
+		"propertyNames":         schema.PropertyNames,
+		"unevaluatedProperties": schema.UnevaluatedProperties,
+		"contentSchema":         schema.ContentSchema,
+	} {
+		index.schemaRef(pointer+"/"+field, ref)
+	}
+	for field, refs := range map[string]SchemaRefs{
+		"allOf":       schema.AllOf,
+		"anyOf":       schema.AnyOf,
+		"oneOf":       schema.OneOf,
+		"prefixItems": schema.PrefixItems,
+	} {
+		for i, ref := range refs {
+			index.schemaRef(pointer+"/"+field+"/"+strconv.Itoa(i), ref)
+		}
+	}
+	for field, schemas := range map[string]Schemas{
+		"$defs":             schema.Defs,
+		"properties":        schema.Properties,
+		"patternProperties": schema.PatternProperties,
+		"dependentSchemas":  schema.DependentSchemas,
+	} {
+		for name, ref := range schemas {
+			index.schemaRef(pointer+"/"+field+"/"+escapeRefString(name), ref)
+		}
+	}
+}
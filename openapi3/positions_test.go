@@ -0,0 +1,70 @@
+package openapi3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositions(t *testing.T) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.IncludePositions = true
+	doc, err := loader.LoadFromFile("testdata/positions/openapi.yml")
+	require.NoError(t, err)
+
+	get := doc.Paths.Value("/pets/{id}").Get
+	require.Equal(t, "testdata/positions/openapi.yml:6:3", doc.Paths.Value("/pets/{id}").Position().String())
+	require.Equal(t, "testdata/positions/openapi.yml:7:5", get.Position().String())
+	require.Equal(t, "testdata/positions/openapi.yml:11:9", get.Responses.Status(200).Value.Position().String())
+
+	// Values of external documents are positioned in their file
+	require.Equal(t, "testdata/positions/components.yml:8:5", get.Parameters[0].Value.Position().String())
+	require.Equal(t, "testdata/positions/components.yml:12:7", get.Parameters[0].Value.Schema.Value.Position().String())
+	pet := get.Responses.Status(200).Value.Content.Get("application/json").Schema.Value
+	require.Equal(t, "testdata/positions/components.yml:14:5", pet.Position().String())
+	name := pet.Properties["name"].Value
+	require.Equal(t, &Position{File: "testdata/positions/components.yml", Line: 17, Column: 9}, name.Position())
+
+	SchemaErrorDetailsDisabled = true
+	defer func() { SchemaErrorDetailsDisabled = false }()
+	err = pet.VisitJSON(map[string]any{"name": ""})
+	require.EqualError(t, err, `Error at "/name": minimum string length is 1 (schema at testdata/positions/components.yml:17:9)`)
+}
+
+func TestPositionsInValidationErrors(t *testing.T) {
+	loader := NewLoader()
+	loader.IncludePositions = true
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "text"}
+        }
+      }
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	err = doc.Validate(context.Background())
+	require.ErrorContains(t, err, `invalid components: schema "Pet": 10:11: unsupported 'type' value "text"`)
+
+	var positionErr *PositionError
+	require.True(t, errors.As(err, &positionErr))
+	require.Equal(t, &Position{Line: 10, Column: 11}, positionErr.Position)
+}
+
+func TestPositionsNotIncludedByDefault(t *testing.T) {
+	loader := NewLoader()
+	doc, err := loader.LoadFromFile("testdata/positions/components.yml")
+	require.NoError(t, err)
+	require.Nil(t, doc.Components.Schemas["Pet"].Value.Position())
+}
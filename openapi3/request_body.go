@@ -14,6 +14,8 @@ type RequestBody struct {
 	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
 	Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
 	Content     Content `json:"content" yaml:"content"`
+
+	position *Position
 }
 
 func NewRequestBody() *RequestBody {
@@ -119,8 +121,9 @@ func (requestBody *RequestBody) UnmarshalJSON(data []byte) error {
 }
 
 // Validate returns an error if RequestBody does not comply with the OpenAPI spec.
-func (requestBody *RequestBody) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (requestBody *RequestBody) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, requestBody.position) }()
 
 	if requestBody.Content == nil {
 		return errors.New("content of the request body is required")
@@ -107,6 +107,8 @@ type Response struct {
 	Headers     Headers `json:"headers,omitempty" yaml:"headers,omitempty"`
 	Content     Content `json:"content,omitempty" yaml:"content,omitempty"`
 	Links       Links   `json:"links,omitempty" yaml:"links,omitempty"`
+
+	position *Position
 }
 
 func NewResponse() *Response {
@@ -183,8 +185,9 @@ func (response *Response) UnmarshalJSON(data []byte) error {
 }
 
 // Validate returns an error if Response does not comply with the OpenAPI spec.
-func (response *Response) Validate(ctx context.Context, opts ...ValidationOption) error {
+func (response *Response) Validate(ctx context.Context, opts ...ValidationOption) (err error) {
 	ctx = WithValidationOptions(ctx, opts...)
+	defer func() { err = withPosition(err, response.position) }()
 
 	if response.Description == nil {
 		return errors.New("a short description of the response is required")
@@ -82,10 +82,21 @@ func (s SchemaRefs) JSONLookup(token string) (any, error) {
 type Schema struct {
 	Extensions map[string]any `json:"-" yaml:"-"`
 
+	// JSON Schema 2020-12 identifiers and definitions, resolved by the Loader
+	Dialect       string  `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	ID            string  `json:"$id,omitempty" yaml:"$id,omitempty"`
+	Anchor        string  `json:"$anchor,omitempty" yaml:"$anchor,omitempty"`
+	DynamicAnchor string  `json:"$dynamicAnchor,omitempty" yaml:"$dynamicAnchor,omitempty"`
+	DynamicRef    string  `json:"$dynamicRef,omitempty" yaml:"$dynamicRef,omitempty"`
+	Defs          Schemas `json:"$defs,omitempty" yaml:"$defs,omitempty"`
+
 	OneOf        SchemaRefs    `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
 	AnyOf        SchemaRefs    `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
 	AllOf        SchemaRefs    `json:"allOf,omitempty" yaml:"allOf,omitempty"`
 	Not          *SchemaRef    `json:"not,omitempty" yaml:"not,omitempty"`
+	If           *SchemaRef    `json:"if,omitempty" yaml:"if,omitempty"`
+	Then         *SchemaRef    `json:"then,omitempty" yaml:"then,omitempty"`
+	Else         *SchemaRef    `json:"else,omitempty" yaml:"else,omitempty"`
 	Type         *Types        `json:"type,omitempty" yaml:"type,omitempty"`
 	Title        string        `json:"title,omitempty" yaml:"title,omitempty"`
 	Format       string        `json:"format,omitempty" yaml:"format,omitempty"`
@@ -93,13 +104,24 @@ type Schema struct {
 	Enum         []any         `json:"enum,omitempty" yaml:"enum,omitempty"`
 	Default      any           `json:"default,omitempty" yaml:"default,omitempty"`
 	Example      any           `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples     []any         `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Const        any           `json:"const,omitempty" yaml:"const,omitempty"`
 	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
 
+	// dynamicRefTarget is where DynamicRef points to when not overridden by the dynamic scope.
+	dynamicRefTarget *SchemaRef
+	// dynamicAnchors holds the $dynamicAnchor of this schema resource, i.e. when it has an $id.
+	dynamicAnchors map[string]*Schema
+	// boolean is set for schemas written as true or false, to write them back so.
+	boolean *bool
+	// constNull is set for const: null, which Const cannot tell from no const.
+	constNull bool
+
 	// Array-related, here for struct compactness
 	UniqueItems bool `json:"uniqueItems,omitempty" yaml:"uniqueItems,omitempty"`
 	// Number-related, here for struct compactness
-	ExclusiveMin bool `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
-	ExclusiveMax bool `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	ExclusiveMin ExclusiveBound `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMax ExclusiveBound `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
 	// Properties
 	Nullable        bool `json:"nullable,omitempty" yaml:"nullable,omitempty"`
 	ReadOnly        bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
@@ -118,18 +140,41 @@ type Schema struct {
 	MaxLength *uint64 `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
 	Pattern   string  `json:"pattern,omitempty" yaml:"pattern,omitempty"`
 
+	// String-encoded content
+	ContentEncoding  string     `json:"contentEncoding,omitempty" yaml:"contentEncoding,omitempty"`
+	ContentMediaType string     `json:"contentMediaType,omitempty" yaml:"contentMediaType,omitempty"`
+	ContentSchema    *SchemaRef `json:"contentSchema,omitempty" yaml:"contentSchema,omitempty"`
+
 	// Array
-	MinItems uint64     `json:"minItems,omitempty" yaml:"minItems,omitempty"`
-	MaxItems *uint64    `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
-	Items    *SchemaRef `json:"items,omitempty" yaml:"items,omitempty"`
+	MinItems    uint64     `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems    *uint64    `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	Items       *SchemaRef `json:"items,omitempty" yaml:"items,omitempty"`
+	PrefixItems SchemaRefs `json:"prefixItems,omitempty" yaml:"prefixItems,omitempty"`
+	Contains    *SchemaRef `json:"contains,omitempty" yaml:"contains,omitempty"`
+	MinContains *uint64    `json:"minContains,omitempty" yaml:"minContains,omitempty"`
+	MaxContains *uint64    `json:"maxContains,omitempty" yaml:"maxContains,omitempty"`
+
+	// UnevaluatedItems applies to the items that no other keyword,
+	// including those of in-place applicators such as allOf, evaluated.
+	UnevaluatedItems *SchemaRef `json:"unevaluatedItems,omitempty" yaml:"unevaluatedItems,omitempty"`
 
 	// Object
 	Required             []string             `json:"required,omitempty" yaml:"required,omitempty"`
 	Properties           Schemas              `json:"properties,omitempty" yaml:"properties,omitempty"`
+	PatternProperties    Schemas              `json:"patternProperties,omitempty" yaml:"patternProperties,omitempty"`
+	PropertyNames        *SchemaRef           `json:"propertyNames,omitempty" yaml:"propertyNames,omitempty"`
 	MinProps             uint64               `json:"minProperties,omitempty" yaml:"minProperties,omitempty"`
 	MaxProps             *uint64              `json:"maxProperties,omitempty" yaml:"maxProperties,omitempty"`
 	AdditionalProperties AdditionalProperties `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
 	Discriminator        *Discriminator       `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
+	DependentRequired    map[string][]string  `json:"dependentRequired,omitempty" yaml:"dependentRequired,omitempty"`
+	DependentSchemas     Schemas              `json:"dependentSchemas,omitempty" yaml:"dependentSchemas,omitempty"`
+
+	// UnevaluatedProperties applies to the properties that no other keyword,
+	// including those of in-place applicators such as allOf, evaluated.
+	UnevaluatedProperties *SchemaRef `json:"unevaluatedProperties,omitempty" yaml:"unevaluatedProperties,omitempty"`
+
+	position *Position
 }
 
 type Types []string
@@ -201,6 +246,57 @@ func (types *Types) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ExclusiveBound is the value of exclusiveMinimum or exclusiveMaximum.
+// OpenAPI 3.0 defines it as a boolean modifying minimum or maximum
+// whereas OpenAPI 3.1 (JSON Schema 2020-12) defines it as a number.
+type ExclusiveBound struct {
+	IsTrue bool
+	Value  *float64
+}
+
+// IsSet tells whether either form of the bound is set.
+func (bound ExclusiveBound) IsSet() bool {
+	return bound.IsTrue || bound.Value != nil
+}
+
+// MarshalYAML returns the YAML encoding of ExclusiveBound.
+func (bound ExclusiveBound) MarshalYAML() (any, error) {
+	if x := bound.Value; x != nil {
+		return *x, nil
+	}
+	if bound.IsTrue {
+		return true, nil
+	}
+	return nil, nil
+}
+
+// MarshalJSON returns the JSON encoding of ExclusiveBound.
+func (bound ExclusiveBound) MarshalJSON() ([]byte, error) {
+	x, err := bound.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(x)
+}
+
+// UnmarshalJSON sets ExclusiveBound to a copy of data.
+func (bound *ExclusiveBound) UnmarshalJSON(data []byte) error {
+	var x any
+	if err := json.Unmarshal(data, &x); err != nil {
+		return unmarshalError(err)
+	}
+	switch y := x.(type) {
+	case nil:
+	case bool:
+		bound.IsTrue = y
+	case float64:
+		bound.Value = &y
+	default:
+		return errors.New("cannot unmarshal exclusive bound: value must be either a number or a boolean")
+	}
+	return nil
+}
+
 type AdditionalProperties struct {
 	Has    *bool
 	Schema *SchemaRef
@@ -278,6 +374,24 @@ func (schema Schema) MarshalYAML() (any, error) {
 		m[k] = v
 	}
 
+	if x := schema.Dialect; x != "" {
+		m["$schema"] = x
+	}
+	if x := schema.ID; x != "" {
+		m["$id"] = x
+	}
+	if x := schema.Anchor; x != "" {
+		m["$anchor"] = x
+	}
+	if x := schema.DynamicAnchor; x != "" {
+		m["$dynamicAnchor"] = x
+	}
+	if x := schema.DynamicRef; x != "" {
+		m["$dynamicRef"] = x
+	}
+	if x := schema.Defs; len(x) != 0 {
+		m["$defs"] = x
+	}
 	if x := schema.OneOf; len(x) != 0 {
 		m["oneOf"] = x
 	}
@@ -290,6 +404,15 @@ func (schema Schema) MarshalYAML() (any, error) {
 	if x := schema.Not; x != nil {
 		m["not"] = x
 	}
+	if x := schema.If; x != nil {
+		m["if"] = x
+	}
+	if x := schema.Then; x != nil {
+		m["then"] = x
+	}
+	if x := schema.Else; x != nil {
+		m["else"] = x
+	}
 	if x := schema.Type; x != nil {
 		m["type"] = x
 	}
@@ -311,6 +434,12 @@ func (schema Schema) MarshalYAML() (any, error) {
 	if x := schema.Example; x != nil {
 		m["example"] = x
 	}
+	if x := schema.Examples; len(x) != 0 {
+		m["examples"] = x
+	}
+	if schema.HasConst() {
+		m["const"] = schema.Const
+	}
 	if x := schema.ExternalDocs; x != nil {
 		m["externalDocs"] = x
 	}
@@ -320,10 +449,10 @@ func (schema Schema) MarshalYAML() (any, error) {
 		m["uniqueItems"] = x
 	}
 	// Number-related
-	if x := schema.ExclusiveMin; x {
+	if x := schema.ExclusiveMin; x.IsSet() {
 		m["exclusiveMinimum"] = x
 	}
-	if x := schema.ExclusiveMax; x {
+	if x := schema.ExclusiveMax; x.IsSet() {
 		m["exclusiveMaximum"] = x
 	}
 	// Properties
@@ -367,6 +496,15 @@ func (schema Schema) MarshalYAML() (any, error) {
 	if x := schema.Pattern; x != "" {
 		m["pattern"] = x
 	}
+	if x := schema.ContentEncoding; x != "" {
+		m["contentEncoding"] = x
+	}
+	if x := schema.ContentMediaType; x != "" {
+		m["contentMediaType"] = x
+	}
+	if x := schema.ContentSchema; x != nil {
+		m["contentSchema"] = x
+	}
 
 	// Array
 	if x := schema.MinItems; x != 0 {
@@ -378,6 +516,21 @@ func (schema Schema) MarshalYAML() (any, error) {
 	if x := schema.Items; x != nil {
 		m["items"] = x
 	}
+	if x := schema.PrefixItems; len(x) != 0 {
+		m["prefixItems"] = x
+	}
+	if x := schema.Contains; x != nil {
+		m["contains"] = x
+	}
+	if x := schema.MinContains; x != nil {
+		m["minContains"] = x
+	}
+	if x := schema.MaxContains; x != nil {
+		m["maxContains"] = x
+	}
+	if x := schema.UnevaluatedItems; x != nil {
+		m["unevaluatedItems"] = x
+	}
 
 	// Object
 	if x := schema.Required; len(x) != 0 {
@@ -386,6 +539,12 @@ func (schema Schema) MarshalYAML() (any, error) {
 	if x := schema.Properties; len(x) != 0 {
 		m["properties"] = x
 	}
+	if x := schema.PatternProperties; len(x) != 0 {
+		m["patternProperties"] = x
+	}
+	if x := schema.PropertyNames; x != nil {
+		m["propertyNames"] = x
+	}
 	if x := schema.MinProps; x != 0 {
 		m["minProperties"] = x
 	}
@@ -398,12 +557,125 @@ func (schema Schema) MarshalYAML() (any, error) {
 	if x := schema.Discriminator; x != nil {
 		m["discriminator"] = x
 	}
+	if x := schema.DependentRequired; len(x) != 0 {
+		m["dependentRequired"] = x
+	}
+	if x := schema.DependentSchemas; len(x) != 0 {
+		m["dependentSchemas"] = x
+	}
+	if x := schema.UnevaluatedProperties; x != nil {
+		m["unevaluatedProperties"] = x
+	}
 
+	if x := schema.boolean; x != nil && schema.isBoolean(*x, m) {
+		return *x, nil
+	}
 	return m, nil
 }
 
+// validateOpenAPIVersion returns an error if schema uses keywords, or forms of them,
+// that the version of the document being validated does not have.
+func (schema *Schema) validateOpenAPIVersion(ctx context.Context) error {
+	version := openAPIVersion(ctx)
+	if isOpenAPI3_1(ctx) {
+		requires3_0 := func(keyword string) error {
+			return fmt.Errorf("boolean %s requires openapi 3.0 (got %q)", keyword, version)
+		}
+		if schema.ExclusiveMin.IsTrue {
+			return requires3_0("exclusiveMinimum")
+		}
+		if schema.ExclusiveMax.IsTrue {
+			return requires3_0("exclusiveMaximum")
+		}
+		return nil
+	}
+	if !isOpenAPI3_0(ctx) {
+		return nil
+	}
+
+	requires3_1 := func(keyword string) error {
+		return fmt.Errorf("%s requires openapi 3.1 (got %q)", keyword, version)
+	}
+	if schema.writtenAsBoolean() {
+		return requires3_1("boolean schema")
+	}
+	for _, keyword := range []struct {
+		name string
+		set  bool
+	}{
+		{"$id", schema.ID != ""},
+		{"$anchor", schema.Anchor != ""},
+		{"$dynamicAnchor", schema.DynamicAnchor != ""},
+		{"$dynamicRef", schema.DynamicRef != ""},
+		{"$defs", len(schema.Defs) != 0},
+		{"if", schema.If != nil},
+		{"then", schema.Then != nil},
+		{"else", schema.Else != nil},
+		{"examples", len(schema.Examples) != 0},
+		{"const", schema.HasConst()},
+		{"numeric exclusiveMinimum", schema.ExclusiveMin.Value != nil},
+		{"numeric exclusiveMaximum", schema.ExclusiveMax.Value != nil},
+		{"contentEncoding", schema.ContentEncoding != ""},
+		{"contentMediaType", schema.ContentMediaType != ""},
+		{"contentSchema", schema.ContentSchema != nil},
+		{"prefixItems", len(schema.PrefixItems) != 0},
+		{"contains", schema.Contains != nil},
+		{"minContains", schema.MinContains != nil},
+		{"maxContains", schema.MaxContains != nil},
+		{"unevaluatedItems", schema.UnevaluatedItems != nil},
+		{"patternProperties", len(schema.PatternProperties) != 0},
+		{"propertyNames", schema.PropertyNames != nil},
+		{"dependentRequired", len(schema.DependentRequired) != 0},
+		{"dependentSchemas", len(schema.DependentSchemas) != 0},
+		{"unevaluatedProperties", schema.UnevaluatedProperties != nil},
+	} {
+		if keyword.set {
+			return requires3_1(keyword.name)
+		}
+	}
+	return nil
+}
+
+// writtenAsBoolean tells whether schema is written as the boolean schema true or false.
+func (schema *Schema) writtenAsBoolean() bool {
+	if schema.boolean == nil {
+		return false
+	}
+	v, err := schema.MarshalYAML()
+	_, ok := v.(bool)
+	return err == nil && ok
+}
+
+// isBoolean tells whether m, the encoding of a schema written as the boolean schema b,
+// still is the equivalent of b: {} for true or {"not": {}} for false.
+func (schema Schema) isBoolean(b bool, m map[string]any) bool {
+	if b {
+		return len(m) == 0
+	}
+	not := schema.Not
+	if len(m) != 1 || not == nil || not.Ref != "" || not.Value == nil {
+		return false
+	}
+	notM, err := not.Value.MarshalYAML()
+	if err != nil {
+		return false
+	}
+	empty, ok := notM.(map[string]any)
+	return ok && len(empty) == 0
+}
+
 // UnmarshalJSON sets Schema to a copy of data.
 func (schema *Schema) UnmarshalJSON(data []byte) error {
+	// JSON Schema 2020-12 boolean schemas: true accepts any value, false none.
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*schema = Schema{boolean: BoolPtr(true)}
+		return nil
+	case "false":
+		*schema = Schema{Not: NewSchemaRef("", NewSchema()), boolean: BoolPtr(false)}
+		return nil
+	}
+
 	type SchemaBis Schema
 	var x SchemaBis
 	if err := json.Unmarshal(data, &x); err != nil {
@@ -411,10 +683,19 @@ func (schema *Schema) UnmarshalJSON(data []byte) error {
 	}
 	_ = json.Unmarshal(data, &x.Extensions)
 
+	delete(x.Extensions, "$schema")
+	delete(x.Extensions, "$id")
+	delete(x.Extensions, "$anchor")
+	delete(x.Extensions, "$dynamicAnchor")
+	delete(x.Extensions, "$dynamicRef")
+	delete(x.Extensions, "$defs")
 	delete(x.Extensions, "oneOf")
 	delete(x.Extensions, "anyOf")
 	delete(x.Extensions, "allOf")
 	delete(x.Extensions, "not")
+	delete(x.Extensions, "if")
+	delete(x.Extensions, "then")
+	delete(x.Extensions, "else")
 	delete(x.Extensions, "type")
 	delete(x.Extensions, "title")
 	delete(x.Extensions, "format")
@@ -422,6 +703,11 @@ func (schema *Schema) UnmarshalJSON(data []byte) error {
 	delete(x.Extensions, "enum")
 	delete(x.Extensions, "default")
 	delete(x.Extensions, "example")
+	delete(x.Extensions, "examples")
+	if c, ok := x.Extensions["const"]; ok && c == nil {
+		x.constNull = true
+	}
+	delete(x.Extensions, "const")
 	delete(x.Extensions, "externalDocs")
 
 	// Array-related
@@ -446,19 +732,32 @@ func (schema *Schema) UnmarshalJSON(data []byte) error {
 	delete(x.Extensions, "minLength")
 	delete(x.Extensions, "maxLength")
 	delete(x.Extensions, "pattern")
+	delete(x.Extensions, "contentEncoding")
+	delete(x.Extensions, "contentMediaType")
+	delete(x.Extensions, "contentSchema")
 
 	// Array
 	delete(x.Extensions, "minItems")
 	delete(x.Extensions, "maxItems")
 	delete(x.Extensions, "items")
+	delete(x.Extensions, "prefixItems")
+	delete(x.Extensions, "contains")
+	delete(x.Extensions, "minContains")
+	delete(x.Extensions, "maxContains")
+	delete(x.Extensions, "unevaluatedItems")
 
 	// Object
 	delete(x.Extensions, "required")
 	delete(x.Extensions, "properties")
+	delete(x.Extensions, "patternProperties")
+	delete(x.Extensions, "propertyNames")
 	delete(x.Extensions, "minProperties")
 	delete(x.Extensions, "maxProperties")
 	delete(x.Extensions, "additionalProperties")
 	delete(x.Extensions, "discriminator")
+	delete(x.Extensions, "dependentRequired")
+	delete(x.Extensions, "dependentSchemas")
+	delete(x.Extensions, "unevaluatedProperties")
 
 	if len(x.Extensions) == 0 {
 		x.Extensions = nil
@@ -478,6 +777,18 @@ func (schema *Schema) UnmarshalJSON(data []byte) error {
 // JSONLookup implements https://pkg.go.dev/github.com/go-openapi/jsonpointer#JSONPointable
 func (schema Schema) JSONLookup(token string) (any, error) {
 	switch token {
+	case "$schema":
+		return schema.Dialect, nil
+	case "$id":
+		return schema.ID, nil
+	case "$anchor":
+		return schema.Anchor, nil
+	case "$dynamicAnchor":
+		return schema.DynamicAnchor, nil
+	case "$dynamicRef":
+		return schema.DynamicRef, nil
+	case "$defs":
+		return schema.Defs, nil
 	case "additionalProperties":
 		if addProps := schema.AdditionalProperties.Has; addProps != nil {
 			return *addProps, nil
@@ -502,6 +813,67 @@ func (schema Schema) JSONLookup(token string) (any, error) {
 			}
 			return schema.Items.Value, nil
 		}
+	case "if":
+		if schema.If != nil {
+			if schema.If.Ref != "" {
+				return &Ref{Ref: schema.If.Ref}, nil
+			}
+			return schema.If.Value, nil
+		}
+	case "then":
+		if schema.Then != nil {
+			if schema.Then.Ref != "" {
+				return &Ref{Ref: schema.Then.Ref}, nil
+			}
+			return schema.Then.Value, nil
+		}
+	case "else":
+		if schema.Else != nil {
+			if schema.Else.Ref != "" {
+				return &Ref{Ref: schema.Else.Ref}, nil
+			}
+			return schema.Else.Value, nil
+		}
+	case "patternProperties":
+		return schema.PatternProperties, nil
+	case "propertyNames":
+		if schema.PropertyNames != nil {
+			if schema.PropertyNames.Ref != "" {
+				return &Ref{Ref: schema.PropertyNames.Ref}, nil
+			}
+			return schema.PropertyNames.Value, nil
+		}
+	case "dependentRequired":
+		return schema.DependentRequired, nil
+	case "dependentSchemas":
+		return schema.DependentSchemas, nil
+	case "prefixItems":
+		return schema.PrefixItems, nil
+	case "contains":
+		if schema.Contains != nil {
+			if schema.Contains.Ref != "" {
+				return &Ref{Ref: schema.Contains.Ref}, nil
+			}
+			return schema.Contains.Value, nil
+		}
+	case "minContains":
+		return schema.MinContains, nil
+	case "maxContains":
+		return schema.MaxContains, nil
+	case "unevaluatedItems":
+		if schema.UnevaluatedItems != nil {
+			if schema.UnevaluatedItems.Ref != "" {
+				return &Ref{Ref: schema.UnevaluatedItems.Ref}, nil
+			}
+			return schema.UnevaluatedItems.Value, nil
+		}
+	case "unevaluatedProperties":
+		if schema.UnevaluatedProperties != nil {
+			if schema.UnevaluatedProperties.Ref != "" {
+				return &Ref{Ref: schema.UnevaluatedProperties.Ref}, nil
+			}
+			return schema.UnevaluatedProperties.Value, nil
+		}
 	case "oneOf":
 		return schema.OneOf, nil
 	case "anyOf":
@@ -522,6 +894,10 @@ func (schema Schema) JSONLookup(token string) (any, error) {
 		return schema.Default, nil
 	case "example":
 		return schema.Example, nil
+	case "examples":
+		return schema.Examples, nil
+	case "const":
+		return schema.Const, nil
 	case "externalDocs":
 		return schema.ExternalDocs, nil
 	case "uniqueItems":
@@ -554,6 +930,17 @@ func (schema Schema) JSONLookup(token string) (any, error) {
 		return schema.MaxLength, nil
 	case "pattern":
 		return schema.Pattern, nil
+	case "contentEncoding":
+		return schema.ContentEncoding, nil
+	case "contentMediaType":
+		return schema.ContentMediaType, nil
+	case "contentSchema":
+		if schema.ContentSchema != nil {
+			if schema.ContentSchema.Ref != "" {
+				return &Ref{Ref: schema.ContentSchema.Ref}, nil
+			}
+			return schema.ContentSchema.Value, nil
+		}
 	case "minItems":
 		return schema.MinItems, nil
 	case "maxItems":
@@ -698,12 +1085,24 @@ func (schema *Schema) WithMax(value float64) *Schema {
 }
 
 func (schema *Schema) WithExclusiveMin(value bool) *Schema {
-	schema.ExclusiveMin = value
+	schema.ExclusiveMin.IsTrue = value
 	return schema
 }
 
 func (schema *Schema) WithExclusiveMax(value bool) *Schema {
-	schema.ExclusiveMax = value
+	schema.ExclusiveMax.IsTrue = value
+	return schema
+}
+
+// WithExclusiveMinValue sets an OpenAPI 3.1 numeric exclusiveMinimum.
+func (schema *Schema) WithExclusiveMinValue(value float64) *Schema {
+	schema.ExclusiveMin.Value = &value
+	return schema
+}
+
+// WithExclusiveMaxValue sets an OpenAPI 3.1 numeric exclusiveMaximum.
+func (schema *Schema) WithExclusiveMaxValue(value float64) *Schema {
+	schema.ExclusiveMax.Value = &value
 	return schema
 }
 
@@ -712,6 +1111,32 @@ func (schema *Schema) WithEnum(values ...any) *Schema {
 	return schema
 }
 
+// WithConst sets the const of the schema, which can be nil for const: null.
+func (schema *Schema) WithConst(value any) *Schema {
+	schema.Const = value
+	schema.constNull = value == nil
+	return schema
+}
+
+// WithoutConst removes the const of the schema, including const: null.
+func (schema *Schema) WithoutConst() *Schema {
+	schema.Const = nil
+	schema.constNull = false
+	return schema
+}
+
+// WithoutBoolean makes the schema written as an object, such as {} for true
+// or {"not": {}} for false, when it was written as a boolean schema.
+func (schema *Schema) WithoutBoolean() *Schema {
+	schema.boolean = nil
+	return schema
+}
+
+// HasConst tells whether the schema has a const, Const being nil for const: null.
+func (schema *Schema) HasConst() bool {
+	return schema.Const != nil || schema.constNull
+}
+
 func (schema *Schema) WithDefault(defaultValue any) *Schema {
 	schema.Default = defaultValue
 	return schema
@@ -773,6 +1198,35 @@ func (schema *Schema) WithItems(value *Schema) *Schema {
 	return schema
 }
 
+// WithPrefixItems sets the schemas of the leading items of a tuple-like array.
+func (schema *Schema) WithPrefixItems(values ...*Schema) *Schema {
+	refs := make(SchemaRefs, 0, len(values))
+	for _, value := range values {
+		refs = append(refs, &SchemaRef{Value: value})
+	}
+	schema.PrefixItems = refs
+	return schema
+}
+
+func (schema *Schema) WithContains(value *Schema) *Schema {
+	schema.Contains = &SchemaRef{
+		Value: value,
+	}
+	return schema
+}
+
+func (schema *Schema) WithMinContains(i int64) *Schema {
+	n := uint64(i)
+	schema.MinContains = &n
+	return schema
+}
+
+func (schema *Schema) WithMaxContains(i int64) *Schema {
+	n := uint64(i)
+	schema.MaxContains = &n
+	return schema
+}
+
 func (schema *Schema) WithMinItems(i int64) *Schema {
 	n := uint64(i)
 	schema.MinItems = n
@@ -858,17 +1312,20 @@ func (schema *Schema) PermitsNull() bool {
 
 // IsEmpty tells whether schema is equivalent to the empty schema `{}`.
 func (schema *Schema) IsEmpty() bool {
-	if schema.Type != nil || schema.Format != "" || len(schema.Enum) != 0 ||
-		schema.UniqueItems || schema.ExclusiveMin || schema.ExclusiveMax ||
+	if schema.Type != nil || schema.Format != "" || len(schema.Enum) != 0 || schema.HasConst() || schema.DynamicRef != "" ||
+		schema.UniqueItems || schema.ExclusiveMin.IsSet() || schema.ExclusiveMax.IsSet() ||
 		schema.Nullable || schema.ReadOnly || schema.WriteOnly || schema.AllowEmptyValue ||
 		schema.Min != nil || schema.Max != nil || schema.MultipleOf != nil ||
 		schema.MinLength != 0 || schema.MaxLength != nil || schema.Pattern != "" ||
+		schema.ContentEncoding != "" || schema.ContentMediaType != "" ||
 		schema.MinItems != 0 || schema.MaxItems != nil ||
-		len(schema.Required) != 0 ||
+		schema.MinContains != nil || schema.MaxContains != nil ||
+		len(schema.Required) != 0 || len(schema.DependentRequired) != 0 ||
 		schema.MinProps != 0 || schema.MaxProps != nil {
 		return false
 	}
-	if n := schema.Not; n != nil && n.Value != nil && !n.Value.IsEmpty() {
+	// "not": {} matches nothing so is not the empty schema either.
+	if n := schema.Not; n != nil && n.Value != nil {
 		return false
 	}
 	if ap := schema.AdditionalProperties.Schema; ap != nil && ap.Value != nil && !ap.Value.IsEmpty() {
@@ -880,11 +1337,47 @@ func (schema *Schema) IsEmpty() bool {
 	if items := schema.Items; items != nil && items.Value != nil && !items.Value.IsEmpty() {
 		return false
 	}
+	// "contains": {} still requires a non-empty array
+	if schema.Contains != nil {
+		return false
+	}
+	if ui := schema.UnevaluatedItems; ui != nil && ui.Value != nil && !ui.Value.IsEmpty() {
+		return false
+	}
+	if up := schema.UnevaluatedProperties; up != nil && up.Value != nil && !up.Value.IsEmpty() {
+		return false
+	}
+	for _, s := range schema.PrefixItems {
+		if ss := s.Value; ss != nil && !ss.IsEmpty() {
+			return false
+		}
+	}
 	for _, s := range schema.Properties {
 		if ss := s.Value; ss != nil && !ss.IsEmpty() {
 			return false
 		}
 	}
+	for _, s := range schema.PatternProperties {
+		if ss := s.Value; ss != nil && !ss.IsEmpty() {
+			return false
+		}
+	}
+	if pn := schema.PropertyNames; pn != nil && pn.Value != nil && !pn.Value.IsEmpty() {
+		return false
+	}
+	for _, s := range schema.DependentSchemas {
+		if ss := s.Value; ss != nil && !ss.IsEmpty() {
+			return false
+		}
+	}
+	// "if" only matters through "then" and "else"
+	if schema.If != nil {
+		for _, s := range []*SchemaRef{schema.Then, schema.Else} {
+			if s != nil && s.Value != nil && !s.Value.IsEmpty() {
+				return false
+			}
+		}
+	}
 	for _, s := range schema.OneOf {
 		if ss := s.Value; ss != nil && !ss.IsEmpty() {
 			return false
@@ -911,7 +1404,9 @@ func (schema *Schema) Validate(ctx context.Context, opts ...ValidationOption) er
 }
 
 // returns the updated stack and an error if Schema does not comply with the OpenAPI spec.
-func (schema *Schema) validate(ctx context.Context, stack []*Schema) ([]*Schema, error) {
+func (schema *Schema) validate(ctx context.Context, stack []*Schema) (_ []*Schema, err error) {
+	defer func() { err = withPosition(err, schema.position) }()
+
 	validationOpts := getValidationOptions(ctx)
 
 	for _, existing := range stack {
@@ -925,6 +1420,27 @@ func (schema *Schema) validate(ctx context.Context, stack []*Schema) ([]*Schema,
 		return stack, errors.New("a property MUST NOT be marked as both readOnly and writeOnly being true")
 	}
 
+	if err := schema.validateIdentifiers(); err != nil {
+		return stack, err
+	}
+
+	if err := schema.validateOpenAPIVersion(ctx); err != nil {
+		return stack, err
+	}
+
+	for _, name := range componentNames(schema.Defs) {
+		ref := schema.Defs[name]
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, fmt.Errorf("$defs[%q]: %w", name, err)
+		}
+	}
+
 	for _, item := range schema.OneOf {
 		v := item.Value
 		if v == nil {
@@ -973,6 +1489,21 @@ func (schema *Schema) validate(ctx context.Context, stack []*Schema) ([]*Schema,
 		}
 	}
 
+	for _, ref := range []*SchemaRef{schema.If, schema.Then, schema.Else} {
+		if ref == nil {
+			continue
+		}
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, err
+		}
+	}
+
 	for _, schemaType := range schema.Type.Slice() {
 		switch schemaType {
 		case TypeBoolean:
@@ -1023,10 +1554,16 @@ func (schema *Schema) validate(ctx context.Context, stack []*Schema) ([]*Schema,
 				}
 			}
 		case TypeArray:
-			if schema.Items == nil {
+			// OpenAPI 3.1 arrays may be described by prefixItems or contains alone, or not at all.
+			if schema.Items == nil && !isOpenAPI3_1(ctx) {
 				return stack, errors.New("when schema type is 'array', schema 'items' must be non-null")
 			}
 		case TypeObject:
+		case TypeNull:
+			// OpenAPI 3.0 has nullable instead
+			if isOpenAPI3_0(ctx) {
+				return stack, fmt.Errorf("unsupported 'type' value %q", schemaType)
+			}
 		default:
 			return stack, fmt.Errorf("unsupported 'type' value %q", schemaType)
 		}
@@ -1044,6 +1581,49 @@ func (schema *Schema) validate(ctx context.Context, stack []*Schema) ([]*Schema,
 		}
 	}
 
+	for i, item := range schema.PrefixItems {
+		v := item.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(item.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, fmt.Errorf("prefixItems[%d]: %w", i, err)
+		}
+	}
+
+	if ref := schema.Contains; ref != nil {
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, err
+		}
+	}
+
+	if minC, maxC := schema.MinContains, schema.MaxContains; minC != nil && maxC != nil && *minC > *maxC {
+		return stack, fmt.Errorf("minContains (%d) is greater than maxContains (%d)", *minC, *maxC)
+	}
+
+	for _, ref := range []*SchemaRef{schema.UnevaluatedItems, schema.UnevaluatedProperties} {
+		if ref == nil {
+			continue
+		}
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, err
+		}
+	}
+
 	properties := make([]string, 0, len(schema.Properties))
 	for name := range schema.Properties {
 		properties = append(properties, name)
@@ -1058,7 +1638,73 @@ func (schema *Schema) validate(ctx context.Context, stack []*Schema) ([]*Schema,
 
 		var err error
 		if stack, err = v.validate(ctx, stack); err != nil {
-			return stack, err
+			return stack, err
+		}
+	}
+
+	for _, pattern := range componentNames(schema.PatternProperties) {
+		if !validationOpts.schemaPatternValidationDisabled {
+			if _, err := schema.compileRegexp(validationOpts.regexCompilerFunc, "patternProperties", pattern); err != nil {
+				return stack, err
+			}
+		}
+
+		ref := schema.PatternProperties[pattern]
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, fmt.Errorf("patternProperties[%q]: %w", pattern, err)
+		}
+	}
+
+	if ref := schema.PropertyNames; ref != nil {
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, fmt.Errorf("propertyNames: %w", err)
+		}
+	}
+
+	if ref := schema.ContentSchema; ref != nil {
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, fmt.Errorf("contentSchema: %w", err)
+		}
+	}
+
+	for _, name := range componentNames(schema.DependentSchemas) {
+		ref := schema.DependentSchemas[name]
+		v := ref.Value
+		if v == nil {
+			return stack, foundUnresolvedRef(ref.Ref)
+		}
+
+		var err error
+		if stack, err = v.validate(ctx, stack); err != nil {
+			return stack, fmt.Errorf("dependentSchemas[%q]: %w", name, err)
+		}
+	}
+
+	for _, name := range componentNames(schema.DependentRequired) {
+		seen := make(map[string]struct{}, len(schema.DependentRequired[name]))
+		for _, dependent := range schema.DependentRequired[name] {
+			if _, ok := seen[dependent]; ok {
+				return stack, fmt.Errorf("dependentRequired[%q]: duplicate property %q", name, dependent)
+			}
+			seen[dependent] = struct{}{}
 		}
 	}
 
@@ -1095,6 +1741,14 @@ func (schema *Schema) validate(ctx context.Context, stack []*Schema) ([]*Schema,
 		}
 	}
 
+	if !validationOpts.examplesValidationDisabled {
+		for i, x := range schema.Examples {
+			if err := validateExampleValue(ctx, x, schema); err != nil {
+				return stack, fmt.Errorf("invalid examples[%d]: %w", i, err)
+			}
+		}
+	}
+
 	return stack, validateExtensions(ctx, schema.Extensions)
 }
 
@@ -1134,11 +1788,14 @@ func (schema *Schema) VisitJSON(value any, opts ...SchemaValidationOption) error
 }
 
 func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (err error) {
+	done := schema.evaluate(settings)
+	defer func() { done(err) }()
+
 	switch value := value.(type) {
 	case nil:
 		// Don't use VisitJSONNull, as we still want to reach 'visitXOFOperations', since
 		// those could allow for a nullable value even though this one doesn't
-		if schema.PermitsNull() {
+		if schema.permitsNull(settings) {
 			return
 		}
 	case float64:
@@ -1150,7 +1807,8 @@ func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (
 		}
 	}
 
-	if schema.IsEmpty() {
+	// Empty schemas accept any value, but may still evaluate properties or items
+	if schema.IsEmpty() && settings.evaluation == nil {
 		switch value.(type) {
 		case nil:
 			return schema.visitJSONNull(settings)
@@ -1159,9 +1817,19 @@ func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (
 		}
 	}
 
+	if schema.dynamicAnchors != nil {
+		settings.dynamicScope = append(settings.dynamicScope, schema)
+		defer func() { settings.dynamicScope = settings.dynamicScope[:len(settings.dynamicScope)-1] }()
+	}
+	if err = schema.visitDynamicRef(settings, value); err != nil {
+		return
+	}
 	if err = schema.visitNotOperation(settings, value); err != nil {
 		return
 	}
+	if err = schema.visitConditionalOperation(settings, value); err != nil {
+		return
+	}
 	var run bool
 	if err, run = schema.visitXOFOperations(settings, value); err != nil || !run {
 		return
@@ -1169,6 +1837,9 @@ func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (
 	if err = schema.visitEnumOperation(settings, value); err != nil {
 		return
 	}
+	if err = schema.visitConstOperation(settings, value); err != nil {
+		return
+	}
 
 	switch value := value.(type) {
 	case nil:
@@ -1236,23 +1907,9 @@ func (schema *Schema) visitJSON(settings *schemaValidationSettings, value any) (
 func (schema *Schema) visitEnumOperation(settings *schemaValidationSettings, value any) (err error) {
 	if enum := schema.Enum; len(enum) != 0 {
 		for _, v := range enum {
-			switch c := value.(type) {
-			case json.Number:
-				var f float64
-				if f, err = strconv.ParseFloat(c.String(), 64); err != nil {
-					return err
-				}
-				if v == f {
-					return
-				}
-			case int64:
-				if v == float64(c) {
-					return
-				}
-			default:
-				if reflect.DeepEqual(v, value) {
-					return
-				}
+			var ok bool
+			if ok, err = enumValueEquals(v, value); err != nil || ok {
+				return
 			}
 		}
 		if settings.failfast {
@@ -1270,6 +1927,43 @@ func (schema *Schema) visitEnumOperation(settings *schemaValidationSettings, val
 	return
 }
 
+func (schema *Schema) visitConstOperation(settings *schemaValidationSettings, value any) (err error) {
+	if c := schema.Const; schema.HasConst() && !settings.ignores("const") {
+		var ok bool
+		if ok, err = enumValueEquals(c, value); err != nil || ok {
+			return
+		}
+		if settings.failfast {
+			return errSchema
+		}
+		expected, _ := json.Marshal(c)
+		return &SchemaError{
+			Value:                 value,
+			Schema:                schema,
+			SchemaField:           "const",
+			Reason:                fmt.Sprintf("value must be %s", string(expected)),
+			customizeMessageError: settings.customizeMessageError,
+		}
+	}
+	return
+}
+
+// enumValueEquals tells whether value is the allowed value v of "enum" or "const".
+func enumValueEquals(v, value any) (bool, error) {
+	switch c := value.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(c.String(), 64)
+		if err != nil {
+			return false, err
+		}
+		return v == f, nil
+	case int64:
+		return v == float64(c), nil
+	default:
+		return reflect.DeepEqual(v, value), nil
+	}
+}
+
 func (schema *Schema) visitNotOperation(settings *schemaValidationSettings, value any) (err error) {
 	if ref := schema.Not; ref != nil {
 		v := ref.Value
@@ -1291,6 +1985,60 @@ func (schema *Schema) visitNotOperation(settings *schemaValidationSettings, valu
 	return
 }
 
+// visitDynamicRef applies the schema that "$dynamicRef" resolves to in the dynamic scope.
+func (schema *Schema) visitDynamicRef(settings *schemaValidationSettings, value any) error {
+	if schema.DynamicRef == "" || settings.ignores("$dynamicRef") {
+		return nil
+	}
+	target, err := schema.dynamicRefSchema(settings)
+	if err != nil {
+		return err
+	}
+	return target.visitJSONInPlace(settings, value, settings.evaluation)
+}
+
+// visitConditionalOperation applies "then" to values matching "if" and "else" to the others.
+func (schema *Schema) visitConditionalOperation(settings *schemaValidationSettings, value any) (err error) {
+	ifRef := schema.If
+	if ifRef == nil || settings.ignores("if") {
+		return
+	}
+	ifSchema := ifRef.Value
+	if ifSchema == nil {
+		return foundUnresolvedRef(ifRef.Ref)
+	}
+
+	field, ref := "then", schema.Then
+	if ifSchema.visitJSONInPlace(settings.probe(), value, settings.evaluation) != nil {
+		field, ref = "else", schema.Else
+	}
+	if ref == nil {
+		return
+	}
+	v := ref.Value
+	if v == nil {
+		return foundUnresolvedRef(ref.Ref)
+	}
+	if err := v.visitJSONInPlace(settings, value, settings.evaluation); err != nil {
+		if settings.failfast {
+			return errSchema
+		}
+		reason := `value matches "if" but doesn't match "then"`
+		if field == "else" {
+			reason = `value doesn't match "if" nor "else"`
+		}
+		return &SchemaError{
+			Value:                 value,
+			Schema:                schema,
+			SchemaField:           field,
+			Reason:                reason,
+			Origin:                err,
+			customizeMessageError: settings.customizeMessageError,
+		}
+	}
+	return
+}
+
 // If the XOF operations pass successfully, abort further run of validation, as they will already be satisfied (unless the schema
 // itself is badly specified
 func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, value any) (err error, run bool) {
@@ -1351,7 +2099,7 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 				tempValue = deepcopy.Copy(value)
 			}
 
-			if err := v.visitJSON(settings, tempValue); err != nil {
+			if err := v.visitJSONInPlace(settings, tempValue, settings.evaluation); err != nil {
 				validationErrors = append(validationErrors, err)
 				continue
 			}
@@ -1403,7 +2151,7 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 			if settings.asreq || settings.asrep {
 				tempValue = deepcopy.Copy(value)
 			}
-			if err := v.visitJSON(settings, tempValue); err == nil {
+			if err := v.visitJSONInPlace(settings, tempValue, settings.evaluation); err == nil {
 				ok = true
 				matchedAnyOfIdx = idx
 				break
@@ -1423,6 +2171,14 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 		}
 
 		_ = v[matchedAnyOfIdx].Value.visitJSON(settings, value)
+		if settings.evaluation != nil {
+			// Annotations are collected from every schema the value matches
+			for _, item := range v[matchedAnyOfIdx+1:] {
+				if item.Value != nil {
+					_ = item.Value.visitJSONInPlace(settings.probe(), value, settings.evaluation)
+				}
+			}
+		}
 		visitedAnyOf = true
 	}
 
@@ -1431,7 +2187,7 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 		if v == nil {
 			return foundUnresolvedRef(item.Ref), false
 		}
-		if err := v.visitJSON(settings, value); err != nil {
+		if err := v.visitJSONInPlace(settings, value, settings.evaluation); err != nil {
 			if settings.failfast {
 				return errSchema, false
 			}
@@ -1456,7 +2212,7 @@ func (schema *Schema) visitXOFOperations(settings *schemaValidationSettings, val
 // https://github.com/OAI/OpenAPI-Specification/blob/main/versions/3.0.3.md#data-types
 // https://github.com/OAI/OpenAPI-Specification/blob/main/versions/3.0.3.md#schema-object
 func (schema *Schema) visitJSONNull(settings *schemaValidationSettings) (err error) {
-	if schema.PermitsNull() {
+	if schema.permitsNull(settings) || (schema.Type == nil && (settings.dialect.untypedNull() || schema.constNull)) {
 		return
 	}
 	if settings.failfast {
@@ -1566,7 +2322,7 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 	}
 
 	// "exclusiveMinimum"
-	if v := schema.ExclusiveMin; v && !(*schema.Min < value) {
+	if v := schema.ExclusiveMin.exclusiveBound(schema.Min); v != nil && !(*v < value) {
 		if settings.failfast {
 			return errSchema
 		}
@@ -1574,7 +2330,7 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 			Value:                 value,
 			Schema:                schema,
 			SchemaField:           "exclusiveMinimum",
-			Reason:                fmt.Sprintf("number must be more than %g", *schema.Min),
+			Reason:                fmt.Sprintf("number must be more than %g", *v),
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -1584,7 +2340,7 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 	}
 
 	// "exclusiveMaximum"
-	if v := schema.ExclusiveMax; v && !(*schema.Max > value) {
+	if v := schema.ExclusiveMax.exclusiveBound(schema.Max); v != nil && !(*v > value) {
 		if settings.failfast {
 			return errSchema
 		}
@@ -1592,7 +2348,7 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 			Value:                 value,
 			Schema:                schema,
 			SchemaField:           "exclusiveMaximum",
-			Reason:                fmt.Sprintf("number must be less than %g", *schema.Max),
+			Reason:                fmt.Sprintf("number must be less than %g", *v),
 			customizeMessageError: settings.customizeMessageError,
 		}
 		if !settings.multiError {
@@ -1666,6 +2422,18 @@ func (schema *Schema) visitJSONNumber(settings *schemaValidationSettings, value
 	return nil
 }
 
+// exclusiveBound returns the numeric exclusive bound in effect, if any:
+// either the 3.1 number or the 3.0 minimum/maximum marked exclusive.
+func (bound ExclusiveBound) exclusiveBound(inclusive *float64) *float64 {
+	if v := bound.Value; v != nil {
+		return v
+	}
+	if bound.IsTrue {
+		return inclusive
+	}
+	return nil
+}
+
 func (schema *Schema) VisitJSONString(value string) error {
 	settings := newSchemaValidationSettings()
 	return schema.visitJSONString(settings, value)
@@ -1787,6 +2555,14 @@ func (schema *Schema) visitJSONString(settings *schemaValidationSettings, value
 
 	}
 
+	// "contentEncoding", "contentMediaType" and "contentSchema"
+	if err := schema.visitJSONStringContent(settings, value); err != nil {
+		if !settings.multiError {
+			return err
+		}
+		me = append(me, err)
+	}
+
 	if len(me) > 0 {
 		return me
 	}
@@ -1865,14 +2641,88 @@ func (schema *Schema) visitJSONArray(settings *schemaValidationSettings, value [
 		me = append(me, err)
 	}
 
-	// "items"
+	// "prefixItems"
+	prefixItems := schema.PrefixItems
+	if settings.ignores("prefixItems") {
+		prefixItems = nil
+	}
+	for i, itemSchemaRef := range prefixItems {
+		if i >= len(value) {
+			break
+		}
+		itemSchema := itemSchemaRef.Value
+		if itemSchema == nil {
+			return foundUnresolvedRef(itemSchemaRef.Ref)
+		}
+		settings.evaluation.item(i)
+		if err := itemSchema.visitJSON(settings, value[i]); err != nil {
+			err = markSchemaErrorIndex(err, i)
+			if !settings.multiError {
+				return err
+			}
+			if itemMe, ok := err.(MultiError); ok {
+				me = append(me, itemMe...)
+			} else {
+				me = append(me, err)
+			}
+		}
+	}
+
+	// "items" (only applies to items not covered by "prefixItems")
 	if itemSchemaRef := schema.Items; itemSchemaRef != nil {
 		itemSchema := itemSchemaRef.Value
 		if itemSchema == nil {
 			return foundUnresolvedRef(itemSchemaRef.Ref)
 		}
+		for i := len(prefixItems); i < len(value); i++ {
+			settings.evaluation.item(i)
+			if err := itemSchema.visitJSON(settings, value[i]); err != nil {
+				err = markSchemaErrorIndex(err, i)
+				if !settings.multiError {
+					return err
+				}
+				if itemMe, ok := err.(MultiError); ok {
+					me = append(me, itemMe...)
+				} else {
+					me = append(me, err)
+				}
+			}
+		}
+	}
+
+	// "contains", "minContains" and "maxContains"
+	if err := schema.visitContains(settings, value); err != nil {
+		if !settings.multiError {
+			return err
+		}
+		me = append(me, err)
+	}
+
+	// "unevaluatedItems"
+	if ref := schema.UnevaluatedItems; ref != nil && !settings.ignores("unevaluatedItems") {
+		unevaluatedItems := ref.Value
+		if unevaluatedItems == nil {
+			return foundUnresolvedRef(ref.Ref)
+		}
+		evaluation := settings.evaluation
 		for i, item := range value {
-			if err := itemSchema.visitJSON(settings, item); err != nil {
+			if evaluation.hasItem(i) {
+				continue
+			}
+			evaluation.item(i)
+			if err := unevaluatedItems.visitJSON(settings, item); err != nil {
+				if settings.failfast {
+					return errSchema
+				}
+				if unevaluatedItems.isFalse() {
+					err = &SchemaError{
+						Value:                 value,
+						Schema:                schema,
+						SchemaField:           "unevaluatedItems",
+						Reason:                fmt.Sprintf("item at index %d is unevaluated", i),
+						customizeMessageError: settings.customizeMessageError,
+					}
+				}
 				err = markSchemaErrorIndex(err, i)
 				if !settings.multiError {
 					return err
@@ -1893,6 +2743,64 @@ func (schema *Schema) visitJSONArray(settings *schemaValidationSettings, value [
 	return nil
 }
 
+func (schema *Schema) visitContains(settings *schemaValidationSettings, value []any) error {
+	containsRef := schema.Contains
+	if containsRef == nil || settings.ignores("contains") {
+		return nil
+	}
+	contains := containsRef.Value
+	if contains == nil {
+		return foundUnresolvedRef(containsRef.Ref)
+	}
+
+	matchSettings := settings.probe()
+	var matched []int
+	for i, item := range value {
+		if err := contains.visitJSON(matchSettings, item); err == nil {
+			matched = append(matched, i)
+			settings.evaluation.item(i)
+		}
+	}
+
+	minContains := uint64(1)
+	if v := schema.MinContains; v != nil {
+		minContains = *v
+	}
+	if uint64(len(matched)) < minContains {
+		if settings.failfast {
+			return errSchema
+		}
+		reason := "no item matches the schema in contains"
+		field := "contains"
+		if schema.MinContains != nil {
+			reason = fmt.Sprintf("at least %d items must match the schema in contains, found %d at indices %v", minContains, len(matched), matched)
+			field = "minContains"
+		}
+		return &SchemaError{
+			Value:                 value,
+			Schema:                schema,
+			SchemaField:           field,
+			Reason:                reason,
+			customizeMessageError: settings.customizeMessageError,
+		}
+	}
+
+	if v := schema.MaxContains; v != nil && uint64(len(matched)) > *v {
+		if settings.failfast {
+			return errSchema
+		}
+		return markSchemaErrorIndex(&SchemaError{
+			Value:                 value,
+			Schema:                schema,
+			SchemaField:           "maxContains",
+			Reason:                fmt.Sprintf("at most %d items must match the schema in contains, found %d at indices %v", *v, len(matched), matched),
+			customizeMessageError: settings.customizeMessageError,
+		}, matched[*v])
+	}
+
+	return nil
+}
+
 func (schema *Schema) VisitJSONObject(value map[string]any) error {
 	settings := newSchemaValidationSettings()
 	return schema.visitJSONObject(settings, value)
@@ -1983,33 +2891,91 @@ func (schema *Schema) visitJSONObject(settings *schemaValidationSettings, value
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
+
+	// "patternProperties"
+	var patterns []string
+	if !settings.ignores("patternProperties") {
+		patterns = componentNames(schema.PatternProperties)
+	}
+	matchers := make([]RegexMatcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		cp, err := schema.regexpMatcher(settings, "patternProperties", pattern)
+		if err != nil {
+			return err
+		}
+		matchers = append(matchers, cp)
+	}
+
+	// "propertyNames"
+	var propertyNames *Schema
+	if ref := schema.PropertyNames; ref != nil && !settings.ignores("propertyNames") {
+		if propertyNames = ref.Value; propertyNames == nil {
+			return foundUnresolvedRef(ref.Ref)
+		}
+	}
 	for _, k := range keys {
 		v := value[k]
-		if properties != nil {
-			propertyRef := properties[k]
-			if propertyRef != nil {
-				p := propertyRef.Value
-				if p == nil {
-					return foundUnresolvedRef(propertyRef.Ref)
+		if propertyNames != nil {
+			if err := propertyNames.visitJSON(settings, k); err != nil {
+				if settings.failfast {
+					return errSchema
 				}
-				if err := p.visitJSON(settings, v); err != nil {
-					if settings.failfast {
-						return errSchema
-					}
-					err = markSchemaErrorKey(err, k)
-					if !settings.multiError {
-						return err
-					}
-					if v, ok := err.(MultiError); ok {
-						me = append(me, v...)
-						continue
-					}
-					me = append(me, err)
+				err := &SchemaError{
+					Value:                 value,
+					Schema:                schema,
+					SchemaField:           "propertyNames",
+					Reason:                fmt.Sprintf(`property name %q doesn't match the schema from "propertyNames"`, k),
+					customizeMessageError: settings.customizeMessageError,
+				}
+				if !settings.multiError {
+					return err
 				}
+				me = append(me, err)
+			}
+		}
+		var subschemas []*Schema
+		if propertyRef := properties[k]; propertyRef != nil {
+			p := propertyRef.Value
+			if p == nil {
+				return foundUnresolvedRef(propertyRef.Ref)
+			}
+			subschemas = append(subschemas, p)
+		}
+		for i, pattern := range patterns {
+			if !matchers[i].MatchString(k) {
 				continue
 			}
+			propertyRef := schema.PatternProperties[pattern]
+			p := propertyRef.Value
+			if p == nil {
+				return foundUnresolvedRef(propertyRef.Ref)
+			}
+			subschemas = append(subschemas, p)
+		}
+		for _, p := range subschemas {
+			if err := p.visitJSON(settings, v); err != nil {
+				if settings.failfast {
+					return errSchema
+				}
+				err = markSchemaErrorKey(err, k)
+				if !settings.multiError {
+					return err
+				}
+				if v, ok := err.(MultiError); ok {
+					me = append(me, v...)
+					continue
+				}
+				me = append(me, err)
+			}
+		}
+		if len(subschemas) != 0 {
+			settings.evaluation.property(k)
+			continue
 		}
 		if allowed := schema.AdditionalProperties.Has; allowed == nil || *allowed {
+			if allowed != nil || additionalProperties != nil {
+				settings.evaluation.property(k)
+			}
 			if additionalProperties != nil {
 				if err := additionalProperties.visitJSON(settings, v); err != nil {
 					if settings.failfast {
@@ -2044,6 +3010,99 @@ func (schema *Schema) visitJSONObject(settings *schemaValidationSettings, value
 		me = append(me, err)
 	}
 
+	// "dependentRequired"
+	for _, k := range componentNames(schema.DependentRequired) {
+		if _, ok := value[k]; !ok || settings.ignores("dependentRequired") {
+			continue
+		}
+		for _, dependent := range schema.DependentRequired[k] {
+			if _, ok := value[dependent]; ok {
+				continue
+			}
+			if settings.failfast {
+				return errSchema
+			}
+			err := markSchemaErrorKey(&SchemaError{
+				Value:                 value,
+				Schema:                schema,
+				SchemaField:           "dependentRequired",
+				Reason:                fmt.Sprintf("property %q is missing, as %q is present", dependent, k),
+				customizeMessageError: settings.customizeMessageError,
+			}, dependent)
+			if !settings.multiError {
+				return err
+			}
+			me = append(me, err)
+		}
+	}
+
+	// "dependentSchemas"
+	for _, k := range componentNames(schema.DependentSchemas) {
+		if _, ok := value[k]; !ok || settings.ignores("dependentSchemas") {
+			continue
+		}
+		ref := schema.DependentSchemas[k]
+		v := ref.Value
+		if v == nil {
+			return foundUnresolvedRef(ref.Ref)
+		}
+		if err := v.visitJSONInPlace(settings, value, settings.evaluation); err != nil {
+			if settings.failfast {
+				return errSchema
+			}
+			err = &SchemaError{
+				Value:                 value,
+				Schema:                schema,
+				SchemaField:           "dependentSchemas",
+				Reason:                fmt.Sprintf("doesn't match the schema that applies when %q is present", k),
+				Origin:                err,
+				customizeMessageError: settings.customizeMessageError,
+			}
+			if !settings.multiError {
+				return err
+			}
+			me = append(me, err)
+		}
+	}
+
+	// "unevaluatedProperties"
+	if ref := schema.UnevaluatedProperties; ref != nil && !settings.ignores("unevaluatedProperties") {
+		unevaluatedProperties := ref.Value
+		if unevaluatedProperties == nil {
+			return foundUnresolvedRef(ref.Ref)
+		}
+		evaluation := settings.evaluation
+		for _, k := range keys {
+			if evaluation.hasProperty(k) {
+				continue
+			}
+			evaluation.property(k)
+			if err := unevaluatedProperties.visitJSON(settings, value[k]); err != nil {
+				if settings.failfast {
+					return errSchema
+				}
+				if unevaluatedProperties.isFalse() {
+					err = &SchemaError{
+						Value:                 value,
+						Schema:                schema,
+						SchemaField:           "unevaluatedProperties",
+						Reason:                fmt.Sprintf("property %q is unevaluated", k),
+						customizeMessageError: settings.customizeMessageError,
+					}
+				}
+				err = markSchemaErrorKey(err, k)
+				if !settings.multiError {
+					return err
+				}
+				if v, ok := err.(MultiError); ok {
+					me = append(me, v...)
+					continue
+				}
+				me = append(me, err)
+			}
+		}
+	}
+
 	// "required"
 	for _, k := range schema.Required {
 		if _, ok := value[k]; !ok {
@@ -2194,6 +3253,12 @@ func (err *SchemaError) Error() string {
 		buf.WriteString(reason)
 	}
 
+	if position := err.Schema.Position(); position != nil {
+		buf.WriteString(" (schema at ")
+		buf.WriteString(position.String())
+		buf.WriteString(")")
+	}
+
 	if !SchemaErrorDetailsDisabled {
 		buf.WriteString("\nSchema:\n  ")
 		encoder := json.NewEncoder(buf)
@@ -0,0 +1,155 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaPrefixItems(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Coordinates:
+      type: array
+      prefixItems:
+      - type: number
+      - type: number
+      - $ref: '#/components/schemas/Label'
+      items: false
+    Row:
+      type: array
+      prefixItems:
+      - type: string
+      items:
+        type: integer
+    Label:
+      type: string
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	coordinates := doc.Components.Schemas["Coordinates"].Value
+	require.Len(t, coordinates.PrefixItems, 3)
+	require.Equal(t, doc.Components.Schemas["Label"].Value, coordinates.PrefixItems[2].Value)
+
+	require.NoError(t, coordinates.VisitJSON([]any{1.0, 2.0}))
+	require.NoError(t, coordinates.VisitJSON([]any{1.0, 2.0, "home"}))
+
+	err = coordinates.VisitJSON([]any{1.0, "north"})
+	require.ErrorContains(t, err, `Error at "/1": value must be a number`)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, []string{"1"}, schemaErr.JSONPointer())
+
+	err = coordinates.VisitJSON([]any{1.0, 2.0, "home", 4.0})
+	require.ErrorContains(t, err, `Error at "/3"`)
+
+	row := doc.Components.Schemas["Row"].Value
+	require.NoError(t, row.VisitJSON([]any{"id", 1.0, 2.0}))
+	err = row.VisitJSON([]any{"id", 1.0, "two"})
+	require.ErrorContains(t, err, `Error at "/2": value must be an integer`)
+
+	err = row.VisitJSON([]any{"id", "one", "two"}, MultiErrors())
+	require.Error(t, err)
+	var me MultiError
+	require.ErrorAs(t, err, &me)
+	require.Len(t, me, 2)
+
+	data, err := json.Marshal(coordinates)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "array",
+		"prefixItems": [{"type": "number"}, {"type": "number"}, {"$ref": "#/components/schemas/Label"}],
+		"items": false
+	}`, string(data))
+
+	v, err := coordinates.JSONLookup("prefixItems")
+	require.NoError(t, err)
+	require.Equal(t, coordinates.PrefixItems, v)
+}
+
+func TestSchemaBoolean(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Nothing:
+      type: array
+      items: false
+      contains: true
+    Not:
+      not: {}
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	nothing := doc.Components.Schemas["Nothing"].Value
+	require.ErrorContains(t, nothing.VisitJSON([]any{}), "no item matches the schema in contains")
+	require.Error(t, nothing.VisitJSON([]any{1.0}))
+
+	data, err := json.Marshal(doc.Components.Schemas)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"Nothing": {"type": "array", "items": false, "contains": true},
+		"Not": {"not": {}}
+	}`, string(data))
+
+	// Changed boolean schemas are written as objects
+	nothing.Items.Value.Description = "Nothing"
+	nothing.Contains.Value.Type = &Types{"string"}
+	data, err = json.Marshal(nothing)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type": "array", "items": {"not": {}, "description": "Nothing"}, "contains": {"type": "string"}}`, string(data))
+}
+
+func TestSchemaContains(t *testing.T) {
+	schema := NewArraySchema().WithContains(NewStringSchema().WithEnum("admin"))
+
+	require.NoError(t, schema.VisitJSON([]any{"user", "admin"}))
+	err := schema.VisitJSON([]any{"user", "guest"})
+	require.ErrorContains(t, err, "no item matches the schema in contains")
+
+	schema.WithMinContains(2).WithMaxContains(3)
+	require.NoError(t, schema.VisitJSON([]any{"admin", "user", "admin"}))
+
+	err = schema.VisitJSON([]any{"admin", "user"})
+	require.ErrorContains(t, err, "at least 2 items must match the schema in contains, found 1 at indices [0]")
+
+	err = schema.VisitJSON([]any{"admin", "admin", "user", "admin", "admin"})
+	require.ErrorContains(t, err, `Error at "/4": at most 3 items must match the schema in contains, found 4 at indices [0 1 3 4]`)
+
+	schema.WithMinContains(0)
+	require.NoError(t, schema.VisitJSON([]any{}))
+
+	require.False(t, schema.IsMatching([]any{"admin", "admin", "admin", "admin"}))
+}
+
+func TestSchemaArrayKeywordsValidate(t *testing.T) {
+	ctx := context.Background()
+
+	schema := NewArraySchema().WithPrefixItems(NewStringSchema())
+	err := schema.Validate(ctx)
+	require.EqualError(t, err, "when schema type is 'array', schema 'items' must be non-null")
+	err = schema.Validate(withOpenAPIVersion(ctx, "3.1.0"))
+	require.NoError(t, err)
+
+	schema = NewArraySchema().WithItems(NewStringSchema()).WithContains(NewStringSchema()).WithMinContains(3).WithMaxContains(2)
+	err = schema.Validate(ctx)
+	require.EqualError(t, err, "minContains (3) is greater than maxContains (2)")
+
+	schema = NewArraySchema().WithItems(NewStringSchema()).WithPrefixItems(NewStringSchema(), &Schema{Type: &Types{"foo"}})
+	err = schema.Validate(ctx)
+	require.EqualError(t, err, `prefixItems[1]: unsupported 'type' value "foo"`)
+}
@@ -0,0 +1,129 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaIfThenElse(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Payment:
+      type: object
+      properties:
+        type: {type: string, enum: [card, transfer]}
+        cardNumber: {type: string}
+        iban: {type: string}
+      if:
+        properties:
+          type: {enum: [card]}
+        required: [type]
+      then:
+        $ref: '#/components/schemas/CardPayment'
+      else:
+        required: [iban]
+    CardPayment:
+      required: [cardNumber]
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	payment := doc.Components.Schemas["Payment"].Value
+	require.Equal(t, doc.Components.Schemas["CardPayment"].Value, payment.Then.Value)
+
+	require.NoError(t, payment.VisitJSON(map[string]any{"type": "card", "cardNumber": "4242"}))
+	require.NoError(t, payment.VisitJSON(map[string]any{"type": "transfer", "iban": "FR76"}))
+
+	err = payment.VisitJSON(map[string]any{"type": "card", "iban": "FR76"})
+	require.ErrorContains(t, err, `property "cardNumber" is missing`)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "then", schemaErr.SchemaField)
+
+	err = payment.VisitJSON(map[string]any{"type": "transfer", "cardNumber": "4242"})
+	require.ErrorContains(t, err, `property "iban" is missing`)
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "else", schemaErr.SchemaField)
+
+	data, err := json.Marshal(payment)
+	require.NoError(t, err)
+	var raw map[string]any
+	err = json.Unmarshal(data, &raw)
+	require.NoError(t, err)
+	require.Contains(t, raw, "if")
+	require.Equal(t, map[string]any{"$ref": "#/components/schemas/CardPayment"}, raw["then"])
+	require.Contains(t, raw, "else")
+
+	v, err := payment.JSONLookup("then")
+	require.NoError(t, err)
+	require.Equal(t, &Ref{Ref: "#/components/schemas/CardPayment"}, v)
+}
+
+func TestSchemaDependentRequired(t *testing.T) {
+	schema := NewObjectSchema()
+	schema.DependentRequired = map[string][]string{
+		"cardNumber": {"expiry", "cvc"},
+	}
+
+	require.NoError(t, schema.VisitJSON(map[string]any{}))
+	require.NoError(t, schema.VisitJSON(map[string]any{"cardNumber": "4242", "expiry": "12/30", "cvc": "123"}))
+
+	err := schema.VisitJSON(map[string]any{"cardNumber": "4242", "expiry": "12/30"})
+	require.ErrorContains(t, err, `Error at "/cvc": property "cvc" is missing, as "cardNumber" is present`)
+
+	err = schema.VisitJSON(map[string]any{"cardNumber": "4242"}, MultiErrors())
+	var me MultiError
+	require.ErrorAs(t, err, &me)
+	require.Len(t, me, 2)
+
+	schema.DependentRequired["expiry"] = []string{"cvc", "cvc"}
+	err = schema.Validate(context.Background())
+	require.EqualError(t, err, `dependentRequired["expiry"]: duplicate property "cvc"`)
+}
+
+func TestSchemaDependentSchemas(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Billing:
+      type: object
+      properties:
+        name: {type: string}
+        creditCard: {type: string}
+      dependentSchemas:
+        creditCard:
+          $ref: '#/components/schemas/BillingAddress'
+    BillingAddress:
+      properties:
+        billingAddress: {type: string}
+      required: [billingAddress]
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	billing := doc.Components.Schemas["Billing"].Value
+	require.Equal(t, doc.Components.Schemas["BillingAddress"].Value, billing.DependentSchemas["creditCard"].Value)
+
+	require.NoError(t, billing.VisitJSON(map[string]any{"name": "John"}))
+	require.NoError(t, billing.VisitJSON(map[string]any{"creditCard": "4242", "billingAddress": "Main St"}))
+
+	err = billing.VisitJSON(map[string]any{"creditCard": "4242"})
+	require.ErrorContains(t, err, `property "billingAddress" is missing`)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "dependentSchemas", schemaErr.SchemaField)
+}
@@ -0,0 +1,93 @@
+package openapi3
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Keywords "contentEncoding", "contentMediaType" and "contentSchema" describe
+// content carried as a string, such as a base64-encoded JSON document.
+// See https://json-schema.org/draft/2020-12/json-schema-validation#section-8
+
+// contentDecoders decodes strings by their "contentEncoding".
+// Encodings not listed here are not validated.
+var contentDecoders = map[string]func(string) ([]byte, error){
+	"base16":    hex.DecodeString,
+	"base32":    base32.StdEncoding.DecodeString,
+	"base64":    base64.StdEncoding.DecodeString,
+	"base64url": base64.URLEncoding.DecodeString,
+}
+
+func (schema *Schema) visitJSONStringContent(settings *schemaValidationSettings, value string) error {
+	content := []byte(value)
+	if encoding := schema.ContentEncoding; encoding != "" && !settings.ignores("contentEncoding") {
+		if decode, ok := contentDecoders[strings.ToLower(encoding)]; ok {
+			decoded, err := decode(value)
+			if err != nil {
+				if settings.failfast {
+					return errSchema
+				}
+				return &SchemaError{
+					Value:                 value,
+					Schema:                schema,
+					SchemaField:           "contentEncoding",
+					Reason:                fmt.Sprintf("string is not valid %s encoded content", encoding),
+					customizeMessageError: settings.customizeMessageError,
+				}
+			}
+			content = decoded
+		}
+	}
+
+	if !isJSONMediaType(schema.ContentMediaType) || settings.ignores("contentMediaType") {
+		return nil
+	}
+
+	var data any
+	if err := json.Unmarshal(content, &data); err != nil {
+		if settings.failfast {
+			return errSchema
+		}
+		return &SchemaError{
+			Value:                 value,
+			Schema:                schema,
+			SchemaField:           "contentMediaType",
+			Reason:                fmt.Sprintf("content is not valid %s: %v", schema.ContentMediaType, err),
+			customizeMessageError: settings.customizeMessageError,
+		}
+	}
+
+	if ref := schema.ContentSchema; ref != nil && ref.Value != nil && !settings.ignores("contentSchema") {
+		if err := ref.Value.visitJSON(settings, data); err != nil {
+			if settings.failfast {
+				return errSchema
+			}
+			return &SchemaError{
+				Value:                 value,
+				Schema:                schema,
+				SchemaField:           "contentSchema",
+				Reason:                `content doesn't match the schema from "contentSchema"`,
+				Origin:                err,
+				customizeMessageError: settings.customizeMessageError,
+			}
+		}
+	}
+	return nil
+}
+
+// isJSONMediaType tells whether mediaType is application/json or a +json structured syntax.
+func isJSONMediaType(mediaType string) bool {
+	if mediaType == "" {
+		return false
+	}
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
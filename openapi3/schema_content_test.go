@@ -0,0 +1,168 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaConst(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Cat:
+      type: object
+      properties:
+        kind: {const: cat}
+        lives: {type: integer, const: 9}
+      examples:
+      - {kind: cat, lives: 9}
+      - {kind: cat}
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	cat := doc.Components.Schemas["Cat"].Value
+	require.Equal(t, "cat", cat.Properties["kind"].Value.Const)
+	require.Len(t, cat.Examples, 2)
+
+	require.NoError(t, cat.VisitJSON(map[string]any{"kind": "cat", "lives": 9.0}))
+	require.NoError(t, cat.VisitJSON(map[string]any{"lives": json.Number("9")}))
+
+	err = cat.VisitJSON(map[string]any{"kind": "dog"})
+	require.ErrorContains(t, err, `Error at "/kind": value must be "cat"`)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "const", schemaErr.SchemaField)
+
+	err = cat.VisitJSON(map[string]any{"lives": 7.0})
+	require.ErrorContains(t, err, `Error at "/lives": value must be 9`)
+
+	cat.Examples = append(cat.Examples, map[string]any{"kind": "dog"})
+	err = cat.Validate(context.Background())
+	require.ErrorContains(t, err, `invalid examples[2]: Error at "/kind": value must be "cat"`)
+	err = cat.Validate(context.Background(), DisableExamplesValidation())
+	require.NoError(t, err)
+
+	data, err := json.Marshal(cat)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"kind": {"const": "cat"},
+			"lives": {"type": "integer", "const": 9}
+		},
+		"examples": [{"kind": "cat", "lives": 9}, {"kind": "cat"}, {"kind": "dog"}]
+	}`, string(data))
+
+	v, err := cat.Properties["lives"].Value.JSONLookup("const")
+	require.NoError(t, err)
+	require.Equal(t, 9.0, v)
+}
+
+func TestSchemaConstNull(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Nothing: {const: null}
+    None: {}
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	null := doc.Components.Schemas["Nothing"].Value
+	require.True(t, null.HasConst())
+	require.Nil(t, null.Const)
+	require.False(t, doc.Components.Schemas["None"].Value.HasConst())
+
+	require.NoError(t, null.VisitJSON(nil))
+	err = null.VisitJSON(0.0)
+	require.ErrorContains(t, err, `value must be null`)
+
+	data, err := json.Marshal(doc.Components.Schemas)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Nothing": {"const": null}, "None": {}}`, string(data))
+
+	schema := NewSchema().WithConst(nil)
+	require.Error(t, schema.VisitJSON("null"))
+	require.NoError(t, schema.WithoutConst().VisitJSON("null"))
+}
+
+func TestSchemaContent(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Token:
+      type: string
+      contentEncoding: base64
+      contentMediaType: application/json
+      contentSchema:
+        $ref: '#/components/schemas/Claims'
+    Claims:
+      type: object
+      properties:
+        sub: {type: string}
+      required: [sub]
+    Blob:
+      type: string
+      contentEncoding: base64
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	token := doc.Components.Schemas["Token"].Value
+	require.Equal(t, doc.Components.Schemas["Claims"].Value, token.ContentSchema.Value)
+
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	require.NoError(t, token.VisitJSON(encode(`{"sub": "me"}`)))
+
+	err = token.VisitJSON(encode(`{"sub": 1}`))
+	require.ErrorContains(t, err, `Error at "/sub": value must be a string`)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "contentSchema", schemaErr.SchemaField)
+
+	err = token.VisitJSON(encode(`{"sub":`))
+	require.ErrorContains(t, err, `content is not valid application/json: unexpected end of JSON input`)
+
+	err = token.VisitJSON("not base64!")
+	require.ErrorContains(t, err, `string is not valid base64 encoded content`)
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "contentEncoding", schemaErr.SchemaField)
+
+	blob := doc.Components.Schemas["Blob"].Value
+	require.NoError(t, blob.VisitJSON(encode("\x00\x01")))
+	require.Error(t, blob.VisitJSON("%%%"))
+
+	data, err := json.Marshal(token)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "string",
+		"contentEncoding": "base64",
+		"contentMediaType": "application/json",
+		"contentSchema": {"$ref": "#/components/schemas/Claims"}
+	}`, string(data))
+
+	v, err := token.JSONLookup("contentSchema")
+	require.NoError(t, err)
+	require.Equal(t, &Ref{Ref: "#/components/schemas/Claims"}, v)
+}
@@ -0,0 +1,63 @@
+package openapi3
+
+// SchemaDialect selects which keywords of Schema apply when visiting values.
+type SchemaDialect int
+
+const (
+	// SchemaDialectDefault applies all keywords Schema supports,
+	// from OpenAPI 3.0 and OpenAPI 3.1 alike.
+	SchemaDialectDefault SchemaDialect = iota
+	// SchemaDialectOpenAPI30 is the subset of JSON Schema that OpenAPI 3.0 defines,
+	// extended with "nullable".
+	SchemaDialectOpenAPI30
+	// SchemaDialectDraft04 is JSON Schema draft-04.
+	SchemaDialectDraft04
+	// SchemaDialect2020_12 is JSON Schema 2020-12, the dialect of OpenAPI 3.1.
+	SchemaDialect2020_12
+)
+
+// keywordsSinceDraft06 are the keywords that JSON Schema draft-06 and later introduced.
+var keywordsSinceDraft06 = map[string]struct{}{
+	"$dynamicRef":           {},
+	"const":                 {},
+	"contains":              {},
+	"contentEncoding":       {},
+	"contentMediaType":      {},
+	"contentSchema":         {},
+	"dependentRequired":     {},
+	"dependentSchemas":      {},
+	"if":                    {},
+	"maxContains":           {},
+	"minContains":           {},
+	"prefixItems":           {},
+	"propertyNames":         {},
+	"unevaluatedItems":      {},
+	"unevaluatedProperties": {},
+}
+
+// ignores tells whether the keyword has no effect on validation in the selected dialect.
+func (settings *schemaValidationSettings) ignores(keyword string) bool {
+	switch settings.dialect {
+	case SchemaDialectOpenAPI30:
+		_, ok := keywordsSinceDraft06[keyword]
+		return ok || keyword == "patternProperties"
+	case SchemaDialectDraft04:
+		_, ok := keywordsSinceDraft06[keyword]
+		return ok || keyword == "nullable"
+	case SchemaDialect2020_12:
+		return keyword == "nullable"
+	default:
+		return false
+	}
+}
+
+// untypedNull tells whether schemas without "type" accept null, as in JSON Schema,
+// rather than only when "nullable" as in OpenAPI 3.0.
+func (dialect SchemaDialect) untypedNull() bool {
+	return dialect == SchemaDialectDraft04 || dialect == SchemaDialect2020_12
+}
+
+// permitsNull is PermitsNull in the selected dialect.
+func (schema *Schema) permitsNull(settings *schemaValidationSettings) bool {
+	return (schema.Nullable && !settings.ignores("nullable")) || schema.Type.Includes(TypeNull)
+}
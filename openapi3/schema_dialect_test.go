@@ -0,0 +1,45 @@
+package openapi3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDialect(t *testing.T) {
+	schema := &Schema{
+		Type:              &Types{TypeObject},
+		Nullable:          true,
+		PatternProperties: Schemas{"^x-": NewStringSchema().NewRef()},
+		PropertyNames:     NewStringSchema().WithMaxLength(3).NewRef(),
+	}
+
+	// By default all keywords apply
+	require.NoError(t, schema.VisitJSON(nil))
+	err := schema.VisitJSON(map[string]any{"x-a": 1.0})
+	require.ErrorContains(t, err, `Error at "/x-a": value must be a string`)
+	err = schema.VisitJSON(map[string]any{"long": 1.0})
+	require.ErrorContains(t, err, `property name "long" doesn't match the schema from "propertyNames"`)
+
+	opt := SetSchemaDialect(SchemaDialectOpenAPI30)
+	require.NoError(t, schema.VisitJSON(nil, opt))
+	require.NoError(t, schema.VisitJSON(map[string]any{"x-a": 1.0, "long": 1.0}, opt))
+
+	opt = SetSchemaDialect(SchemaDialectDraft04)
+	err = schema.VisitJSON(nil, opt)
+	require.ErrorContains(t, err, `Value is not nullable`)
+	err = schema.VisitJSON(map[string]any{"x-a": 1.0}, opt)
+	require.ErrorContains(t, err, `value must be a string`)
+	require.NoError(t, schema.VisitJSON(map[string]any{"long": 1.0}, opt))
+
+	opt = SetSchemaDialect(SchemaDialect2020_12)
+	err = schema.VisitJSON(nil, opt)
+	require.ErrorContains(t, err, `Value is not nullable`)
+	err = schema.VisitJSON(map[string]any{"long": 1.0}, opt)
+	require.ErrorContains(t, err, `doesn't match the schema from "propertyNames"`)
+
+	// Schemas without type accept null in JSON Schema
+	schema = &Schema{Min: Float64Ptr(1)}
+	require.Error(t, schema.VisitJSON(nil))
+	require.NoError(t, schema.VisitJSON(nil, SetSchemaDialect(SchemaDialect2020_12)))
+}
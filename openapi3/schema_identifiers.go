@@ -0,0 +1,408 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// See https://json-schema.org/draft/2020-12/json-schema-core#section-8.2.2
+var schemaAnchorPattern = regexp.MustCompile(`^[A-Za-z_][-A-Za-z0-9._]*$`)
+
+func (schema *Schema) validateIdentifiers() error {
+	if id := schema.ID; id != "" {
+		u, err := url.Parse(id)
+		if err != nil {
+			return fmt.Errorf("invalid $id %q: %w", id, err)
+		}
+		if u.Fragment != "" {
+			return fmt.Errorf("invalid $id %q: must not contain a non-empty fragment", id)
+		}
+	}
+	if anchor := schema.Anchor; anchor != "" && !schemaAnchorPattern.MatchString(anchor) {
+		return fmt.Errorf("invalid $anchor %q", anchor)
+	}
+	if anchor := schema.DynamicAnchor; anchor != "" && !schemaAnchorPattern.MatchString(anchor) {
+		return fmt.Errorf("invalid $dynamicAnchor %q", anchor)
+	}
+	if ref := schema.DynamicRef; ref != "" {
+		if target := schema.dynamicRefTarget; target == nil || target.Value == nil {
+			return foundUnresolvedRef(ref)
+		}
+	}
+	return nil
+}
+
+// dynamicRefSchema returns the schema DynamicRef resolves to: when the schema it
+// statically points to has a matching $dynamicAnchor, the outermost schema resource
+// of the dynamic scope defining that anchor takes precedence.
+func (schema *Schema) dynamicRefSchema(settings *schemaValidationSettings) (*Schema, error) {
+	ref := schema.dynamicRefTarget
+	if ref == nil || ref.Value == nil {
+		return nil, foundUnresolvedRef(schema.DynamicRef)
+	}
+	target := ref.Value
+	if anchor := target.DynamicAnchor; anchor != "" && strings.HasSuffix(schema.DynamicRef, "#"+anchor) {
+		for _, resource := range settings.dynamicScope {
+			if v, ok := resource.dynamicAnchors[anchor]; ok {
+				return v, nil
+			}
+		}
+	}
+	return target, nil
+}
+
+// subschemas lists the schemas directly nested in schema.
+func (schema *Schema) subschemas() []*SchemaRef {
+	refs := make([]*SchemaRef, 0, len(schema.Properties)+len(schema.AllOf)+len(schema.AnyOf)+len(schema.OneOf))
+	for _, ref := range []*SchemaRef{
+		schema.Not, schema.If, schema.Then, schema.Else,
+		schema.Items, schema.Contains, schema.UnevaluatedItems,
+		schema.AdditionalProperties.Schema, schema.PropertyNames, schema.UnevaluatedProperties,
+		schema.ContentSchema,
+	} {
+		if ref != nil {
+			refs = append(refs, ref)
+		}
+	}
+	for _, list := range []SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf, schema.PrefixItems} {
+		refs = append(refs, list...)
+	}
+	for _, schemas := range []Schemas{schema.Defs, schema.Properties, schema.PatternProperties, schema.DependentSchemas} {
+		for _, name := range componentNames(schemas) {
+			refs = append(refs, schemas[name])
+		}
+	}
+	return refs
+}
+
+// schemaIdentifiers indexes the schemas of the loaded documents by the URIs
+// their $id, $anchor and $dynamicAnchor keywords define.
+type schemaIdentifiers struct {
+	// schemas maps absolute URIs, with a fragment for anchors, to schemas.
+	schemas map[string]identifiedSchema
+	// bases holds the base URI that the $ref of a schema reference resolves against.
+	bases map[*SchemaRef]*url.URL
+	// dynamicBases holds the base URI that the $dynamicRef of a schema resolves against.
+	dynamicBases map[*Schema]*url.URL
+	// documents lists the documents already indexed.
+	documents map[string]struct{}
+	// inspected lists the documents found not to be standalone JSON Schema documents.
+	inspected map[string]struct{}
+	// resolved lists the identified schemas whose references were resolved.
+	resolved map[*Schema]struct{}
+}
+
+type identifiedSchema struct {
+	schema       *Schema
+	doc          *T
+	documentPath *url.URL
+}
+
+func (loader *Loader) schemaIdentifiers() *schemaIdentifiers {
+	if loader.schemaIDs == nil {
+		loader.schemaIDs = &schemaIdentifiers{
+			schemas:      make(map[string]identifiedSchema),
+			bases:        make(map[*SchemaRef]*url.URL),
+			dynamicBases: make(map[*Schema]*url.URL),
+			documents:    make(map[string]struct{}),
+			inspected:    make(map[string]struct{}),
+			resolved:     make(map[*Schema]struct{}),
+		}
+	}
+	return loader.schemaIDs
+}
+
+// resolveIdentifier resolves ref against base, keeping relative file paths relative.
+func resolveIdentifier(base *url.URL, ref string) (*url.URL, error) {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse reference: %q: %w", ref, err)
+	}
+	switch {
+	case base == nil || parsed.IsAbs():
+		return parsed, nil
+	case base.IsAbs():
+		return base.ResolveReference(parsed), nil
+	case parsed.Path == "" && parsed.Host == "":
+		u := copyURI(base)
+		u.Fragment = parsed.Fragment
+		return u, nil
+	default:
+		return resolvePathWithRef(ref, base)
+	}
+}
+
+func identifierKey(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+// indexSchemaIdentifiers indexes the component schemas of doc.
+func (loader *Loader) indexSchemaIdentifiers(doc *T, documentPath *url.URL) {
+	ids := loader.schemaIdentifiers()
+	key := identifierKey(documentPath)
+	if _, ok := ids.documents[key]; ok {
+		return
+	}
+	ids.documents[key] = struct{}{}
+
+	if doc.Components == nil {
+		return
+	}
+	seen := make(map[*Schema]struct{})
+	for _, name := range componentNames(doc.Components.Schemas) {
+		ids.index(doc, documentPath, doc.Components.Schemas[name], documentPath, nil, seen)
+	}
+}
+
+// index records the identifiers that ref and its subschemas define, relative to base
+// and within the schema resource whose root is resource.
+func (ids *schemaIdentifiers) index(doc *T, documentPath *url.URL, ref *SchemaRef, base *url.URL, resource *Schema, seen map[*Schema]struct{}) {
+	if ref == nil {
+		return
+	}
+	if ref.Ref != "" {
+		ids.bases[ref] = base
+		return
+	}
+	schema := ref.Value
+	if schema == nil {
+		return
+	}
+	if _, ok := seen[schema]; ok {
+		return
+	}
+	seen[schema] = struct{}{}
+
+	identified := identifiedSchema{schema: schema, doc: doc, documentPath: documentPath}
+	if id := schema.ID; id != "" {
+		if u, err := resolveIdentifier(base, id); err == nil {
+			u.Fragment = ""
+			base, resource = u, schema
+			ids.schemas[identifierKey(u)] = identified
+		}
+	}
+	for _, anchor := range []string{schema.Anchor, schema.DynamicAnchor} {
+		if anchor == "" {
+			continue
+		}
+		u := copyURI(base)
+		if u == nil {
+			u = new(url.URL)
+		}
+		u.Fragment = anchor
+		ids.schemas[identifierKey(u)] = identified
+	}
+	if anchor := schema.DynamicAnchor; anchor != "" && resource != nil {
+		if resource.dynamicAnchors == nil {
+			resource.dynamicAnchors = make(map[string]*Schema)
+		}
+		resource.dynamicAnchors[anchor] = schema
+	}
+	if schema.DynamicRef != "" {
+		ids.dynamicBases[schema] = base
+	}
+
+	for _, sub := range schema.subschemas() {
+		ids.index(doc, documentPath, sub, base, resource, seen)
+	}
+}
+
+// indexSchemaDocument indexes the standalone JSON Schema document at location,
+// that is one declaring $id or $schema at its root, or any document that does not
+// look like an OpenAPI one when anchored is set.
+// OpenAPI documents are loaded, and so indexed, only when anchored is set.
+func (loader *Loader) indexSchemaDocument(doc *T, location *url.URL, anchored bool) error {
+	ids := loader.schemaIdentifiers()
+	key := identifierKey(location)
+	if _, ok := ids.documents[key]; ok || !loader.IsExternalRefsAllowed {
+		return nil
+	}
+	if _, ok := ids.inspected[key]; ok && !anchored {
+		return nil
+	}
+	ids.inspected[key] = struct{}{}
+
+	data, err := loader.readURL(location)
+	if err != nil {
+		// Identifiers need not be retrievable: let the regular resolution report errors.
+		return nil
+	}
+	var root map[string]any
+	if err := unmarshal(data, &root); err != nil {
+		return nil
+	}
+
+	if _, ok := root["openapi"]; ok {
+		if anchored {
+			if _, err := loader.loadFromURIInternal(location); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, hasID := root["$id"]
+	_, hasDialect := root["$schema"]
+	if !hasID && !hasDialect && !anchored {
+		return nil
+	}
+
+	if err := loader.checkDuplicateKeys(location, data); err != nil {
+		return err
+	}
+	schema := &Schema{}
+	if err := unmarshal(data, schema); err != nil {
+		return err
+	}
+	loader.recordPositions(location, data, schema)
+	if err := loader.checkUnknownFields(location, "", schema); err != nil {
+		return err
+	}
+	loader.indexSchemaResource(doc, schema, location)
+	return loader.resolveIdentifiedSchema(ids.schemas[key], nil)
+}
+
+// indexSchemaResource indexes schema as the root of the document at location.
+func (loader *Loader) indexSchemaResource(doc *T, schema *Schema, location *url.URL) {
+	ids := loader.schemaIdentifiers()
+	key := identifierKey(location)
+	ids.documents[key] = struct{}{}
+	ids.schemas[key] = identifiedSchema{schema: schema, doc: doc, documentPath: location}
+	ids.index(doc, location, &SchemaRef{Value: schema}, location, schema, make(map[*Schema]struct{}))
+
+	// Anchors of the document root resource are also relative to the document location
+	if schema.ID != "" {
+		if u, err := resolveIdentifier(location, schema.ID); err == nil && identifierKey(u) != key {
+			prefix := identifierKey(u) + "#"
+			for k, identified := range ids.schemas {
+				if anchor := strings.TrimPrefix(k, prefix); anchor != k && !strings.HasPrefix(anchor, "/") {
+					alias := copyURI(location)
+					alias.Fragment = anchor
+					ids.schemas[identifierKey(alias)] = identified
+				}
+			}
+		}
+	}
+}
+
+// resolveSchemaIdentifier resolves ref against base then looks the result up in the
+// identifier index. It returns a nil schema when ref does not point into an
+// identified schema, for the regular resolution to take over.
+func (loader *Loader) resolveSchemaIdentifier(doc *T, ref string, base *url.URL, visited []string) (*Schema, *url.URL, error) {
+	target, err := resolveIdentifier(base, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := loader.schemaIdentifiers()
+
+	fragment := target.Fragment
+	if fragment != "" && fragment[0] != '/' {
+		// A plain name fragment is an $anchor or a $dynamicAnchor
+		found, ok := ids.schemas[identifierKey(target)]
+		if !ok {
+			location := copyURI(target)
+			location.Fragment = ""
+			if err := loader.indexSchemaDocument(doc, location, true); err != nil {
+				return nil, nil, err
+			}
+			if found, ok = ids.schemas[identifierKey(target)]; !ok {
+				return nil, nil, fmt.Errorf("failed to resolve anchor %q in URI %q", fragment, ref)
+			}
+		}
+		if err := loader.resolveIdentifiedSchema(found, visited); err != nil {
+			return nil, nil, err
+		}
+		return found.schema, target, nil
+	}
+
+	location := copyURI(target)
+	location.Fragment = ""
+	found, ok := ids.schemas[identifierKey(location)]
+	if !ok {
+		if err := loader.indexSchemaDocument(doc, location, false); err != nil {
+			return nil, nil, err
+		}
+		if found, ok = ids.schemas[identifierKey(location)]; !ok {
+			return nil, nil, nil
+		}
+	}
+	if fragment == "" || fragment == "/" {
+		if err := loader.resolveIdentifiedSchema(found, visited); err != nil {
+			return nil, nil, err
+		}
+		return found.schema, target, nil
+	}
+
+	var cursor any = found.schema
+	for _, pathPart := range strings.Split(fragment[1:], "/") {
+		pathPart = unescapeRefString(pathPart)
+		if cursor, err = drillIntoField(cursor, pathPart); err != nil {
+			e := failedToResolveRefFragmentPart(ref, pathPart)
+			return nil, nil, fmt.Errorf("%s: %w", e, err)
+		}
+		if cursor == nil {
+			return nil, nil, failedToResolveRefFragmentPart(ref, pathPart)
+		}
+	}
+	var resolved *SchemaRef
+	switch c := cursor.(type) {
+	case *SchemaRef:
+		resolved = c
+	case map[string]any, bool:
+		// Schemas under unknown keywords, e.g. draft-04 "definitions"
+		data, err := json.Marshal(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		resolved = &SchemaRef{}
+		if err := json.Unmarshal(data, resolved); err != nil {
+			return nil, nil, fmt.Errorf("bad data in %q (expecting %s)", ref, readableType(resolved))
+		}
+		ids.index(found.doc, found.documentPath, resolved, location, nil, make(map[*Schema]struct{}))
+	default:
+		return nil, nil, fmt.Errorf("bad data in %q (expecting %s)", ref, readableType(resolved))
+	}
+	if err := loader.resolveSchemaRef(found.doc, resolved, found.documentPath, visited); err != nil {
+		return nil, nil, err
+	}
+	return resolved.Value, target, nil
+}
+
+// resolveIdentifiedSchema resolves the references within an identified schema, once.
+func (loader *Loader) resolveIdentifiedSchema(found identifiedSchema, visited []string) error {
+	ids := loader.schemaIdentifiers()
+	if _, ok := ids.resolved[found.schema]; ok {
+		return nil
+	}
+	ids.resolved[found.schema] = struct{}{}
+	return loader.resolveSchemaRef(found.doc, &SchemaRef{Value: found.schema}, found.documentPath, visited)
+}
+
+// resolveDynamicRef statically resolves the $dynamicRef of schema, falling back
+// to resolving it as a $ref when it does not point to an identified schema.
+func (loader *Loader) resolveDynamicRef(doc *T, schema *Schema, documentPath *url.URL, visited []string) error {
+	if schema.DynamicRef == "" || schema.dynamicRefTarget != nil {
+		return nil
+	}
+	ref := &SchemaRef{Ref: schema.DynamicRef}
+	schema.dynamicRefTarget = ref
+
+	base, ok := loader.schemaIdentifiers().dynamicBases[schema]
+	if !ok {
+		base = documentPath
+	}
+	target, _, err := loader.resolveSchemaIdentifier(doc, schema.DynamicRef, base, visited)
+	if err != nil {
+		return err
+	}
+	if target != nil {
+		ref.Value = target
+		return nil
+	}
+	return loader.resolveSchemaRef(doc, ref, documentPath, visited)
+}
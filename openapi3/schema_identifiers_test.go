@@ -0,0 +1,158 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaIdentifiers(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Pet:
+      $id: https://example.com/schemas/pet
+      type: object
+      properties:
+        tag: {$ref: tag}
+        owner: {$ref: '#/$defs/owner'}
+        name: {$ref: '#name'}
+      $defs:
+        owner: {type: string, format: email}
+        name: {$anchor: name, type: string, minLength: 1}
+    Tag:
+      $id: https://example.com/schemas/tag
+      type: string
+      enum: [a, b]
+    Named:
+      $ref: https://example.com/schemas/pet#name
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	pet := doc.Components.Schemas["Pet"].Value
+	require.Equal(t, doc.Components.Schemas["Tag"].Value, pet.Properties["tag"].Value)
+	require.Equal(t, pet.Defs["owner"].Value, pet.Properties["owner"].Value)
+	require.Equal(t, pet.Defs["name"].Value, pet.Properties["name"].Value)
+	require.Equal(t, pet.Defs["name"].Value, doc.Components.Schemas["Named"].Value)
+
+	require.NoError(t, pet.VisitJSON(map[string]any{"tag": "a", "name": "Rex"}))
+	err = pet.VisitJSON(map[string]any{"tag": "c"})
+	require.ErrorContains(t, err, `Error at "/tag": value is not one of the allowed values`)
+	err = pet.VisitJSON(map[string]any{"name": ""})
+	require.ErrorContains(t, err, `Error at "/name": minimum string length is 1`)
+
+	data, err := json.Marshal(pet)
+	require.NoError(t, err)
+	var raw map[string]any
+	err = json.Unmarshal(data, &raw)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/schemas/pet", raw["$id"])
+	require.Contains(t, raw, "$defs")
+
+	v, err := pet.JSONLookup("$defs")
+	require.NoError(t, err)
+	require.Equal(t, pet.Defs, v)
+}
+
+func TestSchemaIdentifiersInStandaloneDocument(t *testing.T) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile("testdata/identifiers/openapi.yml")
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	address := doc.Components.Schemas["Address"].Value
+	require.Equal(t, "https://json-schema.org/draft/2020-12/schema", address.Dialect)
+	require.Equal(t, address.Defs["country"].Value, doc.Components.Schemas["Country"].Value)
+	require.Equal(t, address.Defs["street"].Value, doc.Components.Schemas["Street"].Value)
+	require.Equal(t, address.Defs["street"].Value, address.Properties["street"].Value)
+
+	geo := address.Properties["geo"].Value
+	require.Equal(t, address.Defs["geo"].Value, geo)
+	require.Equal(t, geo.Defs["degrees"].Value, geo.Properties["lat"].Value)
+
+	require.NoError(t, address.VisitJSON(map[string]any{
+		"street":  "Main St",
+		"country": "FR",
+		"geo":     map[string]any{"lat": 48.8, "lon": 2.3},
+	}))
+	err = address.VisitJSON(map[string]any{"geo": map[string]any{"lat": 200.0}})
+	require.ErrorContains(t, err, `Error at "/geo/lat": number must be at most 180`)
+}
+
+func TestSchemaDynamicRef(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Tree:
+      $id: https://example.com/schemas/tree
+      $dynamicAnchor: node
+      type: object
+      properties:
+        value: {}
+        children:
+          type: array
+          items: {$dynamicRef: '#node'}
+    StrictTree:
+      $id: https://example.com/schemas/strict-tree
+      $dynamicAnchor: node
+      allOf:
+      - $ref: tree
+      unevaluatedProperties: false
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	tree := doc.Components.Schemas["Tree"].Value
+	strictTree := doc.Components.Schemas["StrictTree"].Value
+	require.Equal(t, tree, strictTree.AllOf[0].Value)
+
+	value := map[string]any{
+		"value": 1,
+		"children": []any{
+			map[string]any{"value": 2, "color": "red"},
+		},
+	}
+	require.NoError(t, tree.VisitJSON(value))
+
+	// Nested nodes are strict trees too as StrictTree is first in the dynamic scope
+	err = strictTree.VisitJSON(value)
+	require.ErrorContains(t, err, `property "color" is unevaluated`)
+
+	require.NoError(t, strictTree.VisitJSON(map[string]any{
+		"children": []any{map[string]any{"value": 2}},
+	}))
+}
+
+func TestSchemaIdentifiersValidate(t *testing.T) {
+	ctx := context.Background()
+
+	schema := NewStringSchema()
+	schema.ID = "https://example.com/schemas/name#main"
+	err := schema.Validate(ctx)
+	require.EqualError(t, err, `invalid $id "https://example.com/schemas/name#main": must not contain a non-empty fragment`)
+
+	schema = NewStringSchema()
+	schema.Anchor = "1st"
+	err = schema.Validate(ctx)
+	require.EqualError(t, err, `invalid $anchor "1st"`)
+
+	schema = NewStringSchema()
+	schema.DynamicRef = "#node"
+	err = schema.Validate(ctx)
+	require.EqualError(t, err, `found unresolved ref: "#node"`)
+}
@@ -0,0 +1,188 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The JSON Schema Test Suite harness runs the cases of
+// https://github.com/json-schema-org/JSON-Schema-Test-Suite found under
+// testdata/JSON-Schema-Test-Suite, or under the checkout that the environment
+// variable JSON_SCHEMA_TEST_SUITE points to, against Schema.VisitJSON.
+// Run with -v for a report of passed and skipped tests per keyword.
+// Files vendored with testdata/JSON-Schema-Test-Suite/update.sh are unmodified,
+// so every known failure must be listed in jsonSchemaTestSuiteSkips.
+// Cases that are not part of upstream go under testdata/JSON-Schema-Test-Suite-local
+// instead, and are reported with the prefix "local/".
+
+var jsonSchemaTestSuiteDialects = map[string]SchemaDialect{
+	"draft4":       SchemaDialectDraft04,
+	"draft2020-12": SchemaDialect2020_12,
+}
+
+// jsonSchemaTestSuiteSkips lists the files, cases and tests that are known not to pass,
+// keyed by "[local/]<dialect>/<file>[/<case description>[/<test description>]]".
+var jsonSchemaTestSuiteSkips = map[string]string{
+	"draft4/dependencies.json":                        `"dependencies" is not supported`,
+	"draft4/format.json":                              `"format" is an assertion`,
+	"draft4/refRemote.json":                           "remote references are not served",
+	"draft4/items.json/an array of schemas for items": `array form of "items" is not supported`,
+	"draft4/items.json/items and subitems":            `array form of "items" is not supported`,
+
+	"draft2020-12/content.json":                                                                  `"contentEncoding", "contentMediaType" and "contentSchema" are assertions`,
+	"draft2020-12/format.json":                                                                   `"format" is an assertion`,
+	"draft2020-12/refRemote.json":                                                                "remote references are not served",
+	"draft2020-12/vocabulary.json":                                                               `"$vocabulary" is not supported`,
+	"draft2020-12/anchor.json/Location-independent identifier":                                   refSiblingsUnsupported,
+	"draft2020-12/anchor.json/Location-independent identifier with absolute URI":                 refSiblingsUnsupported,
+	"draft2020-12/anchor.json/Location-independent identifier with base URI change in subschema": refSiblingsUnsupported,
+	"draft2020-12/dynamicRef.json/A $dynamicRef resolves to the first $dynamicAnchor still in scope that is encountered when the schema is evaluated": refSiblingsUnsupported,
+	"draft2020-12/ref.json/ref applies alongside sibling keywords":                                                                                    refSiblingsUnsupported,
+}
+
+const refSiblingsUnsupported = `keywords next to "$ref" are ignored`
+
+type jsonSchemaTestCase struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Tests       []struct {
+		Description string `json:"description"`
+		Data        any    `json:"data"`
+		Valid       bool   `json:"valid"`
+	} `json:"tests"`
+}
+
+type jsonSchemaTestReport struct {
+	passed, skipped int
+}
+
+func TestJSONSchemaTestSuite(t *testing.T) {
+	root := os.Getenv("JSON_SCHEMA_TEST_SUITE")
+	if root == "" {
+		root = "testdata/JSON-Schema-Test-Suite"
+	}
+
+	report := make(map[string]*jsonSchemaTestReport)
+	runJSONSchemaTestSuite(t, root, "", report)
+	runJSONSchemaTestSuite(t, "testdata/JSON-Schema-Test-Suite-local", "local/", report)
+
+	keywords := make([]string, 0, len(report))
+	for keyword := range report {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	var lines []string
+	for _, keyword := range keywords {
+		counts := report[keyword]
+		lines = append(lines, fmt.Sprintf("%-50s passed: %3d  skipped: %3d", keyword, counts.passed, counts.skipped))
+	}
+	t.Logf("JSON Schema Test Suite report (%s):\n%s", jsonSchemaTestSuiteVersion(root), strings.Join(lines, "\n"))
+}
+
+// runJSONSchemaTestSuite runs the files under root, naming their keywords with prefix.
+func runJSONSchemaTestSuite(t *testing.T, root, prefix string, report map[string]*jsonSchemaTestReport) {
+	dialects := make([]string, 0, len(jsonSchemaTestSuiteDialects))
+	for dialect := range jsonSchemaTestSuiteDialects {
+		dialects = append(dialects, dialect)
+	}
+	sort.Strings(dialects)
+
+	for _, dialect := range dialects {
+		files, err := filepath.Glob(filepath.Join(root, "tests", dialect, "*.json"))
+		require.NoError(t, err)
+		if prefix == "" {
+			require.NotEmpty(t, files)
+		}
+
+		for _, file := range files {
+			keyword := prefix + dialect + "/" + filepath.Base(file)
+			counts := &jsonSchemaTestReport{}
+			report[keyword] = counts
+
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			var cases []jsonSchemaTestCase
+			err = json.Unmarshal(data, &cases)
+			require.NoError(t, err)
+
+			t.Run(keyword, func(t *testing.T) {
+				for _, c := range cases {
+					c := c
+					t.Run(c.Description, func(t *testing.T) {
+						runJSONSchemaTestCase(t, root, keyword, jsonSchemaTestSuiteDialects[dialect], c, counts)
+					})
+				}
+			})
+		}
+	}
+}
+
+// jsonSchemaTestSuiteVersion tells which version of the suite the files under root are.
+func jsonSchemaTestSuiteVersion(root string) string {
+	if commit, err := os.ReadFile(filepath.Join(root, "COMMIT")); err == nil {
+		return "upstream commit " + strings.TrimSpace(string(commit))
+	}
+	return "cases picked from upstream, not the whole suite"
+}
+
+func runJSONSchemaTestCase(t *testing.T, root, keyword string, dialect SchemaDialect, c jsonSchemaTestCase, counts *jsonSchemaTestReport) {
+	for _, key := range []string{keyword, keyword + "/" + c.Description} {
+		if reason, ok := jsonSchemaTestSuiteSkips[key]; ok {
+			counts.skipped += len(c.Tests)
+			t.Skip(reason)
+		}
+	}
+
+	schema, err := loadJSONSchemaTestSchema(root, c.Schema)
+	require.NoError(t, err)
+
+	for _, test := range c.Tests {
+		if reason, ok := jsonSchemaTestSuiteSkips[keyword+"/"+c.Description+"/"+test.Description]; ok {
+			counts.skipped++
+			t.Logf("skipping %q: %s", test.Description, reason)
+			continue
+		}
+		err := schema.VisitJSON(test.Data, SetSchemaDialect(dialect), MultiErrors())
+		if test.Valid {
+			require.NoError(t, err, test.Description)
+		} else {
+			require.Error(t, err, test.Description)
+		}
+		counts.passed++
+	}
+}
+
+// loadJSONSchemaTestSchema loads schema as a standalone document, so that
+// its references and identifiers resolve as in plain JSON Schema.
+func loadJSONSchemaTestSchema(root string, schema json.RawMessage) (*Schema, error) {
+	loader := NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(ctx context.Context, loader *Loader, location *url.URL) ([]byte, error) {
+		if location.Host == "localhost:1234" {
+			return os.ReadFile(filepath.Join(root, "remotes", filepath.FromSlash(location.Path)))
+		}
+		if location.Path == "schema.json" {
+			return schema, nil
+		}
+		return nil, fmt.Errorf("unexpected location %q", location)
+	}
+
+	doc, err := loader.LoadFromData([]byte(`{
+		"openapi": "3.1.0",
+		"info": {"title": "JSON Schema Test Suite", "version": "1"},
+		"components": {"schemas": {"Schema": {"$ref": "schema.json"}}}
+	}`))
+	if err != nil {
+		return nil, err
+	}
+	return doc.Components.Schemas["Schema"].Value, nil
+}
@@ -14,7 +14,11 @@ func intoGoRegexp(re string) string {
 
 // NOTE: racey WRT [writes to schema.Pattern] vs [reads schema.Pattern then writes to compiledPatterns]
 func (schema *Schema) compilePattern(c RegexCompilerFunc) (cp RegexMatcher, err error) {
-	pattern := schema.Pattern
+	return schema.compileRegexp(c, "pattern", schema.Pattern)
+}
+
+// compileRegexp compiles pattern, found in field of schema, and caches the result.
+func (schema *Schema) compileRegexp(c RegexCompilerFunc, field, pattern string) (cp RegexMatcher, err error) {
 	if c != nil {
 		cp, err = c(pattern)
 	} else {
@@ -23,7 +27,7 @@ func (schema *Schema) compilePattern(c RegexCompilerFunc) (cp RegexMatcher, err
 	if err != nil {
 		err = &SchemaError{
 			Schema:      schema,
-			SchemaField: "pattern",
+			SchemaField: field,
 			Origin:      err,
 			Reason:      fmt.Sprintf("cannot compile pattern %q: %v", pattern, err),
 		}
@@ -33,3 +37,13 @@ func (schema *Schema) compilePattern(c RegexCompilerFunc) (cp RegexMatcher, err
 	var _ bool = compiledPatterns.CompareAndSwap(pattern, nil, cp)
 	return
 }
+
+// regexpMatcher returns the cached matcher for pattern, compiling it if needed.
+func (schema *Schema) regexpMatcher(settings *schemaValidationSettings, field, pattern string) (RegexMatcher, error) {
+	if cp, ok := compiledPatterns.Load(pattern); ok {
+		if cp, ok := cp.(RegexMatcher); ok && cp != nil {
+			return cp, nil
+		}
+	}
+	return schema.compileRegexp(settings.regexCompiler, field, pattern)
+}
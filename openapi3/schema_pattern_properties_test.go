@@ -0,0 +1,97 @@
+package openapi3
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaPatternProperties(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Translations:
+      type: object
+      properties:
+        default: {type: string}
+      patternProperties:
+        '^[a-z]{2}$':
+          $ref: '#/components/schemas/Translation'
+        '^x-':
+          type: string
+          maxLength: 3
+      propertyNames:
+        pattern: '^([a-z]{2}|default|x-.*)$'
+      additionalProperties: false
+    Translation:
+      type: string
+      minLength: 1
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	translations := doc.Components.Schemas["Translations"].Value
+	require.Equal(t, doc.Components.Schemas["Translation"].Value, translations.PatternProperties["^[a-z]{2}$"].Value)
+
+	require.NoError(t, translations.VisitJSON(map[string]any{"default": "Hello", "fr": "Bonjour", "x-a": "abc"}))
+
+	err = translations.VisitJSON(map[string]any{"fr": ""})
+	require.ErrorContains(t, err, `Error at "/fr": minimum string length is 1`)
+
+	err = translations.VisitJSON(map[string]any{"x-a": "abcd"})
+	require.ErrorContains(t, err, `Error at "/x-a": maximum string length is 3`)
+
+	err = translations.VisitJSON(map[string]any{"FR": "Bonjour"})
+	require.ErrorContains(t, err, `property name "FR" doesn't match the schema from "propertyNames"`)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "propertyNames", schemaErr.SchemaField)
+
+	err = translations.VisitJSON(map[string]any{"FR": "Bonjour"}, MultiErrors())
+	var me MultiError
+	require.ErrorAs(t, err, &me)
+	require.Len(t, me, 2)
+	require.ErrorContains(t, me[1], `property "FR" is unsupported`)
+
+	data, err := json.Marshal(translations)
+	require.NoError(t, err)
+	var raw map[string]any
+	err = json.Unmarshal(data, &raw)
+	require.NoError(t, err)
+	require.Contains(t, raw, "patternProperties")
+	require.Equal(t, map[string]any{"pattern": "^([a-z]{2}|default|x-.*)$"}, raw["propertyNames"])
+
+	v, err := translations.JSONLookup("propertyNames")
+	require.NoError(t, err)
+	require.Equal(t, translations.PropertyNames.Value, v)
+}
+
+func TestSchemaPatternPropertiesValidate(t *testing.T) {
+	schema := NewObjectSchema()
+	schema.PatternProperties = Schemas{"^[a-z": NewStringSchema().NewRef()}
+
+	err := schema.Validate(context.Background())
+	require.ErrorContains(t, err, "error parsing regexp: missing closing ]")
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "patternProperties", schemaErr.SchemaField)
+
+	err = schema.Validate(context.Background(), DisableSchemaPatternValidation())
+	require.NoError(t, err)
+
+	compiled := false
+	err = schema.Validate(context.Background(), SetRegexCompiler(func(expr string) (RegexMatcher, error) {
+		compiled = true
+		return regexp.MustCompile(`^[a-z]`), nil
+	}))
+	require.NoError(t, err)
+	require.True(t, compiled)
+}
@@ -0,0 +1,103 @@
+package openapi3
+
+// Keywords "unevaluatedItems" and "unevaluatedProperties" depend on the items and
+// properties that adjacent keywords evaluated, including those from subschemas of
+// in-place applicators (allOf, anyOf, oneOf, if/then/else, dependentSchemas and
+// $dynamicRef) that successfully validate the same instance.
+// These annotations are collected while visiting the instance, and passed up
+// from in-place subschemas that validate it.
+// See https://json-schema.org/draft/2020-12/json-schema-core#section-7.7
+// and https://json-schema.org/draft/2020-12/json-schema-core#section-11
+
+// evaluation holds the properties and items of an instance that keywords evaluated.
+// Its methods do nothing on a nil evaluation, which is used when no schema visiting
+// the instance needs these annotations.
+type evaluation struct {
+	properties map[string]struct{}
+	items      map[int]struct{}
+}
+
+func (ev *evaluation) property(k string) {
+	if ev == nil {
+		return
+	}
+	if ev.properties == nil {
+		ev.properties = make(map[string]struct{})
+	}
+	ev.properties[k] = struct{}{}
+}
+
+func (ev *evaluation) item(i int) {
+	if ev == nil {
+		return
+	}
+	if ev.items == nil {
+		ev.items = make(map[int]struct{})
+	}
+	ev.items[i] = struct{}{}
+}
+
+func (ev *evaluation) hasProperty(k string) bool {
+	if ev == nil {
+		return false
+	}
+	_, ok := ev.properties[k]
+	return ok
+}
+
+func (ev *evaluation) hasItem(i int) bool {
+	if ev == nil {
+		return false
+	}
+	_, ok := ev.items[i]
+	return ok
+}
+
+func (ev *evaluation) merge(other *evaluation) {
+	for k := range other.properties {
+		ev.property(k)
+	}
+	for i := range other.items {
+		ev.item(i)
+	}
+}
+
+// evaluate starts collecting the annotations of schema visiting an instance,
+// when schema or the schema applying it in place needs them.
+// The returned function ends it once the visit returned err, passing the
+// annotations up to the schema applying it in place if the instance is valid.
+func (schema *Schema) evaluate(settings *schemaValidationSettings) func(err error) {
+	parent, outer := settings.inPlace, settings.evaluation
+	settings.inPlace = nil
+	if parent == nil && schema.UnevaluatedProperties == nil && schema.UnevaluatedItems == nil {
+		settings.evaluation = nil
+		return func(error) { settings.evaluation = outer }
+	}
+
+	ev := &evaluation{}
+	settings.evaluation = ev
+	return func(err error) {
+		settings.evaluation = outer
+		if err == nil && parent != nil {
+			parent.merge(ev)
+		}
+	}
+}
+
+// visitJSONInPlace visits value, the instance that the schema applying schema in place
+// visits with the evaluation ev, which gets the annotations of schema if value is valid.
+func (schema *Schema) visitJSONInPlace(settings *schemaValidationSettings, value any, ev *evaluation) error {
+	settings.inPlace = ev
+	return schema.visitJSON(settings, value)
+}
+
+// isFalse reports whether schema is the boolean schema false, i.e. {"not": {}}.
+func (schema *Schema) isFalse() bool {
+	not := schema.Not
+	if not == nil || not.Value == nil || !not.Value.IsEmpty() {
+		return false
+	}
+	rest := *schema
+	rest.Not = nil
+	return rest.IsEmpty()
+}
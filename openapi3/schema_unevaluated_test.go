@@ -0,0 +1,138 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaUnevaluatedProperties(t *testing.T) {
+	spec := []byte(`
+openapi: 3.1.0
+info: {title: t, version: v}
+components:
+  schemas:
+    Base:
+      type: object
+      properties:
+        id: {type: string}
+      required: [id]
+    Pet:
+      allOf:
+      - $ref: '#/components/schemas/Base'
+      - properties:
+          name: {type: string}
+      anyOf:
+      - properties:
+          bark: {type: boolean}
+        required: [bark]
+      - properties:
+          meow: {type: boolean}
+        required: [meow]
+      if:
+        properties:
+          kind: {enum: [fish]}
+        required: [kind]
+      then:
+        properties:
+          fins: {type: integer}
+      else:
+        properties:
+          kind: {type: string}
+      unevaluatedProperties: false
+    Tagged:
+      allOf:
+      - $ref: '#/components/schemas/Base'
+      unevaluatedProperties:
+        type: string
+`)
+	loader := NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+
+	pet := doc.Components.Schemas["Pet"].Value
+	require.NoError(t, pet.VisitJSON(map[string]any{"id": "1", "name": "Rex", "bark": true}))
+	require.NoError(t, pet.VisitJSON(map[string]any{"id": "1", "meow": true, "kind": "cat"}))
+	require.NoError(t, pet.VisitJSON(map[string]any{"id": "1", "meow": true, "kind": "fish", "fins": 2}))
+
+	err = pet.VisitJSON(map[string]any{"id": "1", "bark": true, "color": "brown"})
+	require.ErrorContains(t, err, `Error at "/color": property "color" is unevaluated`)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Equal(t, "unevaluatedProperties", schemaErr.SchemaField)
+
+	// "meow" is declared in a non-matching anyOf branch, so stays unevaluated
+	err = pet.VisitJSON(map[string]any{"id": "1", "bark": true, "meow": "yes"})
+	require.ErrorContains(t, err, `property "meow" is unevaluated`)
+
+	// "fins" is only declared by "then"
+	err = pet.VisitJSON(map[string]any{"id": "1", "bark": true, "kind": "dog", "fins": 2})
+	require.ErrorContains(t, err, `property "fins" is unevaluated`)
+
+	err = pet.VisitJSON(map[string]any{"id": "1", "bark": true, "color": "brown", "size": 3}, MultiErrors())
+	var me MultiError
+	require.ErrorAs(t, err, &me)
+	require.Len(t, me, 2)
+
+	tagged := doc.Components.Schemas["Tagged"].Value
+	require.NoError(t, tagged.VisitJSON(map[string]any{"id": "1", "tag": "a"}))
+	err = tagged.VisitJSON(map[string]any{"id": "1", "tag": 1.0})
+	require.ErrorContains(t, err, `Error at "/tag": value must be a string`)
+
+	data, err := json.Marshal(tagged)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"allOf": [{"$ref": "#/components/schemas/Base"}],
+		"unevaluatedProperties": {"type": "string"}
+	}`, string(data))
+}
+
+func TestSchemaUnevaluatedItems(t *testing.T) {
+	schema := &Schema{
+		AllOf: SchemaRefs{
+			NewSchemaRef("", NewArraySchema().WithPrefixItems(NewStringSchema())),
+		},
+		Contains:         NewSchemaRef("", NewIntegerSchema()),
+		UnevaluatedItems: NewSchemaRef("", &Schema{Not: NewSchemaRef("", NewSchema())}),
+	}
+
+	require.NoError(t, schema.VisitJSON([]any{"a", 1, 2}))
+
+	err := schema.VisitJSON([]any{"a", 1, true})
+	require.ErrorContains(t, err, `Error at "/2": item at index 2 is unevaluated`)
+
+	schema.AnyOf = SchemaRefs{NewSchemaRef("", &Schema{UnevaluatedItems: NewSchemaRef("", NewSchema())})}
+	require.NoError(t, schema.VisitJSON([]any{"a", 1, true}))
+
+	v, err := schema.JSONLookup("unevaluatedItems")
+	require.NoError(t, err)
+	require.Equal(t, schema.UnevaluatedItems.Value, v)
+}
+
+func TestSchemaUnevaluatedNested(t *testing.T) {
+	schema := &Schema{}
+	err := json.Unmarshal([]byte(`{
+		"oneOf": [{
+			"allOf": [{
+				"anyOf": [
+					{"properties": {"a": {"type": "string"}}},
+					{"properties": {"b": {"type": "string"}}},
+					{"if": {"required": ["c"]}, "then": {"properties": {"c": {"type": "string"}}}}
+				]
+			}]
+		}],
+		"dependentSchemas": {"d": {"properties": {"d": {}, "e": {}}}},
+		"unevaluatedProperties": false
+	}`), schema)
+	require.NoError(t, err)
+
+	// Annotations come from every anyOf schema the value matches, at any depth
+	require.NoError(t, schema.VisitJSON(map[string]any{"a": "x", "b": "y", "c": "z"}))
+	require.NoError(t, schema.VisitJSON(map[string]any{"d": "x", "e": "y"}))
+	require.ErrorContains(t, schema.VisitJSON(map[string]any{"a": "x", "e": "y"}), `property "e" is unevaluated`)
+	// but not from those it does not match
+	require.ErrorContains(t, schema.VisitJSON(map[string]any{"a": 1, "b": "y"}), `property "a" is unevaluated`)
+}
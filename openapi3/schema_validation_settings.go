@@ -23,11 +23,21 @@ type schemaValidationSettings struct {
 	writeOnlyValidationDisabled bool
 
 	regexCompiler RegexCompilerFunc
+	dialect       SchemaDialect
 
 	onceSettingDefaults sync.Once
 	defaultsSet         func()
 
 	customizeMessageError func(err *SchemaError) string
+
+	// dynamicScope lists the schema resources with a $dynamicAnchor being visited, outermost first.
+	dynamicScope []*Schema
+
+	// evaluation collects the properties and items that the schema being visited evaluates,
+	// nil unless it or a schema applying it in place has unevaluatedProperties or unevaluatedItems.
+	evaluation *evaluation
+	// inPlace is the evaluation of the schema applying the next visited schema in place.
+	inPlace *evaluation
 }
 
 // FailFast returns schema validation errors quicker.
@@ -83,6 +93,29 @@ func SetSchemaRegexCompiler(c RegexCompilerFunc) SchemaValidationOption {
 	return func(s *schemaValidationSettings) { s.regexCompiler = c }
 }
 
+// SetSchemaDialect restricts the keywords that apply to those of the given JSON Schema dialect.
+// By default, all keywords apply.
+func SetSchemaDialect(dialect SchemaDialect) SchemaValidationOption {
+	return func(s *schemaValidationSettings) { s.dialect = dialect }
+}
+
+// probe returns settings suitable for checking whether a value matches a subschema
+// without reporting errors nor setting default values, e.g. for "contains".
+func (settings *schemaValidationSettings) probe() *schemaValidationSettings {
+	return &schemaValidationSettings{
+		failfast:                    true,
+		asreq:                       settings.asreq,
+		asrep:                       settings.asrep,
+		formatValidationEnabled:     settings.formatValidationEnabled,
+		patternValidationDisabled:   settings.patternValidationDisabled,
+		readOnlyValidationDisabled:  settings.readOnlyValidationDisabled,
+		writeOnlyValidationDisabled: settings.writeOnlyValidationDisabled,
+		regexCompiler:               settings.regexCompiler,
+		dialect:                     settings.dialect,
+		dynamicScope:                settings.dynamicScope,
+	}
+}
+
 func newSchemaValidationSettings(opts ...SchemaValidationOption) *schemaValidationSettings {
 	settings := &schemaValidationSettings{}
 	for _, opt := range opts {
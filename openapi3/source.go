@@ -0,0 +1,90 @@
+package openapi3
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Source is the YAML or JSON text of a document, which writes the document
+// back after changes with the key order, the comments, the quoting and
+// the flow or block style of the values that did not change:
+//
+//	data, err := os.ReadFile("openapi.yml")
+//	...
+//	source, err := openapi3.NewSource(data)
+//	...
+//	doc, err := loader.LoadFromData(data)
+//	...
+//	doc.Info.Version = "1.1.0"
+//	data, err = source.Marshal(doc)
+//
+// Removed keys get dropped along with their comments, added keys come after
+// the existing ones of their object. Values which changed are written in the
+// style of their surroundings, and lose their anchor.
+// Sources of JSON documents are written back as JSON, with their indentation.
+type Source struct {
+	root *sourceNode
+	json bool
+	// indent is the indentation of JSON documents, empty when compact.
+	indent string
+	// newline is set when the text ends with one.
+	newline bool
+}
+
+// NewSource returns the Source of the document data, a YAML or JSON document.
+func NewSource(data []byte) (*Source, error) {
+	root, err := parseSource(data)
+	if err != nil {
+		return nil, err
+	}
+	source := &Source{
+		root:    root,
+		newline: bytes.HasSuffix(data, []byte("\n")),
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		source.json = true
+		source.indent = jsonIndent(trimmed)
+	}
+	return source, nil
+}
+
+// Marshal returns the text of v, usually the *T loaded from the source
+// or one of its values, formatted after the source.
+// The source is left untouched, so that several versions can be written from it.
+func (source *Source) Marshal(v any) ([]byte, error) {
+	root, err := mergeSource(source.root, v)
+	if err != nil {
+		return nil, err
+	}
+	if !source.json {
+		return encodeSource(root, sourceIndent(source.root))
+	}
+
+	var buf bytes.Buffer
+	if err := appendSourceJSON(&buf, root); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	if source.indent != "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", source.indent); err != nil {
+			return nil, err
+		}
+		data = indented.Bytes()
+	}
+	if source.newline {
+		data = append(data, '\n')
+	}
+	return data, nil
+}
+
+// jsonIndent returns the indentation of the first indented line of data,
+// a JSON document, empty when data is on a single line.
+func jsonIndent(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n"))[1:] {
+		if indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]; len(indent) != 0 {
+			return string(indent)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package openapi3
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceYAML(t *testing.T) {
+	data, err := os.ReadFile("testdata/source/openapi.yml")
+	require.NoError(t, err)
+	source, err := NewSource(data)
+	require.NoError(t, err)
+	doc, err := NewLoader().LoadFromData(data)
+	require.NoError(t, err)
+
+	unchanged, err := source.Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(unchanged))
+
+	doc.Info.Version = "1.1"
+	description := "Oops"
+	doc.Paths.Value("/pets").Get.Responses.Default().Value.Description = &description
+	pet := doc.Components.Schemas["Pet"].Value
+	delete(pet.Properties, "tag")
+	pet.Properties["age"] = NewSchemaRef("", NewIntegerSchema())
+
+	changed, err := source.Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, `
+# Pet store
+openapi: 3.0.3
+info:
+  title: 'Pets'
+  version: "1.1" # Bumped by releases
+  description: |
+    Manages pets.
+    And their owners.
+servers: [{url: 'https://api.example.com'}]
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+          description: How many pets
+      responses:
+        '200':
+          description: The pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+        default:
+          description: Oops
+    post:
+      operationId: createPet
+      responses:
+        default:
+          description: An error
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+        maximum: 100 # Server-side cap
+  schemas:
+    # Pets have names
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+`[1:], string(changed))
+
+	// The source is left untouched
+	unchanged, err = source.Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, string(changed), string(unchanged))
+}
+
+func TestSourceJSON(t *testing.T) {
+	data := []byte(`{
+	"openapi": "3.0.3",
+	"info": {"version": "1.0.0", "title": "Pets"},
+	"paths": {},
+	"components": {
+		"schemas": {
+			"Pet": {"type": "object", "maxProperties": 1.50e1}
+		}
+	}
+}
+`)
+	source, err := NewSource(data)
+	require.NoError(t, err)
+	doc, err := NewLoader().LoadFromData(data)
+	require.NoError(t, err)
+
+	doc.Info.Title = "Animals"
+	doc.Info.Description = "Animals & their owners"
+	changed, err := source.Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, `{
+	"openapi": "3.0.3",
+	"info": {
+		"version": "1.0.0",
+		"title": "Animals",
+		"description": "Animals & their owners"
+	},
+	"paths": {},
+	"components": {
+		"schemas": {
+			"Pet": {
+				"type": "object",
+				"maxProperties": 1.50e1
+			}
+		}
+	}
+}
+`, string(changed))
+}
+
+func TestSourceYAML31(t *testing.T) {
+	data := []byte(`
+openapi: 3.1.0
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Empty:
+      type: array
+      items: false
+    Pet:
+      type: object
+      additionalProperties: false
+      properties:
+        name: {type: string}
+        extra: true
+    Nothing:
+      const: null
+`[1:])
+	source, err := NewSource(data)
+	require.NoError(t, err)
+	doc, err := NewLoader().LoadFromData(data)
+	require.NoError(t, err)
+
+	unchanged, err := source.Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(unchanged))
+
+	doc.Info.Version = "1.1.0"
+	doc.Components.Schemas["Pet"].Value.Description = "A pet"
+	changed, err := source.Marshal(doc)
+	require.NoError(t, err)
+	require.Equal(t, strings.NewReplacer(
+		"version: 1.0.0", "version: 1.1.0",
+		"        extra: true\n", "        extra: true\n      description: A pet\n",
+	).Replace(string(data)), string(changed))
+}
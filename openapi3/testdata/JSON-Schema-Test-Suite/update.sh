@@ -0,0 +1,20 @@
+#!/bin/bash -eux
+set -o pipefail
+
+# Vendors the tests of the dialects the harness runs, and the remotes they refer to,
+# unmodified from the given commit of https://github.com/json-schema-org/JSON-Schema-Test-Suite
+# Known failures then go to jsonSchemaTestSuiteSkips in openapi3/schema_jsonschema_suite_test.go
+
+commit=$1
+cd "$(dirname "$0")"
+
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+curl -fsSL "https://github.com/json-schema-org/JSON-Schema-Test-Suite/archive/$commit.tar.gz" | tar -xz -C "$tmp" --strip-components=1
+
+rm -rf tests remotes
+mkdir tests
+cp -R "$tmp"/tests/draft4 "$tmp"/tests/draft2020-12 tests/
+cp -R "$tmp"/remotes remotes
+cp "$tmp"/LICENSE LICENSE
+echo "$commit" >COMMIT
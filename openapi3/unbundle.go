@@ -0,0 +1,237 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// UnbundleNamer returns the path of the file a component or a path item
+// is moved to by T.Unbundle, relative to the directory of the root document
+// and separated by slashes, or "" to keep it in the root document.
+// collection is the collection of the component, such as "schemas",
+// or "paths" and "webhooks" for path items. Files ending in ".json"
+// are written as JSON, others as YAML.
+type UnbundleNamer func(collection, name string) string
+
+var unbundleInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// DefaultUnbundleNamer is the default UnbundleNamer of T.Unbundle.
+// It moves every component to a YAML file of its collection directory under
+// "components", and every path item or webhook to a YAML file under "paths"
+// or "webhooks", named after their path:
+//
+//	components/schemas/Pet.yaml
+//	paths/pets_petId.yaml
+func DefaultUnbundleNamer(collection, name string) string {
+	switch collection {
+	case "paths", "webhooks":
+		name = strings.Trim(unbundleInvalidChars.ReplaceAllString(name, "_"), "_")
+		if name == "" {
+			name = "root"
+		}
+		return collection + "/" + name + ".yaml"
+	}
+	return "components/" + collection + "/" + unbundleInvalidChars.ReplaceAllString(name, "_") + ".yaml"
+}
+
+// Unbundle splits the document into files, the inverse of InternalizeRefs.
+// It returns the contents of the files by path, root being the path of the root
+// document and namer the paths of the others, DefaultUnbundleNamer when nil:
+//
+//	files, err := doc.Unbundle("openapi.yaml", nil)
+//	...
+//	for name, data := range files {
+//		name = filepath.Join(dir, filepath.FromSlash(name))
+//		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+//			...
+//		}
+//		if err := os.WriteFile(name, data, 0o644); err != nil {
+//			...
+//		}
+//	}
+//
+// Moved components and path items are replaced by references to their file,
+// so that they keep their names, and the references to them are rewritten
+// relative to the files they appear in. The root document loads back
+// to an equivalent document with a Loader allowing external references.
+// Components that are references already are kept in the root document.
+//
+// References to other documents must be relative to the root document.
+// The document is left untouched.
+func (doc *T) Unbundle(root string, namer UnbundleNamer) (map[string][]byte, error) {
+	if namer == nil {
+		namer = DefaultUnbundleNamer
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	u := &unbundler{
+		root:  path.Clean(root),
+		moved: make(map[string]string),
+		files: make(map[string]any),
+	}
+	if components, ok := raw["components"].(map[string]any); ok {
+		for _, collection := range componentNames(components) {
+			if err := u.move(components, collection, "/components/"+collection, namer); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, collection := range []string{"paths", "webhooks"} {
+		if err := u.move(raw, collection, "/"+collection, namer); err != nil {
+			return nil, err
+		}
+	}
+
+	files := make(map[string][]byte, len(u.files)+1)
+	for _, name := range append(componentNames(u.files), u.root) {
+		value := any(raw)
+		if name != u.root {
+			value = u.files[name]
+		}
+		if data, err = marshalFile(name, u.rewrite(value, path.Dir(name))); err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+type unbundler struct {
+	root string
+	// moved maps the JSON pointers of the moved values to the paths of their file.
+	moved map[string]string
+	files map[string]any
+	// pointers holds the keys of moved, longest first.
+	pointers []string
+}
+
+// move moves the values of the collection of parent, at pointer in the root document,
+// to the files namer names.
+func (u *unbundler) move(parent map[string]any, collection, pointer string, namer UnbundleNamer) error {
+	values, ok := parent[collection].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, name := range componentNames(values) {
+		value, ok := values[name].(map[string]any)
+		if !ok || strings.HasPrefix(name, "x-") {
+			continue
+		}
+		if _, ok := value["$ref"]; ok {
+			continue
+		}
+		file := namer(collection, name)
+		if file == "" {
+			continue
+		}
+		file = path.Join(path.Dir(u.root), file)
+		if path.IsAbs(file) || file == ".." || strings.HasPrefix(file, "../") {
+			return fmt.Errorf("cannot unbundle %s %q to %q: file is outside of the root directory", collection, name, file)
+		}
+		if _, ok := u.files[file]; ok || file == u.root {
+			return fmt.Errorf("cannot unbundle %s %q to %q: file is already used", collection, name, file)
+		}
+		u.files[file] = value
+		values[name] = map[string]any{"$ref": "./" + relativePath(path.Dir(u.root), file)}
+
+		ptr := pointer + "/" + escapeRefString(name)
+		u.moved[ptr] = file
+		u.pointers = append(u.pointers, ptr)
+	}
+	sort.Slice(u.pointers, func(i, j int) bool { return len(u.pointers[i]) > len(u.pointers[j]) })
+	return nil
+}
+
+// rewrite rewrites the references of v, a value of a file of the directory dir,
+// relative to that file.
+func (u *unbundler) rewrite(v any, dir string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch key {
+			case "$ref":
+				if ref, ok := value.(string); ok {
+					v[key] = u.rewriteRef(ref, dir)
+				}
+			case "discriminator":
+				if discriminator, ok := value.(map[string]any); ok {
+					if mapping, ok := discriminator["mapping"].(map[string]any); ok {
+						for k, ref := range mapping {
+							if ref, ok := ref.(string); ok && strings.HasPrefix(ref, "#") {
+								mapping[k] = u.rewriteRef(ref, dir)
+							}
+						}
+					}
+				}
+				u.rewrite(value, dir)
+			default:
+				u.rewrite(value, dir)
+			}
+		}
+	case []any:
+		for _, value := range v {
+			u.rewrite(value, dir)
+		}
+	}
+	return v
+}
+
+func (u *unbundler) rewriteRef(ref, dir string) string {
+	rootDir := path.Dir(u.root)
+	if pointer, ok := strings.CutPrefix(ref, "#"); ok {
+		for _, moved := range u.pointers {
+			if rest, ok := strings.CutPrefix(pointer, moved); ok && (rest == "" || rest[0] == '/') {
+				ref = relativePath(dir, u.moved[moved])
+				if rest != "" {
+					ref += "#" + rest
+				}
+				return ref
+			}
+		}
+		if dir == rootDir {
+			return ref
+		}
+		return relativePath(dir, u.root) + ref
+	}
+
+	// References to other documents are relative to the root document
+	if dir == rootDir || strings.Contains(ref, "://") || strings.HasPrefix(ref, "/") {
+		return ref
+	}
+	file, fragment, found := strings.Cut(ref, "#")
+	ref = relativePath(dir, path.Join(rootDir, file))
+	if found {
+		ref += "#" + fragment
+	}
+	return ref
+}
+
+// relativePath returns the path of the file target relative to the directory dir,
+// both relative to the same directory.
+func relativePath(dir, target string) string {
+	dirs := strings.Split(path.Clean(dir), "/")
+	if dirs[0] == "." {
+		dirs = nil
+	}
+	targets := strings.Split(path.Clean(target), "/")
+	common := 0
+	for common < len(dirs) && common < len(targets)-1 && dirs[common] == targets[common] {
+		common++
+	}
+	parts := make([]string, 0, len(dirs)-common+len(targets)-common)
+	for range dirs[common:] {
+		parts = append(parts, "..")
+	}
+	return path.Join(append(parts, targets[common:]...)...)
+}
@@ -0,0 +1,150 @@
+package openapi3
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const unbundleSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets/{petId}:
+    parameters: [{$ref: '#/components/parameters/PetId'}]
+    get:
+      responses:
+        '200':
+          description: a pet
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+  /trees:
+    get:
+      responses:
+        '200':
+          description: trees
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Node'}
+components:
+  parameters:
+    PetId: {name: petId, in: path, required: true, schema: {type: string}}
+  schemas:
+    Pet:
+      oneOf: [{$ref: '#/components/schemas/Cat'}, {$ref: '#/components/schemas/Dog'}]
+      discriminator:
+        propertyName: kind
+        mapping: {cat: '#/components/schemas/Cat', dog: '#/components/schemas/Dog'}
+    Cat:
+      type: object
+      required: [kind]
+      properties:
+        kind: {type: string}
+        lives: {type: integer}
+    Dog:
+      type: object
+      required: [kind]
+      properties:
+        kind: {type: string}
+        name: {$ref: '#/components/schemas/Cat/properties/kind'}
+    Node:
+      type: object
+      properties:
+        children:
+          type: array
+          items: {$ref: '#/components/schemas/Node'}
+`
+
+func TestUnbundle(t *testing.T) {
+	loader := NewLoader()
+	doc, err := loader.LoadFromData([]byte(unbundleSpec))
+	require.NoError(t, err)
+
+	files, err := doc.Unbundle("api/openapi.yaml", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"api/components/parameters/PetId.yaml",
+		"api/components/schemas/Cat.yaml",
+		"api/components/schemas/Dog.yaml",
+		"api/components/schemas/Node.yaml",
+		"api/components/schemas/Pet.yaml",
+		"api/openapi.yaml",
+		"api/paths/pets_petId.yaml",
+		"api/paths/trees.yaml",
+	}, componentNames(files))
+	require.Equal(t, `properties:
+  children:
+    items:
+      $ref: Node.yaml
+    type: array
+type: object
+`, string(files["api/components/schemas/Node.yaml"]))
+	require.Equal(t, `discriminator:
+  mapping:
+    cat: Cat.yaml
+    dog: Dog.yaml
+  propertyName: kind
+oneOf:
+  - $ref: Cat.yaml
+  - $ref: Dog.yaml
+`, string(files["api/components/schemas/Pet.yaml"]))
+	require.Contains(t, string(files["api/components/schemas/Dog.yaml"]), `$ref: Cat.yaml#/properties/kind`)
+	require.Contains(t, string(files["api/paths/pets_petId.yaml"]), `$ref: ../components/parameters/PetId.yaml`)
+	require.Contains(t, string(files["api/openapi.yaml"]), `/pets/{petId}:
+    $ref: ./paths/pets_petId.yaml`)
+
+	// The document is left untouched
+	require.Equal(t, "#/components/schemas/Pet", doc.Paths.Value("/pets/{petId}").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Ref)
+
+	dir := t.TempDir()
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		require.NoError(t, os.WriteFile(name, data, 0o644))
+	}
+
+	loader = NewLoader()
+	loader.IsExternalRefsAllowed = true
+	unbundled, err := loader.LoadFromFile(filepath.Join(dir, "api", "openapi.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, unbundled.Validate(loader.Context))
+
+	pet := unbundled.Paths.Value("/pets/{petId}").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Value
+	require.NoError(t, pet.VisitJSON(map[string]any{"kind": "cat", "lives": 9.0}))
+	require.Error(t, pet.VisitJSON(map[string]any{"kind": "cat", "lives": "nine"}))
+	require.Error(t, pet.VisitJSON(map[string]any{"kind": "bird"}))
+	require.Equal(t, "petId", unbundled.Paths.Value("/pets/{petId}").Parameters[0].Value.Name)
+	require.Equal(t, []string{"Cat", "Dog", "Node", "Pet"}, componentNames(unbundled.Components.Schemas))
+
+	node := unbundled.Components.Schemas["Node"].Value
+	require.Equal(t, "object", node.Type.Slice()[0])
+	require.Same(t, node, node.Properties["children"].Value.Items.Value)
+}
+
+func TestUnbundleNamer(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(unbundleSpec))
+	require.NoError(t, err)
+
+	files, err := doc.Unbundle("openapi.json", func(collection, name string) string {
+		if collection != "schemas" {
+			return ""
+		}
+		return "schemas.d/" + name + ".json"
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 5)
+	require.Contains(t, string(files["openapi.json"]), `"$ref": "./schemas.d/Pet.json"`)
+	require.Contains(t, string(files["openapi.json"]), `"$ref": "#/components/parameters/PetId"`)
+	require.Contains(t, string(files["schemas.d/Pet.json"]), `"$ref": "Cat.json"`)
+
+	_, err = doc.Unbundle("openapi.yaml", func(collection, name string) string { return "all.yaml" })
+	require.EqualError(t, err, `cannot unbundle schemas "Cat" to "all.yaml": file is already used`)
+
+	_, err = doc.Unbundle("openapi.yaml", func(collection, name string) string { return "../" + name + ".yaml" })
+	require.EqualError(t, err, `cannot unbundle parameters "PetId" to "../PetId.yaml": file is outside of the root directory`)
+}
@@ -19,6 +19,8 @@ type ValidationOptions struct {
 
 type validationOptionsKey struct{}
 
+type openAPIVersionKey struct{}
+
 // AllowExtraSiblingFields called as AllowExtraSiblingFields("description") makes Validate not return an error when said field appears next to a $ref.
 func AllowExtraSiblingFields(fields ...string) ValidationOption {
 	return func(options *ValidationOptions) {
@@ -140,3 +142,27 @@ func getValidationOptions(ctx context.Context) *ValidationOptions {
 	}
 	return &ValidationOptions{}
 }
+
+// withOpenAPIVersion records the version of the document being validated,
+// for validations that differ between OpenAPI 3.0 and 3.1.
+func withOpenAPIVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, openAPIVersionKey{}, version)
+}
+
+func isOpenAPI3_1(ctx context.Context) bool {
+	return isOpenAPI3_1Version(openAPIVersion(ctx))
+}
+
+// openAPIVersion returns the version of the document being validated,
+// empty for values validated out of a document.
+func openAPIVersion(ctx context.Context) string {
+	version, _ := ctx.Value(openAPIVersionKey{}).(string)
+	return version
+}
+
+// isOpenAPI3_0 tells whether the document being validated declares a version below 3.1,
+// false for values validated out of a document.
+func isOpenAPI3_0(ctx context.Context) bool {
+	version := openAPIVersion(ctx)
+	return version != "" && !isOpenAPI3_1Version(version)
+}
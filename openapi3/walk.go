@@ -0,0 +1,543 @@
+package openapi3
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WalkKind is the kind of the nodes Walk visits.
+type WalkKind int
+
+// Kinds of nodes, named after the type of their value.
+const (
+	WalkDocument WalkKind = iota
+	WalkPathItem
+	WalkOperation
+	WalkParameter
+	WalkRequestBody
+	WalkResponse
+	WalkHeader
+	WalkMediaType
+	WalkEncoding
+	WalkSchema
+	WalkExample
+	WalkLink
+	WalkCallback
+	WalkSecurityScheme
+	WalkServer
+	WalkTag
+)
+
+var walkKindNames = [...]string{
+	WalkDocument:       "document",
+	WalkPathItem:       "path item",
+	WalkOperation:      "operation",
+	WalkParameter:      "parameter",
+	WalkRequestBody:    "request body",
+	WalkResponse:       "response",
+	WalkHeader:         "header",
+	WalkMediaType:      "media type",
+	WalkEncoding:       "encoding",
+	WalkSchema:         "schema",
+	WalkExample:        "example",
+	WalkLink:           "link",
+	WalkCallback:       "callback",
+	WalkSecurityScheme: "security scheme",
+	WalkServer:         "server",
+	WalkTag:            "tag",
+}
+
+func (kind WalkKind) String() string {
+	if kind < 0 || int(kind) >= len(walkKindNames) {
+		return "WalkKind(" + strconv.Itoa(int(kind)) + ")"
+	}
+	return walkKindNames[kind]
+}
+
+// SkipNode is returned by the Pre callbacks of a Walker to not walk
+// the children of a node, whose Post callback is not called either.
+var SkipNode = errors.New("skip this node")
+
+// WalkNode is a node of the document visited by Walk.
+type WalkNode struct {
+	Kind WalkKind
+	// Pointer is the JSON pointer of the node within the document.
+	Pointer string
+	// Ref is the reference the node is reached through, empty when it is defined in place.
+	Ref string
+	// Value is the node, a *T, *PathItem, *Operation, *Parameter, *RequestBody, *Response,
+	// *Header, *MediaType, *Encoding, *Schema, *Example, *Link, *Callback,
+	// *SecurityScheme, *Server or *Tag according to Kind.
+	Value any
+	// Parent is the node holding this one, nil for the document.
+	Parent *WalkNode
+
+	set func(any)
+}
+
+// Replace replaces the node with value, of the type of Value, in its parent.
+// Nodes reached through a reference hold value in place of the reference,
+// which is left untouched. Once replaced in a Pre callback, the children of value are walked.
+// The document cannot be replaced.
+func (node *WalkNode) Replace(value any) {
+	if node.set == nil {
+		panic("openapi3: cannot replace the document")
+	}
+	node.set(value)
+	node.Value = value
+	node.Ref = ""
+}
+
+// WalkFunc is a callback of a Walker.
+type WalkFunc func(node *WalkNode) error
+
+// Walker holds the callbacks Walk calls for each kind of node.
+type Walker struct {
+	// Pre callbacks are called for nodes before their children, Post ones after.
+	Pre, Post map[WalkKind]WalkFunc
+
+	// FollowRefs walks the children of the nodes reached through references,
+	// which are otherwise only walked where they are defined, such as in components.
+	// Values defined in other documents are only walked with FollowRefs.
+	FollowRefs bool
+}
+
+// Walk walks the nodes of doc, calling the callbacks of walker for them.
+// It walks the components first, then the paths, webhooks, servers and tags,
+// in a fixed order that is not the document order: the keys of maps are sorted
+// and operations are walked before the servers and parameters of their path item.
+// Nodes are walked once for each place they appear in, except for those holding
+// themselves, such as recursive schemas, whose children are not walked again
+// within themselves.
+// Walk stops at the first error a callback returns other than SkipNode.
+//
+//	var operations int
+//	err := openapi3.Walk(doc, &openapi3.Walker{
+//		Pre: map[openapi3.WalkKind]openapi3.WalkFunc{
+//			openapi3.WalkOperation: func(node *openapi3.WalkNode) error {
+//				operations++
+//				return openapi3.SkipNode
+//			},
+//		},
+//	})
+func Walk(doc *T, walker *Walker) error {
+	w := &walkState{Walker: walker, ancestors: make(map[any]struct{})}
+	node := &WalkNode{Kind: WalkDocument, Value: doc}
+	return w.visit(node, func() error { return w.document(node, doc) })
+}
+
+type walkState struct {
+	*Walker
+	// ancestors holds the values of the nodes being walked.
+	ancestors map[any]struct{}
+}
+
+func (w *walkState) visit(node *WalkNode, children func() error) error {
+	if pre := w.Pre[node.Kind]; pre != nil {
+		if err := pre(node); err != nil {
+			if err == SkipNode {
+				return nil
+			}
+			return err
+		}
+	}
+	if _, ok := w.ancestors[node.Value]; !ok && (node.Ref == "" || w.FollowRefs) {
+		w.ancestors[node.Value] = struct{}{}
+		err := children()
+		delete(w.ancestors, node.Value)
+		if err != nil {
+			return err
+		}
+	}
+	if post := w.Post[node.Kind]; post != nil {
+		if err := post(node); err != nil && err != SkipNode {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkNode visits value, at pointer from parent, then its children.
+func walkNode[V any](w *walkState, parent *WalkNode, kind WalkKind, pointer, ref string, value *V, set func(*V), children func(*WalkNode, *V) error) error {
+	if value == nil {
+		return nil
+	}
+	node := &WalkNode{
+		Kind:    kind,
+		Pointer: pointer,
+		Ref:     ref,
+		Value:   value,
+		Parent:  parent,
+		set:     func(v any) { set(v.(*V)) },
+	}
+	return w.visit(node, func() error { return children(node, node.Value.(*V)) })
+}
+
+func noChildren[V any](*WalkNode, *V) error { return nil }
+
+func (w *walkState) document(node *WalkNode, doc *T) error {
+	if components := doc.Components; components != nil {
+		if err := w.components(node, components); err != nil {
+			return err
+		}
+	}
+	if doc.Paths != nil {
+		if err := w.pathItems(node, "/paths", doc.Paths.Map(), doc.Paths.Set); err != nil {
+			return err
+		}
+	}
+	if err := w.pathItems(node, "/webhooks", doc.Webhooks, func(key string, v *PathItem) { doc.Webhooks[key] = v }); err != nil {
+		return err
+	}
+	if err := w.servers(node, "/servers", doc.Servers); err != nil {
+		return err
+	}
+	for i, tag := range doc.Tags {
+		i := i
+		set := func(v *Tag) { doc.Tags[i] = v }
+		if err := walkNode(w, node, WalkTag, "/tags/"+strconv.Itoa(i), "", tag, set, noChildren[Tag]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) components(parent *WalkNode, components *Components) error {
+	const pointer = "/components"
+	if err := w.schemas(parent, pointer+"/schemas", components.Schemas); err != nil {
+		return err
+	}
+	for _, name := range componentNames(components.Parameters) {
+		if err := w.parameter(parent, pointer+"/parameters/"+escapeRefString(name), components.Parameters[name]); err != nil {
+			return err
+		}
+	}
+	if err := w.headers(parent, pointer+"/headers", components.Headers); err != nil {
+		return err
+	}
+	for _, name := range componentNames(components.RequestBodies) {
+		if err := w.requestBody(parent, pointer+"/requestBodies/"+escapeRefString(name), components.RequestBodies[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range componentNames(components.Responses) {
+		if err := w.response(parent, pointer+"/responses/"+escapeRefString(name), components.Responses[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range componentNames(components.SecuritySchemes) {
+		x := components.SecuritySchemes[name]
+		if x == nil {
+			continue
+		}
+		set := func(v *SecurityScheme) { x.Ref, x.Value = "", v }
+		if err := walkNode(w, parent, WalkSecurityScheme, pointer+"/securitySchemes/"+escapeRefString(name), x.Ref, x.Value, set, noChildren[SecurityScheme]); err != nil {
+			return err
+		}
+	}
+	if err := w.examples(parent, pointer+"/examples", components.Examples); err != nil {
+		return err
+	}
+	if err := w.links(parent, pointer+"/links", components.Links); err != nil {
+		return err
+	}
+	if err := w.callbacks(parent, pointer+"/callbacks", components.Callbacks); err != nil {
+		return err
+	}
+	return w.pathItems(parent, pointer+"/pathItems", components.PathItems, func(key string, v *PathItem) { components.PathItems[key] = v })
+}
+
+func (w *walkState) pathItems(parent *WalkNode, pointer string, pathItems map[string]*PathItem, set func(string, *PathItem)) error {
+	for _, key := range componentNames(pathItems) {
+		key, pathItem := key, pathItems[key]
+		if pathItem == nil {
+			continue
+		}
+		if err := walkNode(w, parent, WalkPathItem, pointer+"/"+escapeRefString(key), pathItem.Ref, pathItem, func(v *PathItem) { set(key, v) }, w.pathItem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var walkMethods = []string{
+	http.MethodConnect,
+	http.MethodDelete,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodTrace,
+}
+
+func (w *walkState) pathItem(node *WalkNode, pathItem *PathItem) error {
+	for _, method := range walkMethods {
+		method := method
+		set := func(v *Operation) { pathItem.SetOperation(method, v) }
+		if err := walkNode(w, node, WalkOperation, node.Pointer+"/"+strings.ToLower(method), "", pathItem.GetOperation(method), set, w.operation); err != nil {
+			return err
+		}
+	}
+	if err := w.servers(node, node.Pointer+"/servers", pathItem.Servers); err != nil {
+		return err
+	}
+	return w.parameters(node, node.Pointer+"/parameters", pathItem.Parameters)
+}
+
+func (w *walkState) operation(node *WalkNode, operation *Operation) error {
+	if err := w.parameters(node, node.Pointer+"/parameters", operation.Parameters); err != nil {
+		return err
+	}
+	if err := w.requestBody(node, node.Pointer+"/requestBody", operation.RequestBody); err != nil {
+		return err
+	}
+	if operation.Responses != nil {
+		for _, code := range componentNames(operation.Responses.Map()) {
+			if err := w.response(node, node.Pointer+"/responses/"+escapeRefString(code), operation.Responses.Value(code)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := w.callbacks(node, node.Pointer+"/callbacks", operation.Callbacks); err != nil {
+		return err
+	}
+	if operation.Servers != nil {
+		return w.servers(node, node.Pointer+"/servers", *operation.Servers)
+	}
+	return nil
+}
+
+func (w *walkState) parameters(parent *WalkNode, pointer string, parameters Parameters) error {
+	for i, x := range parameters {
+		if err := w.parameter(parent, pointer+"/"+strconv.Itoa(i), x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) parameter(parent *WalkNode, pointer string, x *ParameterRef) error {
+	if x == nil {
+		return nil
+	}
+	set := func(v *Parameter) { x.Ref, x.Value = "", v }
+	return walkNode(w, parent, WalkParameter, pointer, x.Ref, x.Value, set, w.parameterChildren)
+}
+
+func (w *walkState) parameterChildren(node *WalkNode, parameter *Parameter) error {
+	if err := w.schema(node, node.Pointer+"/schema", parameter.Schema); err != nil {
+		return err
+	}
+	if err := w.examples(node, node.Pointer+"/examples", parameter.Examples); err != nil {
+		return err
+	}
+	return w.content(node, node.Pointer+"/content", parameter.Content)
+}
+
+func (w *walkState) headers(parent *WalkNode, pointer string, headers Headers) error {
+	for _, name := range componentNames(headers) {
+		x := headers[name]
+		if x == nil {
+			continue
+		}
+		set := func(v *Header) { x.Ref, x.Value = "", v }
+		children := func(node *WalkNode, header *Header) error { return w.parameterChildren(node, &header.Parameter) }
+		if err := walkNode(w, parent, WalkHeader, pointer+"/"+escapeRefString(name), x.Ref, x.Value, set, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) requestBody(parent *WalkNode, pointer string, x *RequestBodyRef) error {
+	if x == nil {
+		return nil
+	}
+	set := func(v *RequestBody) { x.Ref, x.Value = "", v }
+	children := func(node *WalkNode, requestBody *RequestBody) error {
+		return w.content(node, node.Pointer+"/content", requestBody.Content)
+	}
+	return walkNode(w, parent, WalkRequestBody, pointer, x.Ref, x.Value, set, children)
+}
+
+func (w *walkState) response(parent *WalkNode, pointer string, x *ResponseRef) error {
+	if x == nil {
+		return nil
+	}
+	set := func(v *Response) { x.Ref, x.Value = "", v }
+	children := func(node *WalkNode, response *Response) error {
+		if err := w.headers(node, node.Pointer+"/headers", response.Headers); err != nil {
+			return err
+		}
+		if err := w.content(node, node.Pointer+"/content", response.Content); err != nil {
+			return err
+		}
+		return w.links(node, node.Pointer+"/links", response.Links)
+	}
+	return walkNode(w, parent, WalkResponse, pointer, x.Ref, x.Value, set, children)
+}
+
+func (w *walkState) content(parent *WalkNode, pointer string, content Content) error {
+	for _, mime := range componentNames(content) {
+		mime := mime
+		set := func(v *MediaType) { content[mime] = v }
+		if err := walkNode(w, parent, WalkMediaType, pointer+"/"+escapeRefString(mime), "", content[mime], set, w.mediaType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) mediaType(node *WalkNode, mediaType *MediaType) error {
+	if err := w.schema(node, node.Pointer+"/schema", mediaType.Schema); err != nil {
+		return err
+	}
+	if err := w.examples(node, node.Pointer+"/examples", mediaType.Examples); err != nil {
+		return err
+	}
+	for _, name := range componentNames(mediaType.Encoding) {
+		name := name
+		set := func(v *Encoding) { mediaType.Encoding[name] = v }
+		children := func(node *WalkNode, encoding *Encoding) error {
+			return w.headers(node, node.Pointer+"/headers", encoding.Headers)
+		}
+		if err := walkNode(w, node, WalkEncoding, node.Pointer+"/encoding/"+escapeRefString(name), "", mediaType.Encoding[name], set, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) examples(parent *WalkNode, pointer string, examples Examples) error {
+	for _, name := range componentNames(examples) {
+		x := examples[name]
+		if x == nil {
+			continue
+		}
+		set := func(v *Example) { x.Ref, x.Value = "", v }
+		if err := walkNode(w, parent, WalkExample, pointer+"/"+escapeRefString(name), x.Ref, x.Value, set, noChildren[Example]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) links(parent *WalkNode, pointer string, links Links) error {
+	for _, name := range componentNames(links) {
+		x := links[name]
+		if x == nil {
+			continue
+		}
+		set := func(v *Link) { x.Ref, x.Value = "", v }
+		children := func(node *WalkNode, link *Link) error {
+			set := func(v *Server) { link.Server = v }
+			return walkNode(w, node, WalkServer, node.Pointer+"/server", "", link.Server, set, noChildren[Server])
+		}
+		if err := walkNode(w, parent, WalkLink, pointer+"/"+escapeRefString(name), x.Ref, x.Value, set, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) callbacks(parent *WalkNode, pointer string, callbacks Callbacks) error {
+	for _, name := range componentNames(callbacks) {
+		x := callbacks[name]
+		if x == nil {
+			continue
+		}
+		set := func(v *Callback) { x.Ref, x.Value = "", v }
+		children := func(node *WalkNode, callback *Callback) error {
+			return w.pathItems(node, node.Pointer, callback.Map(), callback.Set)
+		}
+		if err := walkNode(w, parent, WalkCallback, pointer+"/"+escapeRefString(name), x.Ref, x.Value, set, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) servers(parent *WalkNode, pointer string, servers Servers) error {
+	for i, server := range servers {
+		i := i
+		set := func(v *Server) { servers[i] = v }
+		if err := walkNode(w, parent, WalkServer, pointer+"/"+strconv.Itoa(i), "", server, set, noChildren[Server]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) schema(parent *WalkNode, pointer string, x *SchemaRef) error {
+	if x == nil {
+		return nil
+	}
+	set := func(v *Schema) { x.Ref, x.Value = "", v }
+	return walkNode(w, parent, WalkSchema, pointer, x.Ref, x.Value, set, w.schemaChildren)
+}
+
+func (w *walkState) schemas(parent *WalkNode, pointer string, schemas Schemas) error {
+	for _, name := range componentNames(schemas) {
+		if err := w.schema(parent, pointer+"/"+escapeRefString(name), schemas[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) schemaRefs(parent *WalkNode, pointer string, schemas SchemaRefs) error {
+	for i, x := range schemas {
+		if err := w.schema(parent, pointer+"/"+strconv.Itoa(i), x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walkState) schemaChildren(node *WalkNode, schema *Schema) error {
+	for _, x := range []struct {
+		key     string
+		schema  *SchemaRef
+		refs    SchemaRefs
+		schemas Schemas
+	}{
+		{key: "$defs", schemas: schema.Defs},
+		{key: "oneOf", refs: schema.OneOf},
+		{key: "anyOf", refs: schema.AnyOf},
+		{key: "allOf", refs: schema.AllOf},
+		{key: "not", schema: schema.Not},
+		{key: "if", schema: schema.If},
+		{key: "then", schema: schema.Then},
+		{key: "else", schema: schema.Else},
+		{key: "contentSchema", schema: schema.ContentSchema},
+		{key: "items", schema: schema.Items},
+		{key: "prefixItems", refs: schema.PrefixItems},
+		{key: "contains", schema: schema.Contains},
+		{key: "unevaluatedItems", schema: schema.UnevaluatedItems},
+		{key: "properties", schemas: schema.Properties},
+		{key: "patternProperties", schemas: schema.PatternProperties},
+		{key: "propertyNames", schema: schema.PropertyNames},
+		{key: "additionalProperties", schema: schema.AdditionalProperties.Schema},
+		{key: "dependentSchemas", schemas: schema.DependentSchemas},
+		{key: "unevaluatedProperties", schema: schema.UnevaluatedProperties},
+	} {
+		pointer := node.Pointer + "/" + x.key
+		var err error
+		switch {
+		case x.schema != nil:
+			err = w.schema(node, pointer, x.schema)
+		case x.refs != nil:
+			err = w.schemaRefs(node, pointer, x.refs)
+		case x.schemas != nil:
+			err = w.schemas(node, pointer, x.schemas)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package openapi3
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const walkSpec = `
+openapi: 3.0.3
+info: {title: Trees, version: 1.0.0}
+tags: [{name: trees}]
+paths:
+  /trees:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Node'}
+      responses:
+        '201': {$ref: '#/components/responses/Created'}
+      callbacks:
+        onGrow:
+          '{$request.body#/url}':
+            post:
+              responses:
+                '200': {description: ok}
+components:
+  responses:
+    Created:
+      description: created
+      headers:
+        Location: {schema: {type: string}}
+  schemas:
+    Node:
+      type: object
+      properties:
+        children:
+          type: array
+          items: {$ref: '#/components/schemas/Node'}
+`
+
+func walkPointers(t *testing.T, doc *T, walker *Walker) []string {
+	var pointers []string
+	pre := func(node *WalkNode) error {
+		pointer := node.Kind.String() + " " + node.Pointer
+		if node.Ref != "" {
+			pointer += " -> " + node.Ref
+		}
+		pointers = append(pointers, pointer)
+		return nil
+	}
+	walker.Pre = make(map[WalkKind]WalkFunc)
+	for kind := WalkDocument; kind <= WalkTag; kind++ {
+		walker.Pre[kind] = pre
+	}
+	require.NoError(t, Walk(doc, walker))
+	return pointers
+}
+
+func TestWalk(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(walkSpec))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"document ",
+		"schema /components/schemas/Node",
+		"schema /components/schemas/Node/properties/children",
+		"schema /components/schemas/Node/properties/children/items -> #/components/schemas/Node",
+		"response /components/responses/Created",
+		"header /components/responses/Created/headers/Location",
+		"schema /components/responses/Created/headers/Location/schema",
+		"path item /paths/~1trees",
+		"operation /paths/~1trees/post",
+		"request body /paths/~1trees/post/requestBody",
+		"media type /paths/~1trees/post/requestBody/content/application~1json",
+		"schema /paths/~1trees/post/requestBody/content/application~1json/schema -> #/components/schemas/Node",
+		"response /paths/~1trees/post/responses/201 -> #/components/responses/Created",
+		"callback /paths/~1trees/post/callbacks/onGrow",
+		"path item /paths/~1trees/post/callbacks/onGrow/{$request.body#~1url}",
+		"operation /paths/~1trees/post/callbacks/onGrow/{$request.body#~1url}/post",
+		"response /paths/~1trees/post/callbacks/onGrow/{$request.body#~1url}/post/responses/200",
+		"tag /tags/0",
+	}, walkPointers(t, doc, &Walker{}))
+
+	pointers := walkPointers(t, doc, &Walker{FollowRefs: true})
+	require.Contains(t, pointers, "header /paths/~1trees/post/responses/201/headers/Location")
+	require.Contains(t, pointers, "schema /paths/~1trees/post/requestBody/content/application~1json/schema/properties/children/items -> #/components/schemas/Node")
+	require.NotContains(t, pointers, "schema /paths/~1trees/post/requestBody/content/application~1json/schema/properties/children/items/properties/children")
+}
+
+func TestWalkSkipAndPost(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(walkSpec))
+	require.NoError(t, err)
+
+	var visited []string
+	err = Walk(doc, &Walker{
+		Pre: map[WalkKind]WalkFunc{
+			WalkOperation: func(node *WalkNode) error {
+				if node.Parent.Parent.Kind == WalkCallback {
+					return SkipNode
+				}
+				visited = append(visited, "pre "+node.Pointer)
+				return nil
+			},
+			WalkSchema: func(*WalkNode) error { return SkipNode },
+		},
+		Post: map[WalkKind]WalkFunc{
+			WalkOperation: func(node *WalkNode) error {
+				visited = append(visited, "post "+node.Pointer)
+				return nil
+			},
+			WalkResponse: func(node *WalkNode) error {
+				visited = append(visited, "post "+node.Pointer)
+				return nil
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"post /components/responses/Created",
+		"pre /paths/~1trees/post",
+		"post /paths/~1trees/post/responses/201",
+		"post /paths/~1trees/post",
+	}, visited)
+
+	errStop := errors.New("stop")
+	err = Walk(doc, &Walker{
+		Post: map[WalkKind]WalkFunc{
+			WalkSchema: func(*WalkNode) error { return errStop },
+		},
+	})
+	require.Equal(t, errStop, err)
+}
+
+func TestWalkReplace(t *testing.T) {
+	doc, err := NewLoader().LoadFromData([]byte(walkSpec))
+	require.NoError(t, err)
+	node := doc.Components.Schemas["Node"].Value
+
+	var replaced []string
+	err = Walk(doc, &Walker{
+		Pre: map[WalkKind]WalkFunc{
+			WalkSchema: func(node *WalkNode) error {
+				if node.Ref != "" && node.Parent.Kind == WalkMediaType {
+					node.Replace(&Schema{Type: &Types{TypeArray}, Items: NewSchemaRef(node.Ref, node.Value.(*Schema))})
+				}
+				return nil
+			},
+			WalkMediaType: func(node *WalkNode) error {
+				node.Replace(&MediaType{Schema: node.Value.(*MediaType).Schema})
+				return nil
+			},
+		},
+		Post: map[WalkKind]WalkFunc{
+			WalkSchema: func(node *WalkNode) error {
+				replaced = append(replaced, node.Pointer)
+				return nil
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	schema := doc.Paths.Value("/trees").Post.RequestBody.Value.Content.Get("application/json").Schema
+	require.Empty(t, schema.Ref)
+	require.Equal(t, &Types{TypeArray}, schema.Value.Type)
+	require.Equal(t, "#/components/schemas/Node", schema.Value.Items.Ref)
+	require.Same(t, node, doc.Components.Schemas["Node"].Value)
+	require.Contains(t, replaced, "/paths/~1trees/post/requestBody/content/application~1json/schema/items")
+
+	require.PanicsWithValue(t, "openapi3: cannot replace the document", func() {
+		_ = Walk(doc, &Walker{
+			Pre: map[WalkKind]WalkFunc{
+				WalkDocument: func(node *WalkNode) error {
+					node.Replace(&T{})
+					return nil
+				},
+			},
+		})
+	})
+}
@@ -0,0 +1,567 @@
+package openapi3
+
+// Documents are decoded with unmarshal. Only this file works on their yaml.v3 nodes,
+// which give the positions of values, duplicate keys, nesting depth and aliases,
+// and keep the key order, comments and styles of documents written back.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// marshalFile returns the JSON encoding of v, a value decoded from JSON,
+// when name ends in ".json", its YAML encoding otherwise.
+func marshalFile(name string, v any) ([]byte, error) {
+	if strings.HasSuffix(name, ".json") {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalPositions indexes by JSON pointer the positions of the values
+// of a YAML or JSON document read from file.
+// Positions are not indexed when data fails to parse.
+func unmarshalPositions(data []byte, file string) map[string]*Position {
+	var root yaml3.Node
+	if err := yaml3.Unmarshal(data, &root); err != nil {
+		return nil
+	}
+	positions := make(map[string]*Position)
+	indexPositions(positions, file, "", &root)
+	return positions
+}
+
+func indexPositions(positions map[string]*Position, file, pointer string, node *yaml3.Node) {
+	switch node.Kind {
+	case yaml3.DocumentNode:
+		if len(node.Content) != 0 {
+			indexPositions(positions, file, pointer, node.Content[0])
+		}
+		return
+	case yaml3.AliasNode:
+		if node.Alias != nil {
+			indexPositions(positions, file, pointer, node.Alias)
+		}
+		return
+	}
+
+	setPosition(positions, pointer, file, node)
+
+	switch node.Kind {
+	case yaml3.MappingNode:
+		var merged []*yaml3.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				if value.Kind == yaml3.SequenceNode {
+					merged = append(merged, value.Content...)
+				} else {
+					merged = append(merged, value)
+				}
+				continue
+			}
+			// Values of mappings are positioned at their key
+			child := pointer + "/" + escapeRefString(key.Value)
+			setPosition(positions, child, file, key)
+			indexPositions(positions, file, child, value)
+		}
+		// Keys defined in place override merged ones
+		for _, value := range merged {
+			indexPositions(positions, file, pointer, value)
+		}
+	case yaml3.SequenceNode:
+		for i, item := range node.Content {
+			indexPositions(positions, file, pointer+"/"+strconv.Itoa(i), item)
+		}
+	}
+}
+
+func setPosition(positions map[string]*Position, pointer, file string, node *yaml3.Node) {
+	if _, ok := positions[pointer]; !ok {
+		positions[pointer] = &Position{File: file, Line: node.Line, Column: node.Column}
+	}
+}
+
+// duplicateKeys returns the errors for the keys defined more than once
+// in the same mapping of data, a YAML or JSON document read from file.
+// Keys defined in place can still override merged ones.
+func duplicateKeys(data []byte, file string) []*DuplicateKeyError {
+	var root yaml3.Node
+	if err := yaml3.Unmarshal(data, &root); err != nil {
+		return nil
+	}
+	var errs []*DuplicateKeyError
+	var walk func(pointer string, node *yaml3.Node)
+	walk = func(pointer string, node *yaml3.Node) {
+		switch node.Kind {
+		case yaml3.DocumentNode:
+			for _, child := range node.Content {
+				walk(pointer, child)
+			}
+		case yaml3.MappingNode:
+			defined := make(map[string]*yaml3.Node, len(node.Content)/2)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if key.Tag == "!!merge" {
+					continue
+				}
+				child := pointer + "/" + escapeRefString(key.Value)
+				if previous, ok := defined[key.Value]; ok {
+					errs = append(errs, &DuplicateKeyError{
+						Location: file,
+						Pointer:  child,
+						Key:      key.Value,
+						Position: &Position{File: file, Line: key.Line, Column: key.Column},
+						Previous: &Position{File: file, Line: previous.Line, Column: previous.Column},
+					})
+				} else {
+					defined[key.Value] = key
+				}
+				walk(child, value)
+			}
+		case yaml3.SequenceNode:
+			for i, item := range node.Content {
+				walk(pointer+"/"+strconv.Itoa(i), item)
+			}
+		}
+	}
+	walk("", &root)
+	return errs
+}
+
+// measureDocument returns the nesting depth of the mappings and sequences of data,
+// a YAML or JSON document, and the number of YAML aliases decoding it expands.
+// Both are math.MaxInt64 when an alias refers to a node containing it.
+func measureDocument(data []byte) (depth, aliases int64, err error) {
+	var root yaml3.Node
+	if err := yaml3.Unmarshal(data, &root); err != nil {
+		return 0, 0, err
+	}
+	measure := documentMeasures{}.measure(&root)
+	return measure.depth, measure.aliases, nil
+}
+
+type nodeMeasure struct {
+	depth, aliases int64
+}
+
+// documentMeasures memoizes the measures of the nodes of a document,
+// so that aliases are measured once. Nodes being measured have a nil measure.
+type documentMeasures map[*yaml3.Node]*nodeMeasure
+
+func (measures documentMeasures) measure(node *yaml3.Node) nodeMeasure {
+	if m, ok := measures[node]; ok {
+		if m == nil {
+			return nodeMeasure{depth: math.MaxInt64, aliases: math.MaxInt64}
+		}
+		return *m
+	}
+	measures[node] = nil
+
+	var m nodeMeasure
+	switch node.Kind {
+	case yaml3.AliasNode:
+		if node.Alias != nil {
+			m = measures.measure(node.Alias)
+		}
+		m.aliases = addSaturated(m.aliases, 1)
+	case yaml3.DocumentNode, yaml3.MappingNode, yaml3.SequenceNode:
+		for _, child := range node.Content {
+			c := measures.measure(child)
+			if c.depth > m.depth {
+				m.depth = c.depth
+			}
+			m.aliases = addSaturated(m.aliases, c.aliases)
+		}
+		if node.Kind != yaml3.DocumentNode {
+			m.depth = addSaturated(m.depth, 1)
+		}
+	}
+	measures[node] = &m
+	return m
+}
+
+func addSaturated(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// sourceNode is the YAML representation of a document, which keeps
+// the key order, the comments and the styles of its values.
+type sourceNode = yaml3.Node
+
+func parseSource(data []byte) (*sourceNode, error) {
+	var root yaml3.Node
+	if err := yaml3.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind != yaml3.DocumentNode || len(root.Content) != 1 {
+		return nil, errors.New("empty document")
+	}
+	return &root, nil
+}
+
+// mergeSource returns the representation of v, reusing the nodes of original
+// whose values did not change. Original is left untouched.
+func mergeSource(original *sourceNode, v any) (*sourceNode, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var updated yaml3.Node
+	if err := yaml3.Unmarshal(data, &updated); err != nil {
+		return nil, err
+	}
+	merged := *original
+	merged.Content = []*yaml3.Node{mergeNode(original.Content[0], updated.Content[0])}
+	return expandDanglingAliases(&merged), nil
+}
+
+// Changed nodes lose their anchor, so that aliases to them keep their value.
+func mergeNode(original, updated *yaml3.Node) *yaml3.Node {
+	if sameValue(original, updated) {
+		return original
+	}
+	if sameRef(original, updated) {
+		return original
+	}
+	if original.Kind != updated.Kind || hasMergeKeys(original) {
+		return replaceNode(original, updated)
+	}
+	switch original.Kind {
+	case yaml3.MappingNode:
+		return mergeMapping(original, updated)
+	case yaml3.SequenceNode:
+		return mergeSequence(original, updated)
+	}
+
+	merged := *original
+	merged.Anchor = ""
+	merged.Tag, merged.Value = updated.Tag, updated.Value
+	if merged.Tag != "!!str" || strings.Contains(merged.Value, "\n") != (original.Style&(yaml3.LiteralStyle|yaml3.FoldedStyle) != 0) {
+		merged.Style = 0
+	}
+	return &merged
+}
+
+func mergeMapping(original, updated *yaml3.Node) *yaml3.Node {
+	values := make(map[string]*yaml3.Node, len(updated.Content)/2)
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		values[updated.Content[i].Value] = updated.Content[i+1]
+	}
+
+	merged := *original
+	merged.Anchor = ""
+	merged.Content = make([]*yaml3.Node, 0, len(updated.Content))
+	kept := make(map[string]struct{}, len(values))
+	for i := 0; i+1 < len(original.Content); i += 2 {
+		key := original.Content[i]
+		value, ok := values[key.Value]
+		if !ok {
+			// The key got removed
+			continue
+		}
+		kept[key.Value] = struct{}{}
+		merged.Content = append(merged.Content, key, mergeNode(original.Content[i+1], value))
+	}
+
+	// Added keys come last, in alphabetical order
+	flow := original.Style&yaml3.FlowStyle != 0
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		if _, ok := kept[updated.Content[i].Value]; !ok {
+			merged.Content = append(merged.Content, restyle(updated.Content[i], flow), restyle(updated.Content[i+1], flow))
+		}
+	}
+	return &merged
+}
+
+func mergeSequence(original, updated *yaml3.Node) *yaml3.Node {
+	merged := *original
+	merged.Anchor = ""
+	merged.Content = make([]*yaml3.Node, 0, len(updated.Content))
+	flow := original.Style&yaml3.FlowStyle != 0
+	for i, item := range updated.Content {
+		if i < len(original.Content) {
+			merged.Content = append(merged.Content, mergeNode(original.Content[i], item))
+		} else {
+			merged.Content = append(merged.Content, restyle(item, flow))
+		}
+	}
+	return &merged
+}
+
+// replaceNode returns updated styled like its surroundings, with the comments of original.
+func replaceNode(original, updated *yaml3.Node) *yaml3.Node {
+	replaced := restyle(updated, original.Style&yaml3.FlowStyle != 0)
+	replaced.HeadComment = original.HeadComment
+	replaced.LineComment = original.LineComment
+	replaced.FootComment = original.FootComment
+	return replaced
+}
+
+// restyle returns a copy of node, a value decoded from JSON,
+// in flow style if flow is set and in block style otherwise.
+func restyle(node *yaml3.Node, flow bool) *yaml3.Node {
+	restyled := *node
+	restyled.Style = 0
+	if flow && (node.Kind == yaml3.MappingNode || node.Kind == yaml3.SequenceNode) {
+		restyled.Style = yaml3.FlowStyle
+	}
+	restyled.Content = make([]*yaml3.Node, 0, len(node.Content))
+	for _, child := range node.Content {
+		restyled.Content = append(restyled.Content, restyle(child, flow))
+	}
+	return &restyled
+}
+
+// sameRef tells whether updated is a reference to what original refers to,
+// as references are encoded without their siblings.
+func sameRef(original, updated *yaml3.Node) bool {
+	if original.Kind != yaml3.MappingNode || updated.Kind != yaml3.MappingNode || len(updated.Content) != 2 ||
+		updated.Content[0].Value != "$ref" {
+		return false
+	}
+	for i := 0; i+1 < len(original.Content); i += 2 {
+		if original.Content[i].Value == "$ref" {
+			return original.Content[i+1].Value == updated.Content[1].Value
+		}
+	}
+	return false
+}
+
+func hasMergeKeys(node *yaml3.Node) bool {
+	if node.Kind != yaml3.MappingNode {
+		return false
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Tag == "!!merge" {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue tells whether original and updated, a value decoded from JSON, are equal.
+// Scalars are equal when they have the same text too, as decoding documents
+// turns for instance the version 1.0 into the string "1.0".
+func sameValue(original, updated *yaml3.Node) bool {
+	if original.Kind == yaml3.ScalarNode && updated.Kind == yaml3.ScalarNode &&
+		original.Value == updated.Value && (original.Tag == updated.Tag || updated.Tag == "!!str") {
+		return true
+	}
+	var a, b any
+	if err := original.Decode(&a); err != nil {
+		return false
+	}
+	if err := updated.Decode(&b); err != nil {
+		return false
+	}
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(normalizeKeys(a), b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// normalizeKeys turns the mappings of v with non-string keys into mappings with
+// string keys, as they are when decoded from JSON.
+func normalizeKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[key] = normalizeKeys(value)
+		}
+		return m
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalizeKeys(value)
+		}
+		return m
+	case []any:
+		s := make([]any, 0, len(v))
+		for _, value := range v {
+			s = append(s, normalizeKeys(value))
+		}
+		return s
+	}
+	return v
+}
+
+// expandDanglingAliases returns root with the aliases to anchors it no longer defines
+// replaced with a copy of the node they referred to. Nodes are copied on write.
+func expandDanglingAliases(root *yaml3.Node) *yaml3.Node {
+	anchors := make(map[string]struct{})
+	var collect func(node *yaml3.Node)
+	collect = func(node *yaml3.Node) {
+		if node.Anchor != "" {
+			anchors[node.Anchor] = struct{}{}
+		}
+		for _, child := range node.Content {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	var expand func(node *yaml3.Node) *yaml3.Node
+	expand = func(node *yaml3.Node) *yaml3.Node {
+		if node.Kind == yaml3.AliasNode {
+			if _, ok := anchors[node.Value]; ok || node.Alias == nil {
+				return node
+			}
+			expanded := *node.Alias
+			expanded.Anchor = ""
+			return expand(&expanded)
+		}
+		var content []*yaml3.Node
+		for i, child := range node.Content {
+			if expanded := expand(child); expanded != child {
+				if content == nil {
+					content = append([]*yaml3.Node(nil), node.Content...)
+				}
+				content[i] = expanded
+			}
+		}
+		if content == nil {
+			return node
+		}
+		copied := *node
+		copied.Content = content
+		return &copied
+	}
+	return expand(root)
+}
+
+// encodeSource returns the YAML encoding of root, indented by indent spaces.
+func encodeSource(root *sourceNode, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml3.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sourceIndent returns the indentation of the nested block mappings of root, 2 when it has none.
+func sourceIndent(root *sourceNode) int {
+	var indent func(node *yaml3.Node) int
+	indent = func(node *yaml3.Node) int {
+		if node.Kind == yaml3.DocumentNode {
+			return indent(node.Content[0])
+		}
+		if node.Kind != yaml3.MappingNode || node.Style&yaml3.FlowStyle != 0 {
+			return 0
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind == yaml3.MappingNode && value.Style&yaml3.FlowStyle == 0 && len(value.Content) != 0 {
+				if n := value.Content[0].Column - key.Column; n > 0 {
+					return n
+				}
+			}
+		}
+		return 0
+	}
+	if n := indent(root); n > 0 {
+		return n
+	}
+	return 2
+}
+
+// appendSourceJSON writes the compact JSON encoding of node to buf,
+// keeping the key order and the text of numbers.
+func appendSourceJSON(buf *bytes.Buffer, node *yaml3.Node) error {
+	switch node.Kind {
+	case yaml3.DocumentNode:
+		return appendSourceJSON(buf, node.Content[0])
+	case yaml3.AliasNode:
+		return appendSourceJSON(buf, node.Alias)
+	case yaml3.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := appendJSONValue(buf, node.Content[i].Value); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := appendSourceJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case yaml3.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := appendSourceJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	if (node.Tag == "!!int" || node.Tag == "!!float") && json.Valid([]byte(node.Value)) {
+		buf.WriteString(node.Value)
+		return nil
+	}
+	var v any
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	return appendJSONValue(buf, v)
+}
+
+func appendJSONValue(buf *bytes.Buffer, v any) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	// Drop the newline ending the value
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
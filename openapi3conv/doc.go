@@ -0,0 +1,2 @@
+// Package openapi3conv converts an OpenAPI v3.0 specification document to v3.1 and back.
+package openapi3conv
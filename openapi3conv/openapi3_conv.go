@@ -0,0 +1,522 @@
+package openapi3conv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Loss describes information that a conversion could not carry over.
+type Loss struct {
+	// Pointer is the JSON pointer of the dropped value in the source document.
+	Pointer string
+	// Reason describes what was dropped.
+	Reason string
+}
+
+func (loss Loss) String() string {
+	return fmt.Sprintf("%s: %s", loss.Pointer, loss.Reason)
+}
+
+// ToV31 converts an OpenAPIv3.0 spec to an OpenAPIv3.1 spec.
+//
+// Schemas see nullable turned into a "null" type, boolean exclusiveMinimum and
+// exclusiveMaximum turned into numbers and example turned into examples.
+// doc is left untouched. References of the returned document are not resolved,
+// see openapi3.Loader.ResolveRefsIn. Referenced documents are not converted.
+func ToV31(doc *openapi3.T) (*openapi3.T, error) {
+	out, err := copyDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+	c := &converter{toV31: true}
+	c.doc(out)
+	out.OpenAPI = "3.1.0"
+	return out, nil
+}
+
+// ToV30 converts an OpenAPIv3.1 spec to an OpenAPIv3.0 spec.
+//
+// It reverses the conversions of ToV31 and returns the list of values that
+// OpenAPI 3.0 cannot represent and were dropped, such as webhooks or
+// JSON Schema 2020-12 keywords. Conversion is lossless when that list is empty.
+// doc is left untouched. References of the returned document are not resolved,
+// see openapi3.Loader.ResolveRefsIn. Referenced documents are not converted.
+func ToV30(doc *openapi3.T) (*openapi3.T, []Loss, error) {
+	out, err := copyDoc(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &converter{}
+	c.doc(out)
+	out.OpenAPI = "3.0.3"
+	return out, c.losses, nil
+}
+
+func copyDoc(doc *openapi3.T) (*openapi3.T, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	out := &openapi3.T{}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type converter struct {
+	toV31  bool
+	losses []Loss
+	// pathItems are the path items components, inlined where they are referenced
+	// when converting to OpenAPI 3.0 which does not support them.
+	pathItems map[string]*openapi3.PathItem
+}
+
+func (c *converter) lose(ptr, format string, args ...any) {
+	c.losses = append(c.losses, Loss{Pointer: ptr, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (c *converter) doc(doc *openapi3.T) {
+	if !c.toV31 {
+		if len(doc.Webhooks) != 0 {
+			c.lose("/webhooks", "webhooks are not supported")
+			doc.Webhooks = nil
+		}
+		if doc.JSONSchemaDialect != "" {
+			c.lose("/jsonSchemaDialect", "jsonSchemaDialect is not supported")
+			doc.JSONSchemaDialect = ""
+		}
+		if info := doc.Info; info != nil {
+			if info.Summary != "" {
+				c.lose("/info/summary", "summary is not supported")
+				info.Summary = ""
+			}
+			if license := info.License; license != nil && license.Identifier != "" {
+				c.lose("/info/license/identifier", "identifier is not supported")
+				license.Identifier = ""
+			}
+		}
+		if doc.Paths == nil {
+			doc.Paths = openapi3.NewPaths()
+		}
+		if doc.Components != nil {
+			c.pathItems = doc.Components.PathItems
+		}
+		for path, pathItem := range doc.Paths.Map() {
+			doc.Paths.Set(path, c.inline(pathItem))
+		}
+	}
+
+	if components := doc.Components; components != nil {
+		c.components("/components", components)
+	}
+	if doc.Paths != nil {
+		paths := doc.Paths.Map()
+		for _, path := range sortedKeys(paths) {
+			c.pathItem(pointer("/paths", path), paths[path])
+		}
+	}
+	for _, name := range sortedKeys(doc.Webhooks) {
+		c.pathItem(pointer("/webhooks", name), doc.Webhooks[name])
+	}
+}
+
+func (c *converter) components(ptr string, components *openapi3.Components) {
+	for _, name := range sortedKeys(components.Schemas) {
+		c.schemaRef(pointer(ptr+"/schemas", name), components.Schemas[name])
+	}
+	for _, name := range sortedKeys(components.Parameters) {
+		if ref := components.Parameters[name]; ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.parameter(pointer(ptr+"/parameters", name), ref.Value)
+		}
+	}
+	for _, name := range sortedKeys(components.Headers) {
+		if ref := components.Headers[name]; ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.parameter(pointer(ptr+"/headers", name), &ref.Value.Parameter)
+		}
+	}
+	for _, name := range sortedKeys(components.RequestBodies) {
+		if ref := components.RequestBodies[name]; ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.content(pointer(ptr+"/requestBodies", name)+"/content", ref.Value.Content)
+		}
+	}
+	for _, name := range sortedKeys(components.Responses) {
+		if ref := components.Responses[name]; ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.response(pointer(ptr+"/responses", name), ref.Value)
+		}
+	}
+	for _, name := range sortedKeys(components.Callbacks) {
+		if ref := components.Callbacks[name]; ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.callback(pointer(ptr+"/callbacks", name), ref.Value)
+		}
+	}
+	if len(components.PathItems) != 0 && !c.toV31 {
+		c.lose(ptr+"/pathItems", "path items components are not supported")
+		components.PathItems = nil
+	}
+	for _, name := range sortedKeys(components.PathItems) {
+		c.pathItem(pointer(ptr+"/pathItems", name), components.PathItems[name])
+	}
+}
+
+func (c *converter) pathItem(ptr string, pathItem *openapi3.PathItem) {
+	if pathItem == nil || pathItem.Ref != "" {
+		return
+	}
+	c.parameters(ptr+"/parameters", pathItem.Parameters)
+	operations := pathItem.Operations()
+	for _, method := range sortedKeys(operations) {
+		c.operation(ptr+"/"+strings.ToLower(method), operations[method])
+	}
+}
+
+func (c *converter) operation(ptr string, operation *openapi3.Operation) {
+	c.parameters(ptr+"/parameters", operation.Parameters)
+	if ref := operation.RequestBody; ref != nil && ref.Ref == "" && ref.Value != nil {
+		c.content(ptr+"/requestBody/content", ref.Value.Content)
+	}
+	if operation.Responses != nil {
+		responses := operation.Responses.Map()
+		for _, code := range sortedKeys(responses) {
+			if ref := responses[code]; ref != nil && ref.Ref == "" && ref.Value != nil {
+				c.response(pointer(ptr+"/responses", code), ref.Value)
+			}
+		}
+	}
+	for _, name := range sortedKeys(operation.Callbacks) {
+		if ref := operation.Callbacks[name]; ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.callback(pointer(ptr+"/callbacks", name), ref.Value)
+		}
+	}
+}
+
+func (c *converter) callback(ptr string, callback *openapi3.Callback) {
+	pathItems := callback.Map()
+	for _, expr := range sortedKeys(pathItems) {
+		pathItem := c.inline(pathItems[expr])
+		callback.Set(expr, pathItem)
+		c.pathItem(pointer(ptr, expr), pathItem)
+	}
+}
+
+// inline returns a copy of the path item component pathItem refers to, or pathItem.
+func (c *converter) inline(pathItem *openapi3.PathItem) *openapi3.PathItem {
+	target := pathItem
+	// Components can refer to other ones, but not in cycles
+	for i := 0; i <= len(c.pathItems) && target != nil; i++ {
+		name, ok := strings.CutPrefix(target.Ref, "#/components/pathItems/")
+		if !ok {
+			break
+		}
+		target = c.pathItems[unescapePointer(name)]
+	}
+	if target == nil || target == pathItem || target.Ref != "" {
+		return pathItem
+	}
+	data, err := json.Marshal(target)
+	if err != nil {
+		return pathItem
+	}
+	inlined := &openapi3.PathItem{}
+	if err := json.Unmarshal(data, inlined); err != nil {
+		return pathItem
+	}
+	return inlined
+}
+
+func (c *converter) parameters(ptr string, parameters openapi3.Parameters) {
+	for i, ref := range parameters {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.parameter(fmt.Sprintf("%s/%d", ptr, i), ref.Value)
+		}
+	}
+}
+
+func (c *converter) parameter(ptr string, parameter *openapi3.Parameter) {
+	c.schemaRef(ptr+"/schema", parameter.Schema)
+	c.content(ptr+"/content", parameter.Content)
+}
+
+func (c *converter) response(ptr string, response *openapi3.Response) {
+	c.headers(ptr+"/headers", response.Headers)
+	c.content(ptr+"/content", response.Content)
+}
+
+func (c *converter) headers(ptr string, headers openapi3.Headers) {
+	for _, name := range sortedKeys(headers) {
+		if ref := headers[name]; ref != nil && ref.Ref == "" && ref.Value != nil {
+			c.parameter(pointer(ptr, name), &ref.Value.Parameter)
+		}
+	}
+}
+
+func (c *converter) content(ptr string, content openapi3.Content) {
+	for _, mime := range sortedKeys(content) {
+		mediaType := content[mime]
+		if mediaType == nil {
+			continue
+		}
+		c.schemaRef(pointer(ptr, mime)+"/schema", mediaType.Schema)
+		for _, name := range sortedKeys(mediaType.Encoding) {
+			if encoding := mediaType.Encoding[name]; encoding != nil {
+				c.headers(pointer(pointer(ptr, mime)+"/encoding", name)+"/headers", encoding.Headers)
+			}
+		}
+	}
+}
+
+func (c *converter) schemaRef(ptr string, ref *openapi3.SchemaRef) {
+	// Referenced schemas are converted where they are defined
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	if c.toV31 {
+		c.schemaToV31(ptr, ref.Value)
+	} else {
+		c.schemaToV30(ptr, ref.Value)
+	}
+}
+
+func (c *converter) schemaRefs(ptr string, refs openapi3.SchemaRefs) {
+	for i, ref := range refs {
+		c.schemaRef(fmt.Sprintf("%s/%d", ptr, i), ref)
+	}
+}
+
+func (c *converter) schemas(ptr string, schemas openapi3.Schemas) {
+	for _, name := range sortedKeys(schemas) {
+		c.schemaRef(pointer(ptr, name), schemas[name])
+	}
+}
+
+// subschemas converts the subschemas that both OpenAPI 3.0 and 3.1 support.
+func (c *converter) subschemas(ptr string, schema *openapi3.Schema) {
+	c.schemaRefs(ptr+"/oneOf", schema.OneOf)
+	c.schemaRefs(ptr+"/anyOf", schema.AnyOf)
+	c.schemaRefs(ptr+"/allOf", schema.AllOf)
+	c.schemaRef(ptr+"/not", schema.Not)
+	c.schemaRef(ptr+"/items", schema.Items)
+	c.schemas(ptr+"/properties", schema.Properties)
+	c.schemaRef(ptr+"/additionalProperties", schema.AdditionalProperties.Schema)
+}
+
+func (c *converter) schemaToV31(ptr string, schema *openapi3.Schema) {
+	if schema.Nullable && schema.Type == nil && len(schema.Enum) == 0 {
+		// Such as nullable: true with allOf: [$ref], which only a type or an enum can make nullable
+		schema.Nullable = false
+		c.schemaToV31(ptr, schema)
+		inner := *schema
+		*schema = openapi3.Schema{AnyOf: openapi3.SchemaRefs{
+			openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeNull}}),
+			openapi3.NewSchemaRef("", &inner),
+		}}
+		return
+	}
+	if schema.Nullable {
+		schema.Nullable = false
+		if schema.Type != nil && !schema.Type.Includes(openapi3.TypeNull) {
+			types := append(schema.Type.Slice(), openapi3.TypeNull)
+			schema.Type = (*openapi3.Types)(&types)
+		}
+		if len(schema.Enum) != 0 && !contains(schema.Enum, nil) {
+			schema.Enum = append(schema.Enum, nil)
+		}
+	}
+
+	if schema.ExclusiveMin.IsTrue {
+		schema.ExclusiveMin = openapi3.ExclusiveBound{Value: schema.Min}
+		schema.Min = nil
+	}
+	if schema.ExclusiveMax.IsTrue {
+		schema.ExclusiveMax = openapi3.ExclusiveBound{Value: schema.Max}
+		schema.Max = nil
+	}
+
+	if schema.Example != nil {
+		schema.Examples = append([]any{schema.Example}, schema.Examples...)
+		schema.Example = nil
+	}
+
+	c.subschemas(ptr, schema)
+	c.schemas(ptr+"/$defs", schema.Defs)
+	c.schemaRefs(ptr+"/prefixItems", schema.PrefixItems)
+	for _, sub := range []struct {
+		field string
+		ref   *openapi3.SchemaRef
+	}{
+		{"if", schema.If},
+		{"then", schema.Then},
+		{"else", schema.Else},
+		{"contains", schema.Contains},
+		{"unevaluatedItems", schema.UnevaluatedItems},
+		{"propertyNames", schema.PropertyNames},
+		{"unevaluatedProperties", schema.UnevaluatedProperties},
+		{"contentSchema", schema.ContentSchema},
+	} {
+		c.schemaRef(ptr+"/"+sub.field, sub.ref)
+	}
+	c.schemas(ptr+"/patternProperties", schema.PatternProperties)
+	c.schemas(ptr+"/dependentSchemas", schema.DependentSchemas)
+}
+
+func (c *converter) schemaToV30(ptr string, schema *openapi3.Schema) {
+	// OpenAPI 3.0 has no boolean schemas: true becomes {} and false {"not": {}}
+	schema.WithoutBoolean()
+
+	if schema.Type != nil && schema.Type.Includes(openapi3.TypeNull) {
+		var types []string
+		for _, typ := range schema.Type.Slice() {
+			if typ != openapi3.TypeNull {
+				types = append(types, typ)
+			}
+		}
+		schema.Nullable = true
+		schema.Type = nil
+		if len(types) != 0 {
+			schema.Type = (*openapi3.Types)(&types)
+		} else if len(schema.Enum) == 0 {
+			// Only null is allowed
+			schema.Enum = []any{nil}
+		}
+		if len(schema.Enum) > 1 {
+			// nullable already allows null
+			enum := schema.Enum[:0]
+			for _, v := range schema.Enum {
+				if v != nil {
+					enum = append(enum, v)
+				}
+			}
+			schema.Enum = enum
+		}
+	}
+	if types := schema.Type.Slice(); len(types) > 1 {
+		anyOf := make(openapi3.SchemaRefs, 0, len(types))
+		for _, typ := range types {
+			anyOf = append(anyOf, openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{typ}}))
+		}
+		schema.Type = nil
+		if len(schema.AnyOf) == 0 {
+			schema.AnyOf = anyOf
+		} else {
+			schema.AllOf = append(schema.AllOf, openapi3.NewSchemaRef("", &openapi3.Schema{AnyOf: anyOf}))
+		}
+	}
+
+	if v := schema.ExclusiveMin.Value; v != nil {
+		schema.ExclusiveMin = openapi3.ExclusiveBound{}
+		if schema.Min == nil || *v >= *schema.Min {
+			schema.Min = v
+			schema.ExclusiveMin.IsTrue = true
+		}
+	}
+	if v := schema.ExclusiveMax.Value; v != nil {
+		schema.ExclusiveMax = openapi3.ExclusiveBound{}
+		if schema.Max == nil || *v <= *schema.Max {
+			schema.Max = v
+			schema.ExclusiveMax.IsTrue = true
+		}
+	}
+
+	if examples := schema.Examples; len(examples) != 0 {
+		if schema.Example == nil {
+			schema.Example, examples = examples[0], examples[1:]
+		}
+		if len(examples) != 0 {
+			c.lose(ptr+"/examples", "only one example is supported, %d dropped", len(examples))
+		}
+		schema.Examples = nil
+	}
+
+	if x := schema.Const; schema.HasConst() {
+		switch {
+		case len(schema.Enum) == 0 || contains(schema.Enum, x):
+			schema.Enum = []any{x}
+			if x == nil {
+				schema.Nullable = true
+			}
+		default:
+			c.lose(ptr+"/const", "const is not one of the enum values")
+		}
+		schema.WithoutConst()
+	}
+
+	if schema.ContentEncoding == "base64" && schema.Format == "" && schema.Type.Is(openapi3.TypeString) &&
+		schema.ContentMediaType == "" && schema.ContentSchema == nil {
+		schema.ContentEncoding = ""
+		schema.Format = "byte"
+	}
+
+	c.subschemas(ptr, schema)
+
+	for _, keyword := range []struct {
+		name string
+		set  bool
+		drop func()
+	}{
+		{"$schema", schema.Dialect != "", func() { schema.Dialect = "" }},
+		{"$id", schema.ID != "", func() { schema.ID = "" }},
+		{"$anchor", schema.Anchor != "", func() { schema.Anchor = "" }},
+		{"$dynamicAnchor", schema.DynamicAnchor != "", func() { schema.DynamicAnchor = "" }},
+		{"$dynamicRef", schema.DynamicRef != "", func() { schema.DynamicRef = "" }},
+		{"$defs", len(schema.Defs) != 0, func() { schema.Defs = nil }},
+		{"if", schema.If != nil, func() { schema.If = nil }},
+		{"then", schema.Then != nil, func() { schema.Then = nil }},
+		{"else", schema.Else != nil, func() { schema.Else = nil }},
+		{"prefixItems", len(schema.PrefixItems) != 0, func() {
+			// "items" only applies past "prefixItems" so would now constrain all items
+			schema.PrefixItems, schema.Items = nil, openapi3.NewSchemaRef("", openapi3.NewSchema())
+		}},
+		{"contains", schema.Contains != nil, func() { schema.Contains = nil }},
+		{"minContains", schema.MinContains != nil, func() { schema.MinContains = nil }},
+		{"maxContains", schema.MaxContains != nil, func() { schema.MaxContains = nil }},
+		{"unevaluatedItems", schema.UnevaluatedItems != nil, func() { schema.UnevaluatedItems = nil }},
+		{"patternProperties", len(schema.PatternProperties) != 0, func() { schema.PatternProperties = nil }},
+		{"propertyNames", schema.PropertyNames != nil, func() { schema.PropertyNames = nil }},
+		{"dependentRequired", len(schema.DependentRequired) != 0, func() { schema.DependentRequired = nil }},
+		{"dependentSchemas", len(schema.DependentSchemas) != 0, func() { schema.DependentSchemas = nil }},
+		{"unevaluatedProperties", schema.UnevaluatedProperties != nil, func() { schema.UnevaluatedProperties = nil }},
+		{"contentEncoding", schema.ContentEncoding != "", func() { schema.ContentEncoding = "" }},
+		{"contentMediaType", schema.ContentMediaType != "", func() { schema.ContentMediaType = "" }},
+		{"contentSchema", schema.ContentSchema != nil, func() { schema.ContentSchema = nil }},
+	} {
+		if keyword.set {
+			c.lose(ptr+"/"+keyword.name, "%s is not supported", keyword.name)
+			keyword.drop()
+		}
+	}
+}
+
+func contains(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointer appends the escaped reference token to the JSON pointer ptr.
+func pointer(ptr, token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return ptr + "/" + token
+}
+
+func unescapePointer(token string) string {
+	token = strings.ReplaceAll(token, "~1", "/")
+	return strings.ReplaceAll(token, "~0", "~")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,299 @@
+package openapi3conv
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const exampleV30 = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      parameters:
+      - name: limit
+        in: query
+        schema: {type: integer, minimum: 0, exclusiveMinimum: true, example: 10}
+      responses:
+        '200':
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, example: Rex}
+        tag: {type: string, nullable: true}
+        size: {type: string, enum: [S, L], nullable: true}
+        weight: {type: number, maximum: 100, exclusiveMaximum: true}
+`
+
+const exampleV31 = `
+openapi: 3.1.0
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      parameters:
+      - name: limit
+        in: query
+        schema: {type: integer, exclusiveMinimum: 0, examples: [10]}
+      responses:
+        '200':
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, examples: [Rex]}
+        tag: {type: [string, 'null']}
+        size: {type: [string, 'null'], enum: [S, L, null]}
+        weight: {type: number, exclusiveMaximum: 100}
+`
+
+func load(t *testing.T, spec string) *openapi3.T {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	err = doc.Validate(loader.Context)
+	require.NoError(t, err)
+	return doc
+}
+
+func requireSameDoc(t *testing.T, expected, actual *openapi3.T) {
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
+
+func TestConvOpenAPIV30ToV31(t *testing.T) {
+	doc30 := load(t, exampleV30)
+
+	doc31, err := ToV31(doc30)
+	require.NoError(t, err)
+	requireSameDoc(t, load(t, exampleV31), doc31)
+
+	// The source document is left untouched
+	require.True(t, doc30.Components.Schemas["Pet"].Value.Properties["tag"].Value.Nullable)
+
+	err = openapi3.NewLoader().ResolveRefsIn(doc31, nil)
+	require.NoError(t, err)
+	err = doc31.Validate(context.Background())
+	require.NoError(t, err)
+}
+
+func TestConvOpenAPIV31ToV30(t *testing.T) {
+	doc30, losses, err := ToV30(load(t, exampleV31))
+	require.NoError(t, err)
+	require.Empty(t, losses)
+	requireSameDoc(t, load(t, exampleV30), doc30)
+}
+
+func TestConvOpenAPIV31ToV30Losses(t *testing.T) {
+	doc31 := load(t, `
+openapi: 3.1.0
+info: {title: Pets, version: 1.0.0, summary: All about pets}
+webhooks:
+  newPet:
+    post:
+      responses:
+        '200': {description: ok}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        kind: {const: dog}
+        none: {const: null}
+        id: {type: [integer, string]}
+        tags:
+          type: array
+          prefixItems: [{type: string}]
+          examples: [[a], [b]]
+        photo: {type: string, contentEncoding: base64}
+      patternProperties:
+        '^x-': {}
+`)
+
+	doc30, losses, err := ToV30(doc31)
+	require.NoError(t, err)
+	require.Equal(t, []Loss{
+		{Pointer: "/webhooks", Reason: "webhooks are not supported"},
+		{Pointer: "/info/summary", Reason: "summary is not supported"},
+		{Pointer: "/components/schemas/Pet/properties/tags/examples", Reason: "only one example is supported, 1 dropped"},
+		{Pointer: "/components/schemas/Pet/properties/tags/prefixItems", Reason: "prefixItems is not supported"},
+		{Pointer: "/components/schemas/Pet/patternProperties", Reason: "patternProperties is not supported"},
+	}, losses)
+	require.Equal(t, "/webhooks: webhooks are not supported", losses[0].String())
+
+	err = openapi3.NewLoader().ResolveRefsIn(doc30, nil)
+	require.NoError(t, err)
+	err = doc30.Validate(context.Background())
+	require.NoError(t, err)
+
+	data, err := json.Marshal(doc30.Components.Schemas["Pet"])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"kind": {"enum": ["dog"]},
+			"none": {"enum": [null], "nullable": true},
+			"id": {"anyOf": [{"type": "integer"}, {"type": "string"}]},
+			"tags": {"type": "array", "items": {}, "example": ["a"]},
+			"photo": {"type": "string", "format": "byte"}
+		}
+	}`, string(data))
+}
+
+func TestConvOpenAPIV31ToV30BooleanSchemas(t *testing.T) {
+	doc31 := load(t, `
+openapi: 3.1.0
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        any: true
+        none: false
+        anyItems: {type: array, items: true}
+        noItems: {type: array, items: false}
+        anyMap: {type: object, additionalProperties: true}
+        noMap: {type: object, additionalProperties: false}
+        map:
+          type: object
+          additionalProperties:
+            properties:
+              any: true
+              none: false
+`)
+	data, err := json.Marshal(doc31.Components.Schemas["Pet"].Value.Properties["none"])
+	require.NoError(t, err)
+	require.Equal(t, `false`, string(data))
+
+	doc30, losses, err := ToV30(doc31)
+	require.NoError(t, err)
+	require.Empty(t, losses)
+
+	err = openapi3.NewLoader().ResolveRefsIn(doc30, nil)
+	require.NoError(t, err)
+	err = doc30.Validate(context.Background())
+	require.NoError(t, err)
+
+	data, err = json.Marshal(doc30.Components.Schemas["Pet"])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"any": {},
+			"none": {"not": {}},
+			"anyItems": {"type": "array", "items": {}},
+			"noItems": {"type": "array", "items": {"not": {}}},
+			"anyMap": {"type": "object", "additionalProperties": true},
+			"noMap": {"type": "object", "additionalProperties": false},
+			"map": {
+				"type": "object",
+				"additionalProperties": {
+					"properties": {
+						"any": {},
+						"none": {"not": {}}
+					}
+				}
+			}
+		}
+	}`, string(data))
+}
+
+func TestConvOpenAPIV30ToV31UntypedNullable(t *testing.T) {
+	doc30 := load(t, `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pet: {type: object}
+    MaybePet:
+      nullable: true
+      allOf: [{$ref: '#/components/schemas/Pet'}]
+`)
+	require.NoError(t, doc30.Components.Schemas["MaybePet"].Value.VisitJSON(nil))
+
+	doc31, err := ToV31(doc30)
+	require.NoError(t, err)
+	data, err := json.Marshal(doc31.Components.Schemas["MaybePet"])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"anyOf": [{"type": "null"}, {"allOf": [{"$ref": "#/components/schemas/Pet"}]}]}`, string(data))
+
+	err = openapi3.NewLoader().ResolveRefsIn(doc31, nil)
+	require.NoError(t, err)
+	maybePet := doc31.Components.Schemas["MaybePet"].Value
+	require.NoError(t, maybePet.VisitJSON(nil))
+	require.NoError(t, maybePet.VisitJSON(map[string]any{}))
+	require.Error(t, maybePet.VisitJSON(1.0))
+}
+
+func TestConvOpenAPIV31ToV30PathItems(t *testing.T) {
+	doc31 := load(t, `
+openapi: 3.1.0
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    $ref: '#/components/pathItems/Pets'
+  /animals:
+    $ref: '#/components/pathItems/Animals'
+components:
+  pathItems:
+    Animals:
+      $ref: '#/components/pathItems/Pets'
+    Pets:
+      get:
+        responses:
+          '200':
+            description: The pets
+            content:
+              application/json:
+                schema: {type: [array, 'null'], items: {type: string}}
+`)
+
+	doc30, losses, err := ToV30(doc31)
+	require.NoError(t, err)
+	require.Equal(t, []Loss{
+		{Pointer: "/components/pathItems", Reason: "path items components are not supported"},
+	}, losses)
+
+	data, err := json.Marshal(doc30)
+	require.NoError(t, err)
+	doc30, err = openapi3.NewLoader().LoadFromData(data)
+	require.NoError(t, err)
+	err = doc30.Validate(context.Background())
+	require.NoError(t, err)
+
+	for _, path := range []string{"/pets", "/animals"} {
+		pathItem := doc30.Paths.Value(path)
+		require.Empty(t, pathItem.Ref)
+		schema := pathItem.Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Value
+		require.True(t, schema.Nullable)
+	}
+}
@@ -0,0 +1,390 @@
+package openapi3diff
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Kind is the kind of a change.
+type Kind string
+
+const (
+	Added   = Kind("added")
+	Removed = Kind("removed")
+	Changed = Kind("changed")
+)
+
+// Direction tells whether a change is in what clients send or receive.
+type Direction string
+
+const (
+	Request  = Direction("request")
+	Response = Direction("response")
+)
+
+// Change is a change between two documents.
+type Change struct {
+	Kind Kind
+	// Pointer is the JSON pointer of the changed value in the revision,
+	// or in the base for removed values, as looked up by openapi3.T.Lookup.
+	Pointer string
+	// Path is the path of the changed path item or operation.
+	Path string
+	// Method is the method of the changed operation, empty for path items.
+	Method string
+	// Direction is the direction of changes within operations, empty for path items and operations.
+	Direction Direction
+	// Message describes the change.
+	Message string
+	// Breaking is set for changes which can break existing clients.
+	Breaking bool
+}
+
+// Operation returns the method and path of the operation of the change, or its path.
+func (change *Change) Operation() string {
+	if change.Method == "" {
+		return change.Path
+	}
+	return change.Method + " " + change.Path
+}
+
+// Report is the list of changes between two documents.
+type Report struct {
+	Changes []Change
+}
+
+// Breaking returns the breaking changes of the report.
+func (report *Report) Breaking() []Change {
+	var changes []Change
+	for _, change := range report.Changes {
+		if change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// HasBreaking tells whether the report holds breaking changes.
+func (report *Report) HasBreaking() bool {
+	return len(report.Breaking()) != 0
+}
+
+// Diff returns the changes of the paths, operations, parameters, request bodies,
+// responses and schemas of revision from base, in a stable order.
+// References are compared through their values, which must be resolved.
+//
+// Changes are classified as breaking according to their direction:
+// tightening what requests accept, such as removing a value of an enum
+// or lowering a maxLength, is breaking whereas doing so in responses is not,
+// and the other way around for loosening.
+func Diff(base, revision *openapi3.T) *Report {
+	d := &differ{}
+	d.paths(pathsMap(base.Paths), pathsMap(revision.Paths))
+	return &Report{Changes: d.changes}
+}
+
+type differ struct {
+	changes []Change
+	// comparing holds the schemas being compared, for recursive ones.
+	comparing map[schemaPair]bool
+}
+
+// scope is where changes happen.
+type scope struct {
+	path, method string
+	direction    Direction
+	// label describes the changed value, such as `request body "application/json"`.
+	label string
+	// negated is set within the schemas of not, which accept less as they accept more.
+	negated bool
+}
+
+func (s scope) with(label string) scope {
+	if s.label != "" {
+		label = s.label + " " + label
+	}
+	s.label = label
+	return s
+}
+
+func (d *differ) report(s scope, kind Kind, pointer string, breaking bool, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	if s.label != "" {
+		message = s.label + ": " + message
+	}
+	d.changes = append(d.changes, Change{
+		Kind:      kind,
+		Pointer:   pointer,
+		Path:      s.path,
+		Method:    s.method,
+		Direction: s.direction,
+		Message:   message,
+		Breaking:  breaking,
+	})
+}
+
+// tightened reports a constraint tightened, which breaks requests.
+func (d *differ) tightened(s scope, kind Kind, pointer, format string, args ...any) {
+	d.report(s, kind, pointer, (s.direction == Request) != s.negated, format, args...)
+}
+
+// loosened reports a constraint loosened, which breaks responses.
+func (d *differ) loosened(s scope, kind Kind, pointer, format string, args ...any) {
+	d.report(s, kind, pointer, (s.direction == Response) != s.negated, format, args...)
+}
+
+func pathsMap(paths *openapi3.Paths) map[string]*openapi3.PathItem {
+	if paths == nil {
+		return nil
+	}
+	return paths.Map()
+}
+
+var pathParameter = regexp.MustCompile(`\{[^}]*\}`)
+
+func (d *differ) paths(base, revision map[string]*openapi3.PathItem) {
+	// Paths differing only by the names of their parameters are the same
+	templates := make(map[string]string, len(base))
+	for path := range base {
+		templates[pathParameter.ReplaceAllString(path, "{}")] = path
+	}
+	// matched maps the paths of revision to those of base
+	matched := make(map[string]string, len(revision))
+	kept := make(map[string]bool, len(base))
+	for path := range revision {
+		if basePath, ok := templates[pathParameter.ReplaceAllString(path, "{}")]; ok {
+			matched[path] = basePath
+			kept[basePath] = true
+		}
+	}
+
+	for _, path := range sortedKeys(base) {
+		if !kept[path] {
+			d.report(scope{path: path}, Removed, "/paths/"+escape(path), true, "path removed")
+		}
+	}
+	for _, path := range sortedKeys(revision) {
+		basePath, ok := matched[path]
+		if !ok {
+			d.report(scope{path: path}, Added, "/paths/"+escape(path), false, "path added")
+			continue
+		}
+		d.pathItem(basePath, path, base[basePath], revision[path])
+	}
+}
+
+// pointers are the JSON pointers of a value in base and in revision.
+type pointers struct {
+	base, revision string
+}
+
+func (at pointers) child(token string) pointers {
+	return pointers{base: at.base + "/" + escape(token), revision: at.revision + "/" + escape(token)}
+}
+
+func (d *differ) pathItem(basePath, path string, base, revision *openapi3.PathItem) {
+	at := pointers{base: "/paths/" + escape(basePath), revision: "/paths/" + escape(path)}
+	baseOperations, revisionOperations := base.Operations(), revision.Operations()
+	for _, method := range sortedKeys(baseOperations) {
+		if _, ok := revisionOperations[method]; !ok {
+			s := scope{path: path, method: method}
+			d.report(s, Removed, at.child(strings.ToLower(method)).base, true, "operation removed")
+		}
+	}
+	for _, method := range sortedKeys(revisionOperations) {
+		s := scope{path: path, method: method}
+		operationAt := at.child(strings.ToLower(method))
+		baseOperation, ok := baseOperations[method]
+		if !ok {
+			d.report(s, Added, operationAt.revision, false, "operation added")
+			continue
+		}
+		revisionOperation := revisionOperations[method]
+		if !baseOperation.Deprecated && revisionOperation.Deprecated {
+			d.report(s, Changed, operationAt.revision+"/deprecated", false, "operation deprecated")
+		}
+
+		request := s
+		request.direction = Request
+		d.parameters(request,
+			parameters(basePath, base, baseOperation, operationAt.base),
+			parameters(path, revision, revisionOperation, operationAt.revision))
+		d.requestBody(request, operationAt.child("requestBody"), baseOperation.RequestBody, revisionOperation.RequestBody)
+
+		response := s
+		response.direction = Response
+		d.responses(response, operationAt.child("responses"), baseOperation.Responses, revisionOperation.Responses)
+	}
+}
+
+type parameter struct {
+	pointer string
+	value   *openapi3.Parameter
+}
+
+// parameters returns the parameters of the operation at pointer by location and name,
+// including those of its path item it does not override.
+// Path parameters are keyed by their position in path, as they can be renamed.
+func parameters(path string, pathItem *openapi3.PathItem, operation *openapi3.Operation, pointer string) map[string]parameter {
+	positions := make(map[string]int)
+	for i, name := range pathParameter.FindAllString(path, -1) {
+		positions[strings.Trim(name, "{}")] = i
+	}
+	key := func(p *openapi3.Parameter) string {
+		if i, ok := positions[p.Name]; ok && p.In == openapi3.ParameterInPath {
+			return p.In + " " + strconv.Itoa(i)
+		}
+		return p.In + " " + p.Name
+	}
+
+	m := make(map[string]parameter)
+	pathPointer := pointer[:strings.LastIndex(pointer, "/")]
+	for i, x := range pathItem.Parameters {
+		if x != nil && x.Value != nil {
+			m[key(x.Value)] = parameter{pointer: pathPointer + "/parameters/" + strconv.Itoa(i), value: x.Value}
+		}
+	}
+	for i, x := range operation.Parameters {
+		if x != nil && x.Value != nil {
+			m[key(x.Value)] = parameter{pointer: pointer + "/parameters/" + strconv.Itoa(i), value: x.Value}
+		}
+	}
+	return m
+}
+
+func (d *differ) parameters(s scope, base, revision map[string]parameter) {
+	for _, key := range sortedKeys(base) {
+		if _, ok := revision[key]; !ok {
+			p := base[key]
+			d.report(s.with(parameterLabel(p.value)), Removed, p.pointer, true, "removed")
+		}
+	}
+	for _, key := range sortedKeys(revision) {
+		p := revision[key]
+		ps := s.with(parameterLabel(p.value))
+		b, ok := base[key]
+		if !ok {
+			d.report(ps, Added, p.pointer, p.value.Required, "added")
+			continue
+		}
+		at := pointers{base: b.pointer, revision: p.pointer}
+		switch {
+		case !b.value.Required && p.value.Required:
+			d.tightened(ps, Changed, p.pointer+"/required", "became required")
+		case b.value.Required && !p.value.Required:
+			d.loosened(ps, Changed, p.pointer+"/required", "became optional")
+		}
+		d.schema(ps, at.child("schema"), b.value.Schema, p.value.Schema)
+		d.content(ps, at.child("content"), b.value.Content, p.value.Content)
+	}
+}
+
+func parameterLabel(p *openapi3.Parameter) string {
+	return fmt.Sprintf("%s parameter %q", p.In, p.Name)
+}
+
+func (d *differ) requestBody(s scope, at pointers, base, revision *openapi3.RequestBodyRef) {
+	s = s.with("request body")
+	switch {
+	case base == nil && revision == nil:
+		return
+	case revision == nil || revision.Value == nil:
+		d.report(s, Removed, at.base, true, "removed")
+		return
+	case base == nil || base.Value == nil:
+		d.report(s, Added, at.revision, revision.Value.Required, "added")
+		return
+	}
+	switch {
+	case !base.Value.Required && revision.Value.Required:
+		d.tightened(s, Changed, at.revision+"/required", "became required")
+	case base.Value.Required && !revision.Value.Required:
+		d.loosened(s, Changed, at.revision+"/required", "became optional")
+	}
+	d.content(s, at.child("content"), base.Value.Content, revision.Value.Content)
+}
+
+func (d *differ) content(s scope, at pointers, base, revision openapi3.Content) {
+	for _, mime := range sortedKeys(base) {
+		if _, ok := revision[mime]; !ok {
+			d.report(s, Removed, at.child(mime).base, true, "media type %q removed", mime)
+		}
+	}
+	for _, mime := range sortedKeys(revision) {
+		b, ok := base[mime]
+		if !ok {
+			d.report(s, Added, at.child(mime).revision, false, "media type %q added", mime)
+			continue
+		}
+		if b != nil && revision[mime] != nil {
+			d.schema(s.with(strconv.Quote(mime)), at.child(mime).child("schema"), b.Schema, revision[mime].Schema)
+		}
+	}
+}
+
+func (d *differ) responses(s scope, at pointers, base, revision *openapi3.Responses) {
+	var baseMap, revisionMap map[string]*openapi3.ResponseRef
+	if base != nil {
+		baseMap = base.Map()
+	}
+	if revision != nil {
+		revisionMap = revision.Map()
+	}
+	for _, code := range sortedKeys(baseMap) {
+		if _, ok := revisionMap[code]; !ok {
+			// Clients may rely on successful responses, not on errors
+			d.report(s.with("response "+code), Removed, at.child(code).base, strings.HasPrefix(code, "2"), "removed")
+		}
+	}
+	for _, code := range sortedKeys(revisionMap) {
+		rs := s.with("response " + code)
+		b, ok := baseMap[code]
+		if !ok {
+			d.report(rs, Added, at.child(code).revision, false, "added")
+			continue
+		}
+		r := revisionMap[code]
+		if b == nil || b.Value == nil || r == nil || r.Value == nil {
+			continue
+		}
+		d.headers(rs, at.child(code).child("headers"), b.Value.Headers, r.Value.Headers)
+		d.content(rs, at.child(code).child("content"), b.Value.Content, r.Value.Content)
+	}
+}
+
+func (d *differ) headers(s scope, at pointers, base, revision openapi3.Headers) {
+	for _, name := range sortedKeys(base) {
+		if _, ok := revision[name]; !ok {
+			d.report(s, Removed, at.child(name).base, true, "header %q removed", name)
+		}
+	}
+	for _, name := range sortedKeys(revision) {
+		b, ok := base[name]
+		if !ok {
+			d.report(s, Added, at.child(name).revision, false, "header %q added", name)
+			continue
+		}
+		r := revision[name]
+		if b != nil && b.Value != nil && r != nil && r.Value != nil {
+			d.schema(s.with(fmt.Sprintf("header %q", name)), at.child(name).child("schema"), b.Value.Schema, r.Value.Schema)
+		}
+	}
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,301 @@
+package openapi3diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const baseSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      parameters:
+      - {name: limit, in: query, schema: {type: integer, maximum: 100}}
+      - {name: status, in: query, schema: {type: string, enum: [available, sold]}}
+      responses:
+        '200':
+          description: The pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+      responses:
+        '201': {description: Created}
+        '400': {description: Invalid}
+  /pets/{id}:
+    get:
+      parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        '200':
+          description: The pet
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+    delete:
+      parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        '204': {description: Deleted}
+  /stores:
+    get:
+      responses:
+        '200': {description: The stores}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, maxLength: 50}
+        kind: {type: string, enum: [cat, dog]}
+        tag: {type: string}
+        parent: {$ref: '#/components/schemas/Pet'}
+`
+
+const revisionSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 2.0.0}
+paths:
+  /pets:
+    get:
+      parameters:
+      - {name: limit, in: query, schema: {type: integer, maximum: 50}}
+      - {name: status, in: query, required: true, schema: {type: string, enum: [available, sold, pending]}}
+      responses:
+        '200':
+          description: The pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+      responses:
+        '201': {description: Created}
+  /pets/{petId}:
+    get:
+      parameters:
+      - {name: petId, in: path, required: true, schema: {type: string}}
+      responses:
+        '200':
+          description: The pet
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+  /owners:
+    get:
+      responses:
+        '200': {description: The owners}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name, kind]
+      properties:
+        name: {type: string, maxLength: 30}
+        kind: {type: string, enum: [cat]}
+        parent: {$ref: '#/components/schemas/Pet'}
+`
+
+func load(t *testing.T, spec string) *openapi3.T {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	return doc
+}
+
+func TestDiff(t *testing.T) {
+	report := Diff(load(t, baseSpec), load(t, revisionSpec))
+	require.True(t, report.HasBreaking())
+	require.Len(t, report.Breaking(), 10)
+	require.Equal(t, Change{
+		Kind:      Changed,
+		Pointer:   "/paths/~1pets/get/parameters/0/schema/maximum",
+		Path:      "/pets",
+		Method:    "GET",
+		Direction: Request,
+		Message:   `query parameter "limit": maximum decreased from 100 to 50`,
+		Breaking:  true,
+	}, report.Breaking()[1])
+
+	require.Equal(t, `breaking: /stores: path removed (/paths/~1stores)
+breaking: GET /pets: query parameter "limit": maximum decreased from 100 to 50 (/paths/~1pets/get/parameters/0/schema/maximum)
+breaking: GET /pets: query parameter "status": became required (/paths/~1pets/get/parameters/1/required)
+breaking: GET /pets: response 200 "application/json" items: property "tag" removed (/paths/~1pets/get/responses/200/content/application~1json/schema/items/properties/tag)
+breaking: POST /pets: request body: became required (/paths/~1pets/post/requestBody/required)
+breaking: POST /pets: request body "application/json": property "kind" became required (/paths/~1pets/post/requestBody/content/application~1json/schema/required)
+breaking: POST /pets: request body "application/json" property "kind": enum values "dog" removed (/paths/~1pets/post/requestBody/content/application~1json/schema/properties/kind/enum)
+breaking: POST /pets: request body "application/json" property "name": maxLength decreased from 50 to 30 (/paths/~1pets/post/requestBody/content/application~1json/schema/properties/name/maxLength)
+breaking: DELETE /pets/{petId}: operation removed (/paths/~1pets~1{id}/delete)
+breaking: GET /pets/{petId}: response 200 "application/json": property "tag" removed (/paths/~1pets~1{id}/get/responses/200/content/application~1json/schema/properties/tag)
+/owners: path added (/paths/~1owners)
+GET /pets: query parameter "status": enum values "pending" added (/paths/~1pets/get/parameters/1/schema/enum)
+GET /pets: response 200 "application/json" items: property "kind" became required (/paths/~1pets/get/responses/200/content/application~1json/schema/items/required)
+GET /pets: response 200 "application/json" items property "kind": enum values "dog" removed (/paths/~1pets/get/responses/200/content/application~1json/schema/items/properties/kind/enum)
+GET /pets: response 200 "application/json" items property "name": maxLength decreased from 50 to 30 (/paths/~1pets/get/responses/200/content/application~1json/schema/items/properties/name/maxLength)
+POST /pets: request body "application/json": property "tag" removed (/paths/~1pets/post/requestBody/content/application~1json/schema/properties/tag)
+POST /pets: response 400: removed (/paths/~1pets/post/responses/400)
+GET /pets/{petId}: response 200 "application/json": property "kind" became required (/paths/~1pets~1{petId}/get/responses/200/content/application~1json/schema/required)
+GET /pets/{petId}: response 200 "application/json" property "kind": enum values "dog" removed (/paths/~1pets~1{petId}/get/responses/200/content/application~1json/schema/properties/kind/enum)
+GET /pets/{petId}: response 200 "application/json" property "name": maxLength decreased from 50 to 30 (/paths/~1pets~1{petId}/get/responses/200/content/application~1json/schema/properties/name/maxLength)
+`, report.Text())
+}
+
+func TestDiffSchemaDirection(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info: {title: Things, version: 1.0.0}
+paths:
+  /things:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Thing'}
+      responses:
+        '200':
+          description: The thing
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Thing'}
+components:
+  schemas:
+    Thing:
+`
+
+	for _, tc := range []struct {
+		name              string
+		base, revision    string
+		request, response bool
+	}{
+		{name: "enum value removed", base: `{type: string, enum: [a, b]}`, revision: `{type: string, enum: [a]}`, request: true},
+		{name: "enum value added", base: `{type: string, enum: [a]}`, revision: `{type: string, enum: [a, b]}`, response: true},
+		{name: "minimum raised", base: `{type: number, minimum: 1}`, revision: `{type: number, minimum: 2}`, request: true},
+		{name: "maximum raised", base: `{type: number, maximum: 1}`, revision: `{type: number, maximum: 2}`, response: true},
+		{name: "maxItems removed", base: `{type: array, maxItems: 3}`, revision: `{type: array}`, response: true},
+		{name: "pattern added", base: `{type: string}`, revision: `{type: string, pattern: '^a'}`, request: true},
+		{name: "pattern changed", base: `{type: string, pattern: '^a'}`, revision: `{type: string, pattern: '^b'}`, request: true, response: true},
+		{name: "became nullable", base: `{type: string}`, revision: `{type: string, nullable: true}`, response: true},
+		{name: "type changed", base: `{type: string}`, revision: `{type: integer}`, request: true, response: true},
+		{name: "format changed", base: `{type: string, format: date}`, revision: `{type: string, format: date-time}`, request: true, response: true},
+		{name: "additional properties forbidden", base: `{type: object}`, revision: `{type: object, additionalProperties: false}`, request: true},
+		{name: "exclusiveMinimum added", base: `{type: number, minimum: 1}`, revision: `{type: number, minimum: 1, exclusiveMinimum: true}`, request: true},
+		{name: "exclusiveMaximum removed", base: `{type: number, maximum: 1, exclusiveMaximum: true}`, revision: `{type: number, maximum: 1}`, response: true},
+		{name: "numeric exclusiveMinimum lowered", base: `{type: number, exclusiveMinimum: 2}`, revision: `{type: number, exclusiveMinimum: 1}`, response: true},
+		{name: "numeric exclusiveMaximum lowered", base: `{type: number, exclusiveMaximum: 2}`, revision: `{type: number, exclusiveMaximum: 1}`, request: true},
+		{name: "multipleOf multiplied", base: `{type: number, multipleOf: 2}`, revision: `{type: number, multipleOf: 4}`, request: true},
+		{name: "multipleOf divided", base: `{type: number, multipleOf: 4}`, revision: `{type: number, multipleOf: 2}`, response: true},
+		{name: "multipleOf changed", base: `{type: number, multipleOf: 2}`, revision: `{type: number, multipleOf: 3}`, request: true, response: true},
+		{name: "items became unique", base: `{type: array}`, revision: `{type: array, uniqueItems: true}`, request: true},
+		{name: "const added", base: `{type: string}`, revision: `{type: string, const: a}`, request: true},
+		{name: "const removed", base: `{const: null}`, revision: `{}`, response: true},
+		{name: "const changed", base: `{const: null}`, revision: `{const: 0}`, request: true, response: true},
+		{name: "not added", base: `{type: string}`, revision: `{type: string, not: {enum: [a]}}`, request: true},
+		{name: "not loosened", base: `{type: string, not: {enum: [a]}}`, revision: `{type: string, not: {enum: [a, b]}}`, request: true},
+		{name: "not tightened", base: `{type: string, not: {enum: [a, b]}}`, revision: `{type: string, not: {enum: [a]}}`, response: true},
+		{name: "prefixItems added", base: `{type: array}`, revision: `{type: array, prefixItems: [{type: string}]}`, request: true},
+		{name: "prefixItem tightened", base: `{type: array, prefixItems: [{type: string}]}`, revision: `{type: array, prefixItems: [{type: string, maxLength: 1}]}`, request: true},
+		{name: "minContains raised", base: `{type: array, contains: {type: string}, minContains: 1}`, revision: `{type: array, contains: {type: string}, minContains: 2}`, request: true},
+		{name: "maxContains raised", base: `{type: array, contains: {type: string}, maxContains: 1}`, revision: `{type: array, contains: {type: string}, maxContains: 2}`, response: true},
+		{name: "anyOf schema added", base: `{anyOf: [{type: string}]}`, revision: `{anyOf: [{type: string}, {type: number}]}`, response: true},
+		{name: "oneOf schema removed", base: `{oneOf: [{type: string}, {type: number}]}`, revision: `{oneOf: [{type: string}]}`, request: true},
+		{name: "anyOf added", base: `{}`, revision: `{anyOf: [{type: string}]}`, request: true},
+		{name: "allOf schema added", base: `{allOf: [{type: string}]}`, revision: `{allOf: [{type: string}, {maxLength: 1}]}`, request: true},
+		{name: "allOf schema removed", base: `{allOf: [{type: string}, {maxLength: 1}]}`, revision: `{allOf: [{type: string}]}`, response: true},
+		{name: "property removed", base: `{type: object, properties: {a: {}}}`, revision: `{type: object}`, response: true},
+		{name: "property removed without additional properties", base: `{type: object, properties: {a: {}}, additionalProperties: false}`, revision: `{type: object, additionalProperties: false}`, request: true, response: true},
+		{name: "contains added", base: `{type: array}`, revision: `{type: array, contains: {type: string}}`, request: true},
+		{name: "contains tightened", base: `{type: array, contains: {type: string}}`, revision: `{type: array, contains: {type: string, maxLength: 1}}`, request: true},
+		{name: "contains tightened without minContains", base: `{type: array, contains: {type: string}, minContains: 0, maxContains: 1}`, revision: `{type: array, contains: {type: string, maxLength: 1}, minContains: 0, maxContains: 1}`, response: true},
+		{name: "default minContains raised", base: `{type: array, contains: {type: string}}`, revision: `{type: array, contains: {type: string}, minContains: 2}`, request: true},
+		{name: "default minContains lowered", base: `{type: array, contains: {type: string}}`, revision: `{type: array, contains: {type: string}, minContains: 0}`, response: true},
+		{name: "default minContains kept", base: `{type: array, contains: {type: string}}`, revision: `{type: array, contains: {type: string}, minContains: 1}`},
+		{name: "if changed", base: `{if: {type: string}, then: {maxLength: 1}}`, revision: `{if: {type: number}, then: {maxLength: 1}}`, request: true, response: true},
+		{name: "then added", base: `{if: {type: string}}`, revision: `{if: {type: string}, then: {maxLength: 1}}`, request: true},
+		{name: "then loosened", base: `{if: {type: string}, then: {maxLength: 1}}`, revision: `{if: {type: string}, then: {maxLength: 2}}`, response: true},
+		{name: "else tightened", base: `{if: {type: string}, else: {minimum: 1}}`, revision: `{if: {type: string}, else: {minimum: 2}}`, request: true},
+		{name: "dependentRequired added", base: `{type: object}`, revision: `{type: object, dependentRequired: {a: [b]}}`, request: true},
+		{name: "dependentRequired property removed", base: `{type: object, dependentRequired: {a: [b, c]}}`, revision: `{type: object, dependentRequired: {a: [b]}}`, response: true},
+		{name: "dependentSchemas added", base: `{type: object}`, revision: `{type: object, dependentSchemas: {a: {required: [b]}}}`, request: true},
+		{name: "dependentSchemas loosened", base: `{type: object, dependentSchemas: {a: {required: [b]}}}`, revision: `{type: object, dependentSchemas: {a: {}}}`, response: true},
+		{name: "patternProperties added", base: `{type: object}`, revision: `{type: object, patternProperties: {'^x-': {type: string}}}`, request: true},
+		{name: "patternProperties added without additional properties", base: `{type: object, additionalProperties: false}`, revision: `{type: object, patternProperties: {'^x-': {}}, additionalProperties: false}`, response: true},
+		{name: "patternProperties removed without additional properties", base: `{type: object, patternProperties: {'^x-': {}}, additionalProperties: false}`, revision: `{type: object, additionalProperties: false}`, request: true},
+		{name: "patternProperties tightened", base: `{type: object, patternProperties: {'^x-': {}}}`, revision: `{type: object, patternProperties: {'^x-': {type: string}}}`, request: true},
+		{name: "propertyNames added", base: `{type: object}`, revision: `{type: object, propertyNames: {maxLength: 3}}`, request: true},
+		{name: "propertyNames loosened", base: `{type: object, propertyNames: {maxLength: 3}}`, revision: `{type: object, propertyNames: {maxLength: 4}}`, response: true},
+		{name: "unevaluatedProperties forbidden", base: `{type: object}`, revision: `{type: object, unevaluatedProperties: {not: {}}}`, request: true},
+		{name: "unevaluatedProperties allowed", base: `{type: object, unevaluatedProperties: {not: {}}}`, revision: `{type: object, unevaluatedProperties: {}}`, response: true},
+		{name: "unevaluatedItems tightened", base: `{type: array, unevaluatedItems: {}}`, revision: `{type: array, unevaluatedItems: {type: string}}`, request: true},
+		{name: "prefixItem added over items", base: `{type: array, items: {type: string}}`, revision: `{type: array, prefixItems: [{type: string, maxLength: 1}], items: {type: string}}`, request: true},
+		{name: "prefixItem added over no items", base: `{type: array, prefixItems: [{}], items: {not: {}}}`, revision: `{type: array, prefixItems: [{}, {}], items: {not: {}}}`, response: true},
+		{name: "prefixItem removed over items", base: `{type: array, prefixItems: [{type: string}], items: {type: string}}`, revision: `{type: array, items: {type: string}}`},
+		{name: "became read-only", base: `{type: string}`, revision: `{type: string, readOnly: true}`, request: true},
+		{name: "became write-only", base: `{type: string}`, revision: `{type: string, writeOnly: true}`, response: true},
+		{name: "became not read-only", base: `{type: string, readOnly: true}`, revision: `{type: string}`},
+		{name: "discriminator added", base: `{type: object}`, revision: `{type: object, discriminator: {propertyName: kind}}`, request: true},
+		{name: "discriminator removed", base: `{type: object, discriminator: {propertyName: kind}}`, revision: `{type: object}`, response: true},
+		{name: "discriminator changed", base: `{type: object, discriminator: {propertyName: kind}}`, revision: `{type: object, discriminator: {propertyName: type}}`, request: true, response: true},
+		{name: "discriminator value added", base: `{type: object, discriminator: {propertyName: kind, mapping: {a: '#/a'}}}`, revision: `{type: object, discriminator: {propertyName: kind, mapping: {a: '#/a', b: '#/b'}}}`, response: true},
+		{name: "discriminator value removed", base: `{type: object, discriminator: {propertyName: kind, mapping: {a: '#/a', b: '#/b'}}}`, revision: `{type: object, discriminator: {propertyName: kind, mapping: {a: '#/a'}}}`, request: true},
+		{name: "description changed", base: `{type: string, description: a}`, revision: `{type: string, description: b}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := Diff(load(t, spec+"      "+tc.base), load(t, spec+"      "+tc.revision))
+			breaking := map[Direction]bool{}
+			for _, change := range report.Changes {
+				breaking[change.Direction] = breaking[change.Direction] || change.Breaking
+			}
+			require.Equal(t, tc.request, breaking[Request], report.Text())
+			require.Equal(t, tc.response, breaking[Response], report.Text())
+		})
+	}
+}
+
+func TestDiffMarkdown(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info: {title: Things, version: 1.0.0}
+paths:
+  /things:
+    get:
+      parameters:
+      - {name: q, in: query, schema: {type: string, pattern: '^a|b$'}}
+      responses:
+        '200': {description: The things}
+`
+	base := load(t, spec)
+	require.Equal(t, "No changes.\n", Diff(base, base).Markdown())
+
+	revision := load(t, spec)
+	revision.Paths.Value("/things").Get.Parameters[0].Value.Required = true
+	revision.Paths.Value("/things").Get.Deprecated = true
+	require.Equal(t, `## Breaking changes
+
+| Operation | Change | Location |
+| --- | --- | --- |
+| `+"`GET /things`"+` | query parameter "q": became required | `+"`/paths/~1things/get/parameters/0/required`"+` |
+
+## Non-breaking changes
+
+| Operation | Change | Location |
+| --- | --- | --- |
+| `+"`GET /things`"+` | operation deprecated | `+"`/paths/~1things/get/deprecated`"+` |
+`, Diff(base, revision).Markdown())
+}
@@ -0,0 +1,3 @@
+// Package openapi3diff reports the changes between two versions of an OpenAPI v3
+// specification document, and whether they break clients.
+package openapi3diff
@@ -0,0 +1,70 @@
+package openapi3diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Text returns the changes of the report as text, one per line, breaking ones first:
+//
+//	breaking: GET /pets: request: query parameter "limit": maximum decreased from 100 to 50 (/paths/~1pets/get/parameters/0/schema/maximum)
+//	GET /pets: response: response 200: "application/json": items: property "tag" added (/paths/~1pets/get/responses/200/content/application~1json/schema/items/properties/tag)
+func (report *Report) Text() string {
+	var b strings.Builder
+	for _, change := range report.sorted() {
+		if change.Breaking {
+			b.WriteString("breaking: ")
+		}
+		fmt.Fprintf(&b, "%s: %s (%s)\n", change.Operation(), change.Message, change.Pointer)
+	}
+	return b.String()
+}
+
+// Markdown returns the changes of the report as Markdown tables
+// of the breaking changes and of the other ones.
+func (report *Report) Markdown() string {
+	changes := report.sorted()
+	breaking := len(report.Breaking())
+
+	var b strings.Builder
+	if len(changes) == 0 {
+		b.WriteString("No changes.\n")
+	}
+	for _, section := range []struct {
+		title   string
+		changes []Change
+	}{
+		{title: "Breaking changes", changes: changes[:breaking]},
+		{title: "Non-breaking changes", changes: changes[breaking:]},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		if b.Len() != 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", section.title)
+		b.WriteString("| Operation | Change | Location |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, change := range section.changes {
+			fmt.Fprintf(&b, "| `%s` | %s | `%s` |\n", change.Operation(), markdownEscape(change.Message), change.Pointer)
+		}
+	}
+	return b.String()
+}
+
+// sorted returns the changes with the breaking ones first.
+func (report *Report) sorted() []Change {
+	changes := make([]Change, 0, len(report.Changes))
+	changes = append(changes, report.Breaking()...)
+	for _, change := range report.Changes {
+		if !change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;").Replace(s)
+}
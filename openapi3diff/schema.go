@@ -0,0 +1,522 @@
+package openapi3diff
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaPair is a schema of base compared with one of revision.
+type schemaPair struct {
+	base, revision *openapi3.Schema
+}
+
+// schema reports the changes of the constraints of a schema.
+// Tightened constraints break requests, loosened ones break responses.
+func (d *differ) schema(s scope, at pointers, base, revision *openapi3.SchemaRef) {
+	switch {
+	case base == nil || base.Value == nil:
+		if revision != nil && revision.Value != nil {
+			d.tightened(s, Added, at.revision, "schema added")
+		}
+		return
+	case revision == nil || revision.Value == nil:
+		d.loosened(s, Removed, at.base, "schema removed")
+		return
+	}
+
+	// Recursive schemas are compared once per cycle
+	pair := schemaPair{base: base.Value, revision: revision.Value}
+	if d.comparing[pair] {
+		return
+	}
+	if d.comparing == nil {
+		d.comparing = make(map[schemaPair]bool)
+	}
+	d.comparing[pair] = true
+	defer delete(d.comparing, pair)
+
+	b, r := base.Value, revision.Value
+	d.types(s, at.child("type"), b.Type.Slice(), r.Type.Slice())
+	switch {
+	case b.Format == r.Format:
+	case b.Format == "":
+		d.tightened(s, Added, at.revision+"/format", "format %q added", r.Format)
+	case r.Format == "":
+		d.loosened(s, Removed, at.base+"/format", "format %q removed", b.Format)
+	default:
+		d.report(s, Changed, at.revision+"/format", true, "format changed from %q to %q", b.Format, r.Format)
+	}
+	switch {
+	case !b.Nullable && r.Nullable:
+		d.loosened(s, Changed, at.revision+"/nullable", "became nullable")
+	case b.Nullable && !r.Nullable:
+		d.tightened(s, Changed, at.revision+"/nullable", "became not nullable")
+	}
+	d.enum(s, at.child("enum"), b.Enum, r.Enum)
+
+	switch {
+	case !b.HasConst() && !r.HasConst():
+	case !b.HasConst():
+		d.tightened(s, Added, at.revision+"/const", "const %#v added", r.Const)
+	case !r.HasConst():
+		d.loosened(s, Removed, at.base+"/const", "const %#v removed", b.Const)
+	case !reflect.DeepEqual(b.Const, r.Const):
+		d.report(s, Changed, at.revision+"/const", true, "const changed from %#v to %#v", b.Const, r.Const)
+	}
+
+	d.minimum(s, at.child("minimum"), "minimum", b.Min, r.Min)
+	d.maximum(s, at.child("maximum"), "maximum", b.Max, r.Max)
+	d.exclusiveBound(s, at.child("exclusiveMinimum"), "exclusiveMinimum", b.ExclusiveMin, r.ExclusiveMin, d.minimum)
+	d.exclusiveBound(s, at.child("exclusiveMaximum"), "exclusiveMaximum", b.ExclusiveMax, r.ExclusiveMax, d.maximum)
+	d.multipleOf(s, at.child("multipleOf"), b.MultipleOf, r.MultipleOf)
+	d.minimum(s, at.child("minLength"), "minLength", positive(b.MinLength), positive(r.MinLength))
+	d.maximum(s, at.child("maxLength"), "maxLength", float(b.MaxLength), float(r.MaxLength))
+	d.minimum(s, at.child("minItems"), "minItems", positive(b.MinItems), positive(r.MinItems))
+	d.maximum(s, at.child("maxItems"), "maxItems", float(b.MaxItems), float(r.MaxItems))
+	switch {
+	case !b.UniqueItems && r.UniqueItems:
+		d.tightened(s, Changed, at.revision+"/uniqueItems", "items became unique")
+	case b.UniqueItems && !r.UniqueItems:
+		d.loosened(s, Changed, at.revision+"/uniqueItems", "items became not unique")
+	}
+	d.contains(s, at, b, r)
+	d.minimum(s, at.child("minProperties"), "minProperties", positive(b.MinProps), positive(r.MinProps))
+	d.maximum(s, at.child("maxProperties"), "maxProperties", float(b.MaxProps), float(r.MaxProps))
+	switch {
+	case b.Pattern == r.Pattern:
+	case b.Pattern == "":
+		d.tightened(s, Added, at.revision+"/pattern", "pattern %q added", r.Pattern)
+	case r.Pattern == "":
+		d.loosened(s, Removed, at.base+"/pattern", "pattern %q removed", b.Pattern)
+	default:
+		d.report(s, Changed, at.revision+"/pattern", true, "pattern changed from %q to %q", b.Pattern, r.Pattern)
+	}
+
+	switch {
+	case !b.ReadOnly && r.ReadOnly:
+		// Clients can no longer send it
+		d.report(s, Changed, at.revision+"/readOnly", s.direction == Request, "became read-only")
+	case b.ReadOnly && !r.ReadOnly:
+		d.report(s, Changed, at.revision+"/readOnly", false, "became not read-only")
+	}
+	switch {
+	case !b.WriteOnly && r.WriteOnly:
+		// Clients no longer receive it
+		d.report(s, Changed, at.revision+"/writeOnly", s.direction == Response, "became write-only")
+	case b.WriteOnly && !r.WriteOnly:
+		d.report(s, Changed, at.revision+"/writeOnly", false, "became not write-only")
+	}
+
+	d.required(s, at.child("required"), b.Required, r.Required)
+	d.dependentRequired(s, at.child("dependentRequired"), b.DependentRequired, r.DependentRequired)
+	d.properties(s, at.child("properties"), b.Properties, r.Properties, !additionalAllowed(r.AdditionalProperties))
+	d.patternProperties(s, at.child("patternProperties"), b.PatternProperties, r.PatternProperties, r.AdditionalProperties)
+	d.subschema(s, at.child("propertyNames"), "propertyNames", b.PropertyNames, r.PropertyNames, false)
+	d.dependentSchemas(s, at.child("dependentSchemas"), b.DependentSchemas, r.DependentSchemas)
+	d.prefixItems(s, at, b, r)
+	d.schema(s.with("items"), at.child("items"), b.Items, r.Items)
+	d.additionalProperties(s, at.child("additionalProperties"), b.AdditionalProperties, r.AdditionalProperties)
+	d.discriminator(s, at.child("discriminator"), b.Discriminator, r.Discriminator)
+	d.schemas(s, at.child("allOf"), "allOf", b.AllOf, r.AllOf)
+	d.schemas(s, at.child("anyOf"), "anyOf", b.AnyOf, r.AnyOf)
+	d.schemas(s, at.child("oneOf"), "oneOf", b.OneOf, r.OneOf)
+	d.subschema(s, at.child("not"), "not", b.Not, r.Not, true)
+	d.condition(s, at.child("if"), b.If, r.If)
+	d.subschema(s, at.child("then"), "then", b.Then, r.Then, false)
+	d.subschema(s, at.child("else"), "else", b.Else, r.Else, false)
+	d.subschema(s, at.child("unevaluatedItems"), "unevaluatedItems", b.UnevaluatedItems, r.UnevaluatedItems, false)
+	d.subschema(s, at.child("unevaluatedProperties"), "unevaluatedProperties", b.UnevaluatedProperties, r.UnevaluatedProperties, false)
+}
+
+// subschema compares the schemas of a keyword that constrains the schema more
+// as they constrain more, or less if negated, such as "not".
+func (d *differ) subschema(s scope, at pointers, name string, base, revision *openapi3.SchemaRef, negated bool) {
+	switch {
+	case base == nil && revision == nil:
+	case base == nil:
+		d.tightened(s, Added, at.revision, "%s added", name)
+	case revision == nil:
+		d.loosened(s, Removed, at.base, "%s removed", name)
+	default:
+		sub := s.with(name)
+		sub.negated = sub.negated != negated
+		d.schema(sub, at, base, revision)
+	}
+}
+
+// condition reports the changes of "if", which can both tighten and loosen
+// the schema, applying "then" to more values and "else" to fewer.
+func (d *differ) condition(s scope, at pointers, base, revision *openapi3.SchemaRef) {
+	switch {
+	case base == nil && revision == nil:
+	case base == nil:
+		d.report(s, Added, at.revision, true, "if added")
+	case revision == nil:
+		d.report(s, Removed, at.base, true, "if removed")
+	default:
+		compared := &differ{comparing: d.comparing}
+		compared.schema(scope{direction: s.direction}, at, base, revision)
+		if len(compared.changes) != 0 {
+			d.report(s, Changed, at.revision, true, "if changed")
+		}
+	}
+}
+
+// contains reports the changes of contains, minContains and maxContains.
+// minContains defaults to 1 when there is a contains, 0 making contains only
+// bound the number of matching items with maxContains.
+func (d *differ) contains(s scope, at pointers, base, revision *openapi3.Schema) {
+	bMin, rMin := float(base.MinContains), float(revision.MinContains)
+	if base.Contains != nil && revision.Contains != nil {
+		bMin, rMin = minContains(base), minContains(revision)
+	}
+	d.minimum(s, at.child("minContains"), "minContains", bMin, rMin)
+	d.maximum(s, at.child("maxContains"), "maxContains", float(base.MaxContains), float(revision.MaxContains))
+	negated := bMin != nil && *bMin == 0 && rMin != nil && *rMin == 0
+	d.subschema(s, at.child("contains"), "contains", base.Contains, revision.Contains, negated)
+}
+
+func minContains(schema *openapi3.Schema) *float64 {
+	if schema.MinContains != nil {
+		return float(schema.MinContains)
+	}
+	one := 1.0
+	return &one
+}
+
+// prefixItems compares the schemas that apply to the items at the indexes of
+// prefixItems, which are those of items beyond them.
+func (d *differ) prefixItems(s scope, at pointers, base, revision *openapi3.Schema) {
+	n := len(base.PrefixItems)
+	if len(revision.PrefixItems) > n {
+		n = len(revision.PrefixItems)
+	}
+	for i := 0; i < n; i++ {
+		index := strconv.Itoa(i)
+		b, bAt := base.Items, at.base+"/items"
+		if i < len(base.PrefixItems) {
+			b, bAt = base.PrefixItems[i], at.base+"/prefixItems/"+index
+		}
+		r, rAt := revision.Items, at.revision+"/items"
+		if i < len(revision.PrefixItems) {
+			r, rAt = revision.PrefixItems[i], at.revision+"/prefixItems/"+index
+		}
+		if b == nil && r == nil {
+			continue
+		}
+		d.schema(s.with("item "+index), pointers{base: bAt, revision: rAt}, b, r)
+	}
+}
+
+func (d *differ) types(s scope, at pointers, base, revision []string) {
+	if len(base) == 0 && len(revision) == 0 {
+		return
+	}
+	removed, added := difference(base, revision), difference(revision, base)
+	switch {
+	case len(removed) == 0 && len(added) == 0:
+	case len(base) == 0:
+		d.tightened(s, Added, at.revision, "type %s added", strings.Join(revision, ", "))
+	case len(revision) == 0:
+		d.loosened(s, Removed, at.base, "type %s removed", strings.Join(base, ", "))
+	case len(removed) == 0:
+		d.loosened(s, Changed, at.revision, "type %s added", strings.Join(added, ", "))
+	case len(added) == 0:
+		d.tightened(s, Changed, at.revision, "type %s removed", strings.Join(removed, ", "))
+	default:
+		d.report(s, Changed, at.revision, true, "type changed from %s to %s", strings.Join(base, ", "), strings.Join(revision, ", "))
+	}
+}
+
+func (d *differ) enum(s scope, at pointers, base, revision []any) {
+	switch {
+	case len(base) == 0 && len(revision) == 0:
+	case len(base) == 0:
+		d.tightened(s, Added, at.revision, "enum %s added", values(revision))
+	case len(revision) == 0:
+		d.loosened(s, Removed, at.base, "enum %s removed", values(base))
+	default:
+		if removed := difference(base, revision); len(removed) != 0 {
+			d.tightened(s, Changed, at.revision, "enum values %s removed", values(removed))
+		}
+		if added := difference(revision, base); len(added) != 0 {
+			d.loosened(s, Changed, at.revision, "enum values %s added", values(added))
+		}
+	}
+}
+
+// exclusiveBound reports the changes of exclusiveMinimum or exclusiveMaximum,
+// comparing their numeric values with compare.
+func (d *differ) exclusiveBound(s scope, at pointers, name string, base, revision openapi3.ExclusiveBound, compare func(scope, pointers, string, *float64, *float64)) {
+	switch {
+	case base.Value != nil || revision.Value != nil:
+		compare(s, at, name, base.Value, revision.Value)
+	case !base.IsTrue && revision.IsTrue:
+		d.tightened(s, Added, at.revision, "%s added", name)
+	case base.IsTrue && !revision.IsTrue:
+		d.loosened(s, Removed, at.base, "%s removed", name)
+	}
+}
+
+// multipleOf reports the changes of multipleOf, a multiple of it tightening the schema.
+func (d *differ) multipleOf(s scope, at pointers, base, revision *float64) {
+	switch {
+	case base == nil && revision == nil:
+	case base == nil:
+		d.tightened(s, Added, at.revision, "multipleOf %s added", number(*revision))
+	case revision == nil:
+		d.loosened(s, Removed, at.base, "multipleOf %s removed", number(*base))
+	case *base == *revision:
+	case isMultiple(*revision, *base):
+		d.tightened(s, Changed, at.revision, "multipleOf changed from %s to %s", number(*base), number(*revision))
+	case isMultiple(*base, *revision):
+		d.loosened(s, Changed, at.revision, "multipleOf changed from %s to %s", number(*base), number(*revision))
+	default:
+		d.report(s, Changed, at.revision, true, "multipleOf changed from %s to %s", number(*base), number(*revision))
+	}
+}
+
+// minimum reports the changes of a lower bound, raising it tightens the schema.
+func (d *differ) minimum(s scope, at pointers, name string, base, revision *float64) {
+	switch {
+	case base == nil && revision == nil:
+	case base == nil:
+		d.tightened(s, Added, at.revision, "%s %s added", name, number(*revision))
+	case revision == nil:
+		d.loosened(s, Removed, at.base, "%s %s removed", name, number(*base))
+	case *revision > *base:
+		d.tightened(s, Changed, at.revision, "%s increased from %s to %s", name, number(*base), number(*revision))
+	case *revision < *base:
+		d.loosened(s, Changed, at.revision, "%s decreased from %s to %s", name, number(*base), number(*revision))
+	}
+}
+
+// maximum reports the changes of an upper bound, lowering it tightens the schema.
+func (d *differ) maximum(s scope, at pointers, name string, base, revision *float64) {
+	switch {
+	case base == nil && revision == nil:
+	case base == nil:
+		d.tightened(s, Added, at.revision, "%s %s added", name, number(*revision))
+	case revision == nil:
+		d.loosened(s, Removed, at.base, "%s %s removed", name, number(*base))
+	case *revision < *base:
+		d.tightened(s, Changed, at.revision, "%s decreased from %s to %s", name, number(*base), number(*revision))
+	case *revision > *base:
+		d.loosened(s, Changed, at.revision, "%s increased from %s to %s", name, number(*base), number(*revision))
+	}
+}
+
+func (d *differ) required(s scope, at pointers, base, revision []string) {
+	for _, name := range difference(revision, base) {
+		d.tightened(s, Changed, at.revision, "property %q became required", name)
+	}
+	for _, name := range difference(base, revision) {
+		d.loosened(s, Changed, at.revision, "property %q became optional", name)
+	}
+}
+
+// properties compares the properties of schemas, forbidden telling whether
+// the revision forbids additional properties.
+func (d *differ) properties(s scope, at pointers, base, revision openapi3.Schemas, forbidden bool) {
+	for _, name := range sortedKeys(base) {
+		if _, ok := revision[name]; !ok {
+			// Clients may still send it unless additional properties are forbidden,
+			// but no longer receive it
+			d.report(s, Removed, at.child(name).base, s.direction == Response || forbidden, "property %q removed", name)
+		}
+	}
+	for _, name := range sortedKeys(revision) {
+		b, ok := base[name]
+		if !ok {
+			d.report(s, Added, at.child(name).revision, false, "property %q added", name)
+			continue
+		}
+		d.schema(s.with(fmt.Sprintf("property %q", name)), at.child(name), b, revision[name])
+	}
+}
+
+func (d *differ) additionalProperties(s scope, at pointers, base, revision openapi3.AdditionalProperties) {
+	switch {
+	case additionalAllowed(base) && !additionalAllowed(revision):
+		d.tightened(s, Changed, at.revision, "additional properties became forbidden")
+	case !additionalAllowed(base) && additionalAllowed(revision):
+		d.loosened(s, Changed, at.revision, "additional properties became allowed")
+	case base.Schema != nil || revision.Schema != nil:
+		d.schema(s.with("additional properties"), at, base.Schema, revision.Schema)
+	}
+}
+
+func additionalAllowed(x openapi3.AdditionalProperties) bool {
+	return x.Has == nil || *x.Has || x.Schema != nil
+}
+
+// patternProperties compares the schemas of the properties matching patterns.
+// A pattern added constrains properties that were allowed, or allows ones that
+// additional properties of the revision forbid, and conversely for removed patterns.
+func (d *differ) patternProperties(s scope, at pointers, base, revision openapi3.Schemas, additional openapi3.AdditionalProperties) {
+	added, removed := d.tightened, d.loosened
+	switch {
+	case !additionalAllowed(additional):
+		added, removed = d.loosened, d.tightened
+	case additional.Schema != nil:
+		added = func(s scope, kind Kind, pointer, format string, args ...any) {
+			d.report(s, kind, pointer, true, format, args...)
+		}
+		removed = added
+	}
+	for _, pattern := range sortedKeys(base) {
+		if _, ok := revision[pattern]; !ok {
+			removed(s, Removed, at.child(pattern).base, "pattern property %q removed", pattern)
+		}
+	}
+	for _, pattern := range sortedKeys(revision) {
+		b, ok := base[pattern]
+		if !ok {
+			added(s, Added, at.child(pattern).revision, "pattern property %q added", pattern)
+			continue
+		}
+		d.schema(s.with(fmt.Sprintf("pattern property %q", pattern)), at.child(pattern), b, revision[pattern])
+	}
+}
+
+func (d *differ) dependentRequired(s scope, at pointers, base, revision map[string][]string) {
+	for _, name := range sortedKeys(base) {
+		if _, ok := revision[name]; !ok {
+			d.loosened(s, Removed, at.child(name).base, "properties required with %q removed", name)
+		}
+	}
+	for _, name := range sortedKeys(revision) {
+		for _, required := range difference(revision[name], base[name]) {
+			d.tightened(s, Changed, at.child(name).revision, "property %q became required with %q", required, name)
+		}
+		for _, optional := range difference(base[name], revision[name]) {
+			d.loosened(s, Changed, at.child(name).revision, "property %q became optional with %q", optional, name)
+		}
+	}
+}
+
+func (d *differ) dependentSchemas(s scope, at pointers, base, revision openapi3.Schemas) {
+	for _, name := range sortedKeys(base) {
+		if _, ok := revision[name]; !ok {
+			d.loosened(s, Removed, at.child(name).base, "schema dependent on %q removed", name)
+		}
+	}
+	for _, name := range sortedKeys(revision) {
+		b, ok := base[name]
+		if !ok {
+			d.tightened(s, Added, at.child(name).revision, "schema dependent on %q added", name)
+			continue
+		}
+		d.schema(s.with(fmt.Sprintf("schema dependent on %q", name)), at.child(name), b, revision[name])
+	}
+}
+
+// discriminator reports the changes of a discriminator. Mapping values to
+// more schemas loosens it, breaking responses with values clients do not know.
+func (d *differ) discriminator(s scope, at pointers, base, revision *openapi3.Discriminator) {
+	switch {
+	case base == nil && revision == nil:
+		return
+	case base == nil:
+		d.tightened(s, Added, at.revision, "discriminator %q added", revision.PropertyName)
+		return
+	case revision == nil:
+		d.loosened(s, Removed, at.base, "discriminator %q removed", base.PropertyName)
+		return
+	case base.PropertyName != revision.PropertyName:
+		d.report(s, Changed, at.revision+"/propertyName", true, "discriminator changed from %q to %q", base.PropertyName, revision.PropertyName)
+		return
+	}
+	mapping := at.child("mapping")
+	for _, value := range sortedKeys(base.Mapping) {
+		if _, ok := revision.Mapping[value]; !ok {
+			d.tightened(s, Removed, mapping.child(value).base, "discriminator value %q removed", value)
+		}
+	}
+	for _, value := range sortedKeys(revision.Mapping) {
+		b, ok := base.Mapping[value]
+		switch {
+		case !ok:
+			d.loosened(s, Added, mapping.child(value).revision, "discriminator value %q added", value)
+		case b != revision.Mapping[value]:
+			d.report(s, Changed, mapping.child(value).revision, true, "discriminator value %q changed from %q to %q", value, b, revision.Mapping[value])
+		}
+	}
+}
+
+// schemas compares the schemas of allOf, anyOf and oneOf by index.
+// Adding schemas to anyOf or oneOf loosens them, whereas adding some to
+// allOf, or adding anyOf or oneOf, tightens the schema.
+func (d *differ) schemas(s scope, at pointers, name string, base, revision openapi3.SchemaRefs) {
+	added, removed := d.tightened, d.loosened
+	if (name == "anyOf" || name == "oneOf") && len(base) != 0 && len(revision) != 0 {
+		added, removed = d.loosened, d.tightened
+	}
+	for i := range revision {
+		index := strconv.Itoa(i)
+		if i >= len(base) {
+			added(s, Added, at.child(index).revision, "%s schema %d added", name, i)
+			continue
+		}
+		d.schema(s.with(name+" "+index), at.child(index), base[i], revision[i])
+	}
+	for i := len(revision); i < len(base); i++ {
+		removed(s, Removed, at.child(strconv.Itoa(i)).base, "%s schema %d removed", name, i)
+	}
+}
+
+func isMultiple(a, b float64) bool {
+	q := a / b
+	return q == math.Trunc(q)
+}
+
+// difference returns the values of a missing from b.
+func difference[V any](a, b []V) []V {
+	var values []V
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if reflect.DeepEqual(x, y) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, x)
+		}
+	}
+	return values
+}
+
+func values(vs []any) string {
+	s := make([]string, 0, len(vs))
+	for _, v := range vs {
+		s = append(s, fmt.Sprintf("%#v", v))
+	}
+	return strings.Join(s, ", ")
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func float(u *uint64) *float64 {
+	if u == nil {
+		return nil
+	}
+	f := float64(*u)
+	return &f
+}
+
+// positive returns the lower bound u, nil when it is the default.
+func positive(u uint64) *float64 {
+	if u == 0 {
+		return nil
+	}
+	return float(&u)
+}
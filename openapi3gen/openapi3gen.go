@@ -313,6 +313,11 @@ func (g *Generator) generateWithoutSaving(parents []*theTypeInfo, t reflect.Type
 
 	case reflect.Map:
 		schema.Type = &openapi3.Types{"object"}
+		propertyNames, err := g.generatePropertyNames(t.Key(), name, tag)
+		if err != nil {
+			return nil, err
+		}
+		schema.PropertyNames = propertyNames
 		additionalProperties, err := g.generateSchemaRefFor(parents, t.Elem(), name, tag)
 		if err != nil {
 			if _, ok := err.(*CycleError); ok && !g.opts.throwErrorOnCycle {
@@ -439,6 +444,33 @@ func (g *Generator) generateWithoutSaving(parents []*theTypeInfo, t reflect.Type
 	return openapi3.NewSchemaRef(t.Name(), schema), nil
 }
 
+// generatePropertyNames describes the keys of a map when their type is a named
+// string type with a known pattern, as set by its SetSchema method or by the
+// schema customizer.
+func (g *Generator) generatePropertyNames(t reflect.Type, name string, tag reflect.StructTag) (*openapi3.SchemaRef, error) {
+	if t.Kind() != reflect.String || t.PkgPath() == "" {
+		return nil, nil
+	}
+
+	schema := openapi3.NewStringSchema()
+	if v, ok := reflect.New(t).Interface().(SetSchemar); ok {
+		v.SetSchema(schema)
+	}
+	if g.opts.schemaCustomizer != nil {
+		if err := g.opts.schemaCustomizer(name, t, tag, schema); err != nil {
+			if _, ok := err.(*ExcludeSchemaSentinel); ok {
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+
+	if schema.Pattern == "" {
+		return nil, nil
+	}
+	return openapi3.NewSchemaRef("", schema), nil
+}
+
 func (g *Generator) generateTypeName(t reflect.Type) string {
 	if g.opts.typeNameGenerator != nil {
 		return g.opts.typeNameGenerator(t)
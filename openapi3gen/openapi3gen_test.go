@@ -634,3 +634,42 @@ func ExampleSetSchemar() {
 	//   "type": "object"
 	// }
 }
+
+type Locale string
+
+func (*Locale) SetSchema(schema *openapi3.Schema) {
+	schema.Pattern = `^[a-z]{2}(-[A-Z]{2})?$`
+}
+
+type Currency string
+
+func TestMapPropertyNames(t *testing.T) {
+	type Catalog struct {
+		Titles map[Locale]string   `json:"titles"`
+		Prices map[Currency]int    `json:"prices"`
+		Labels map[string][]string `json:"labels"`
+	}
+
+	customizer := openapi3gen.SchemaCustomizer(func(name string, ft reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+		if ft == reflect.TypeOf(Currency("")) {
+			schema.Pattern = `^[A-Z]{3}$`
+		}
+		return nil
+	})
+	schemaRef, err := openapi3gen.NewSchemaRefForValue(&Catalog{}, nil, customizer)
+	require.NoError(t, err)
+
+	titles := schemaRef.Value.Properties["titles"].Value
+	require.Equal(t, &openapi3.Schema{
+		Type:    &openapi3.Types{"string"},
+		Pattern: `^[a-z]{2}(-[A-Z]{2})?$`,
+	}, titles.PropertyNames.Value)
+	require.NoError(t, titles.VisitJSON(map[string]any{"en": "Title", "fr-FR": "Titre"}))
+	require.Error(t, titles.VisitJSON(map[string]any{"english": "Title"}))
+
+	prices := schemaRef.Value.Properties["prices"].Value
+	require.Equal(t, `^[A-Z]{3}$`, prices.PropertyNames.Value.Pattern)
+
+	labels := schemaRef.Value.Properties["labels"].Value
+	require.Nil(t, labels.PropertyNames)
+}
@@ -0,0 +1,2 @@
+// Package openapi3merge merges several OpenAPI v3 specification documents into one.
+package openapi3merge
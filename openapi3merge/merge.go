@@ -0,0 +1,775 @@
+package openapi3merge
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ConflictKind is the kind of values that conflict.
+type ConflictKind string
+
+const (
+	// ComponentConflict is a component defined differently under the same name.
+	ComponentConflict = ConflictKind("component")
+	// PathConflict is a path or an operation defined differently, paths with
+	// templates that differ only by the names of their parameters, or path items
+	// with different parameters or servers.
+	PathConflict = ConflictKind("path")
+	// OperationIDConflict is an operationId used by different operations.
+	OperationIDConflict = ConflictKind("operationId")
+)
+
+// Conflict describes values of two documents that cannot be merged as they are.
+type Conflict struct {
+	Kind ConflictKind
+	// Pointer is the JSON pointer of the existing value in the merged document,
+	// such as "/components/schemas/Pet" or "/paths/~1pets/get".
+	Pointer string
+	// Name is the name of the component, the path or the operationId.
+	Name string
+	// ComponentType is the collection of the components, such as "schemas".
+	ComponentType string
+	// Existing is the index of the document the existing value comes from,
+	// Incoming the index of the document being merged.
+	Existing, Incoming int
+	// ExistingValue and IncomingValue are the JSON values of the conflicting
+	// components, path items or operations.
+	ExistingValue, IncomingValue any
+}
+
+// Resolution is how to resolve a conflict.
+type Resolution int
+
+const (
+	// Fail fails merging with a *ConflictError.
+	Fail Resolution = iota
+	// KeepExisting drops the incoming value. References to an incoming component
+	// then refer to the existing one.
+	KeepExisting
+	// KeepIncoming replaces the existing value with the incoming one.
+	KeepIncoming
+	// Rename renames the incoming component or operationId, and its references.
+	// Paths cannot be renamed.
+	Rename
+)
+
+// ConflictError is returned when merging fails because of a conflict.
+type ConflictError struct {
+	Conflict *Conflict
+}
+
+var _ error = (*ConflictError)(nil)
+
+func (err *ConflictError) Error() string {
+	c := err.Conflict
+	return fmt.Sprintf("conflicting %s %q between documents %d and %d at %s", c.Kind, c.Name, c.Existing, c.Incoming, c.Pointer)
+}
+
+// Option allows tweaking merging.
+type Option func(*merger)
+
+// WithResolver sets the function resolving conflicts, which fail merging by default.
+func WithResolver(resolve func(conflict *Conflict) Resolution) Option {
+	return func(m *merger) {
+		m.resolve = resolve
+	}
+}
+
+// WithRenamer sets the function naming components and operationIds resolved with Rename.
+// Names must not be in use already. By default the index of the incoming document
+// is appended to the name, as in "Pet_1".
+func WithRenamer(rename func(conflict *Conflict) string) Option {
+	return func(m *merger) {
+		m.rename = rename
+	}
+}
+
+// Merge merges docs into a new document, with the version and info of the first one.
+//
+// It merges the paths, webhooks, components and tags of the documents.
+// Identical components, path items and operations are merged into one.
+// The merged document has the servers and security requirements of the first one:
+// those of other documents are copied into their operations that do not set their own,
+// when they differ.
+// Components are compared once the references of the incoming document got renamed.
+// Tags are merged by name, keeping the first definition.
+//
+// docs are left untouched. They must not refer to other documents,
+// see openapi3.T.InternalizeRefs. References of the returned document are resolved.
+func Merge(docs []*openapi3.T, opts ...Option) (*openapi3.T, error) {
+	m := &merger{
+		resolve:      func(*Conflict) Resolution { return Fail },
+		rename:       defaultRename,
+		operationIDs: make(map[string]operationOrigin),
+		origins:      make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	for i, doc := range docs {
+		raw, err := toRaw(doc)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.merge(i, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(m.out)
+	if err != nil {
+		return nil, err
+	}
+	out := &openapi3.T{}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	if err := openapi3.NewLoader().ResolveRefsIn(out, nil); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func defaultRename(conflict *Conflict) string {
+	return conflict.Name + "_" + strconv.Itoa(conflict.Incoming)
+}
+
+type merger struct {
+	resolve func(*Conflict) Resolution
+	rename  func(*Conflict) string
+
+	out map[string]any
+	// operationIDs locates the operations of the merged document by operationId.
+	operationIDs map[string]operationOrigin
+	// origins maps the pointers of the merged values to the index of their document.
+	origins map[string]int
+}
+
+type operationOrigin struct {
+	root, path, method string
+}
+
+func toRaw(doc *openapi3.T) (map[string]any, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (m *merger) merge(i int, raw map[string]any) error {
+	if m.out == nil {
+		m.out = map[string]any{"openapi": raw["openapi"], "info": raw["info"]}
+		for _, key := range []string{"servers", "security"} {
+			if value, ok := raw[key]; ok {
+				m.out[key] = value
+			}
+		}
+	}
+
+	if _, err := m.mergeComponents(i, raw); err != nil {
+		return err
+	}
+	m.inheritTopLevel(raw)
+
+	for _, root := range []string{"paths", "webhooks"} {
+		if err := m.mergeOperationIDs(i, root, object(raw, root)); err != nil {
+			return err
+		}
+	}
+	for _, root := range []string{"paths", "webhooks"} {
+		if err := m.mergePaths(i, root, object(raw, root)); err != nil {
+			return err
+		}
+	}
+
+	m.mergeTags(raw)
+	for key, value := range raw {
+		if _, ok := m.out[key]; !ok && strings.HasPrefix(key, "x-") {
+			m.out[key] = value
+		}
+	}
+	return nil
+}
+
+// inheritTopLevel copies the servers and security requirements of raw into
+// its operations that do not set their own, when they differ from those of
+// the merged document, so that these operations keep their servers and security.
+func (m *merger) inheritTopLevel(raw map[string]any) {
+	servers, security := raw["servers"], raw["security"]
+	inheritServers := !reflect.DeepEqual(servers, m.out["servers"])
+	inheritSecurity := !reflect.DeepEqual(security, m.out["security"])
+	if servers == nil {
+		servers = []any{map[string]any{"url": "/"}}
+	}
+	if security == nil {
+		security = []any{}
+	}
+
+	for _, root := range []string{"paths", "webhooks"} {
+		for _, pathItem := range object(raw, root) {
+			pathItem, _ := pathItem.(map[string]any)
+			for method, operation := range pathItem {
+				operation, ok := operation.(map[string]any)
+				if !ok || !isMethod(method) {
+					continue
+				}
+				if _, ok := operation["security"]; !ok && inheritSecurity {
+					operation["security"] = deepCopy(security)
+				}
+				if root != "paths" || !inheritServers {
+					// Servers do not apply to webhooks
+					continue
+				}
+				_, ok = operation["servers"]
+				if _, pathServers := pathItem["servers"]; !ok && !pathServers {
+					operation["servers"] = deepCopy(servers)
+				}
+			}
+		}
+	}
+}
+
+// mergeComponents merges the components of raw, after renaming them and
+// rewriting the references of raw to them, and returns these renames by component type.
+func (m *merger) mergeComponents(i int, raw map[string]any) (map[string]map[string]string, error) {
+	incoming := object(raw, "components")
+	existing := object(m.out, "components")
+
+	renames := make(map[string]map[string]string)
+	decided := make(map[string]Resolution)
+	for {
+		renamed := rewriteRefs(deepCopy(incoming), renames).(map[string]any)
+		renameComponentsSecurity(renamed, renames["securitySchemes"])
+		changed := false
+		for _, typ := range sortedKeys(incoming) {
+			for _, name := range sortedKeys(object(incoming, typ)) {
+				pointer := "/components/" + typ + "/" + escape(name)
+				if _, ok := decided[pointer]; ok {
+					continue
+				}
+				existingValue, ok := object(existing, typ)[name]
+				incomingValue := object(renamed, typ)[name]
+				if !ok || reflect.DeepEqual(existingValue, incomingValue) {
+					continue
+				}
+				conflict := &Conflict{
+					Kind:          ComponentConflict,
+					Pointer:       pointer,
+					Name:          name,
+					ComponentType: typ,
+					Existing:      m.origins[pointer],
+					Incoming:      i,
+					ExistingValue: existingValue,
+					IncomingValue: incomingValue,
+				}
+				resolution, err := m.resolveConflict(conflict)
+				if err != nil {
+					return nil, err
+				}
+				decided[pointer] = resolution
+				if resolution == Rename {
+					if renames[typ] == nil {
+						renames[typ] = make(map[string]string)
+					}
+					newName := m.rename(conflict)
+					if _, ok := object(existing, typ)[newName]; ok {
+						return nil, fmt.Errorf("cannot rename %s to %q: name in use", pointer, newName)
+					}
+					renames[typ][name] = newName
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	// Rewrite the references of the whole document
+	for key, value := range raw {
+		raw[key] = rewriteRefs(value, renames)
+	}
+	renameDocumentSecurity(raw, renames["securitySchemes"])
+
+	for _, typ := range sortedKeys(incoming) {
+		components := object(raw["components"].(map[string]any), typ)
+		for _, name := range sortedKeys(components) {
+			pointer := "/components/" + typ + "/" + escape(name)
+			newName := name
+			if renamed, ok := renames[typ][name]; ok {
+				newName = renamed
+			} else if decided[pointer] == KeepExisting {
+				continue
+			}
+			if _, ok := object(existing, typ)[newName]; ok && decided[pointer] != KeepIncoming {
+				// Identical
+				continue
+			}
+			if existing == nil {
+				existing = make(map[string]any)
+				m.out["components"] = existing
+			}
+			if object(existing, typ) == nil {
+				existing[typ] = make(map[string]any)
+			}
+			existing[typ].(map[string]any)[newName] = components[name]
+			m.origins["/components/"+typ+"/"+escape(newName)] = i
+		}
+	}
+	return renames, nil
+}
+
+// mergeOperationIDs resolves the conflicts between the operationIds
+// of the operations in paths, the paths or webhooks of the document i, and those merged.
+func (m *merger) mergeOperationIDs(i int, root string, paths map[string]any) error {
+	for _, path := range sortedKeys(paths) {
+		pathItem, _ := paths[path].(map[string]any)
+		for _, method := range sortedKeys(pathItem) {
+			operation, ok := pathItem[method].(map[string]any)
+			if !ok || !isMethod(method) {
+				continue
+			}
+			id, _ := operation["operationId"].(string)
+			origin, ok := m.operationIDs[id]
+			if id == "" || !ok {
+				continue
+			}
+			existingItem, _ := object(m.out, origin.root)[origin.path].(map[string]any)
+			existingOperation := existingItem[origin.method]
+			if origin.root == root && normalizePath(origin.path) == normalizePath(path) && origin.method == method {
+				// Merged along its path
+				continue
+			}
+
+			pointer := "/" + origin.root + "/" + escape(origin.path) + "/" + origin.method
+			conflict := &Conflict{
+				Kind:          OperationIDConflict,
+				Pointer:       pointer,
+				Name:          id,
+				Existing:      m.origins[pointer],
+				Incoming:      i,
+				ExistingValue: existingOperation,
+				IncomingValue: operation,
+			}
+			resolution, err := m.resolveConflict(conflict)
+			if err != nil {
+				return err
+			}
+			switch resolution {
+			case KeepExisting:
+				delete(pathItem, method)
+			case KeepIncoming:
+				delete(existingItem, origin.method)
+				delete(m.operationIDs, id)
+			case Rename:
+				newID := m.rename(conflict)
+				if _, ok := m.operationIDs[newID]; ok {
+					return fmt.Errorf("cannot rename operationId %q to %q: operationId in use", id, newID)
+				}
+				operation["operationId"] = newID
+				renameLinks(paths, id, newID)
+			}
+		}
+	}
+	return nil
+}
+
+// mergePaths merges paths, the paths or webhooks of the document i.
+func (m *merger) mergePaths(i int, root string, paths map[string]any) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	out := object(m.out, root)
+	if out == nil {
+		out = make(map[string]any)
+		m.out[root] = out
+	}
+	templates := make(map[string]string, len(out))
+	for path := range out {
+		templates[normalizePath(path)] = path
+	}
+
+	for _, path := range sortedKeys(paths) {
+		pathItem, _ := paths[path].(map[string]any)
+		pointer := "/" + root + "/" + escape(path)
+		existingPath, ok := templates[normalizePath(path)]
+		if !ok {
+			out[path] = pathItem
+			m.addOperations(i, root, path, pathItem)
+			continue
+		}
+
+		existingPointer := "/" + root + "/" + escape(existingPath)
+		existingItem, _ := out[existingPath].(map[string]any)
+		if existingPath != path {
+			conflict := &Conflict{
+				Kind:          PathConflict,
+				Pointer:       existingPointer,
+				Name:          path,
+				Existing:      m.origins[existingPointer],
+				Incoming:      i,
+				ExistingValue: existingItem,
+				IncomingValue: pathItem,
+			}
+			resolution, err := m.resolveConflict(conflict)
+			if err != nil {
+				return err
+			}
+			if resolution == KeepIncoming {
+				m.removeOperations(root, existingPath, existingItem)
+				delete(out, existingPath)
+				out[path] = pathItem
+				templates[normalizePath(path)] = path
+				m.addOperations(i, root, path, pathItem)
+			}
+			continue
+		}
+
+		for _, key := range []string{"parameters", "servers"} {
+			existingValue, value := existingItem[key], pathItem[key]
+			if reflect.DeepEqual(existingValue, value) {
+				continue
+			}
+			conflict := &Conflict{
+				Kind:          PathConflict,
+				Pointer:       pointer + "/" + key,
+				Name:          path,
+				Existing:      m.origins[pointer],
+				Incoming:      i,
+				ExistingValue: existingValue,
+				IncomingValue: value,
+			}
+			resolution, err := m.resolveConflict(conflict)
+			if err != nil {
+				return err
+			}
+			if resolution == KeepIncoming {
+				if value == nil {
+					delete(existingItem, key)
+				} else {
+					existingItem[key] = value
+				}
+			}
+		}
+
+		for _, key := range sortedKeys(pathItem) {
+			value := pathItem[key]
+			existingValue, ok := existingItem[key]
+			switch {
+			case key == "parameters" || key == "servers":
+			case !ok:
+				existingItem[key] = value
+				if isMethod(key) {
+					m.addOperation(i, root, path, key, value)
+				}
+			case reflect.DeepEqual(existingValue, value):
+			case !isMethod(key):
+				// Keep the existing summary or description
+			default:
+				operationPointer := pointer + "/" + key
+				conflict := &Conflict{
+					Kind:          PathConflict,
+					Pointer:       operationPointer,
+					Name:          path,
+					Existing:      m.origins[operationPointer],
+					Incoming:      i,
+					ExistingValue: existingValue,
+					IncomingValue: value,
+				}
+				resolution, err := m.resolveConflict(conflict)
+				if err != nil {
+					return err
+				}
+				if resolution == KeepIncoming {
+					if id, _ := existingValue.(map[string]any)["operationId"].(string); id != "" {
+						delete(m.operationIDs, id)
+					}
+					existingItem[key] = value
+					m.addOperation(i, root, path, key, value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (m *merger) addOperations(i int, root, path string, pathItem map[string]any) {
+	m.origins["/"+root+"/"+escape(path)] = i
+	for method, operation := range pathItem {
+		if isMethod(method) {
+			m.addOperation(i, root, path, method, operation)
+		}
+	}
+}
+
+func (m *merger) addOperation(i int, root, path, method string, operation any) {
+	m.origins["/"+root+"/"+escape(path)+"/"+method] = i
+	if op, ok := operation.(map[string]any); ok {
+		if id, _ := op["operationId"].(string); id != "" {
+			m.operationIDs[id] = operationOrigin{root: root, path: path, method: method}
+		}
+	}
+}
+
+func (m *merger) removeOperations(root, path string, pathItem map[string]any) {
+	for method, operation := range pathItem {
+		if op, ok := operation.(map[string]any); ok && isMethod(method) {
+			if id, _ := op["operationId"].(string); id != "" {
+				delete(m.operationIDs, id)
+			}
+		}
+	}
+}
+
+func (m *merger) mergeTags(raw map[string]any) {
+	tags, _ := m.out["tags"].([]any)
+	names := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		if name, ok := tag.(map[string]any)["name"].(string); ok {
+			names[name] = struct{}{}
+		}
+	}
+	incoming, _ := raw["tags"].([]any)
+	for _, tag := range incoming {
+		name, _ := tag.(map[string]any)["name"].(string)
+		if _, ok := names[name]; !ok {
+			names[name] = struct{}{}
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) != 0 {
+		m.out["tags"] = tags
+	}
+}
+
+func (m *merger) resolveConflict(conflict *Conflict) (Resolution, error) {
+	resolution := m.resolve(conflict)
+	switch {
+	case resolution == Fail:
+		return Fail, &ConflictError{Conflict: conflict}
+	case resolution == Rename && conflict.Kind == PathConflict:
+		return Fail, fmt.Errorf("cannot rename path %q: %w", conflict.Name, &ConflictError{Conflict: conflict})
+	}
+	return resolution, nil
+}
+
+// rewriteRefs renames the components that v refers to.
+func rewriteRefs(v any, renames map[string]map[string]string) any {
+	if len(renames) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch key {
+			case "$ref":
+				if ref, ok := value.(string); ok {
+					v[key] = renameRef(ref, renames)
+				}
+			case "discriminator":
+				if discriminator, ok := value.(map[string]any); ok {
+					mapping, _ := discriminator["mapping"].(map[string]any)
+					for k, target := range mapping {
+						if target, ok := target.(string); ok {
+							if renamed, ok := renames["schemas"][target]; ok {
+								mapping[k] = renamed
+							} else {
+								mapping[k] = renameRef(target, renames)
+							}
+						}
+					}
+				}
+			default:
+				v[key] = rewriteRefs(value, renames)
+			}
+		}
+		if components, ok := v["components"].(map[string]any); ok {
+			// Rename the components themselves
+			for typ, names := range renames {
+				collection, _ := components[typ].(map[string]any)
+				for name, newName := range names {
+					if value, ok := collection[name]; ok {
+						delete(collection, name)
+						collection[newName] = value
+					}
+				}
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = rewriteRefs(value, renames)
+		}
+	}
+	return v
+}
+
+func renameRef(ref string, renames map[string]map[string]string) string {
+	const prefix = "#/components/"
+	if !strings.HasPrefix(ref, prefix) {
+		return ref
+	}
+	typ, rest, ok := strings.Cut(strings.TrimPrefix(ref, prefix), "/")
+	if !ok {
+		return ref
+	}
+	name, suffix, _ := strings.Cut(rest, "/")
+	if newName, ok := renames[typ][unescape(name)]; ok {
+		ref = prefix + typ + "/" + escape(newName)
+		if suffix != "" {
+			ref += "/" + suffix
+		}
+	}
+	return ref
+}
+
+// renameDocumentSecurity renames the security schemes of the security requirements
+// of the document raw and of its operations.
+func renameDocumentSecurity(raw map[string]any, renames map[string]string) {
+	if len(renames) == 0 {
+		return
+	}
+	renameSecurity(raw["security"], renames)
+	for _, root := range []string{"paths", "webhooks"} {
+		for _, pathItem := range object(raw, root) {
+			renamePathItemSecurity(pathItem, renames)
+		}
+	}
+	renameComponentsSecurity(object(raw, "components"), renames)
+}
+
+// renameComponentsSecurity renames the security schemes of the security requirements
+// of the operations of the path item and callback components.
+func renameComponentsSecurity(components map[string]any, renames map[string]string) {
+	if len(renames) == 0 {
+		return
+	}
+	for _, pathItem := range object(components, "pathItems") {
+		renamePathItemSecurity(pathItem, renames)
+	}
+	for _, callback := range object(components, "callbacks") {
+		renameCallbackSecurity(callback, renames)
+	}
+}
+
+func renamePathItemSecurity(pathItem any, renames map[string]string) {
+	item, _ := pathItem.(map[string]any)
+	for method, operation := range item {
+		if operation, ok := operation.(map[string]any); ok && isMethod(method) {
+			renameSecurity(operation["security"], renames)
+			for _, callback := range object(operation, "callbacks") {
+				renameCallbackSecurity(callback, renames)
+			}
+		}
+	}
+}
+
+func renameCallbackSecurity(callback any, renames map[string]string) {
+	pathItems, _ := callback.(map[string]any)
+	for _, pathItem := range pathItems {
+		renamePathItemSecurity(pathItem, renames)
+	}
+}
+
+// renameSecurity renames the security schemes of security requirements.
+func renameSecurity(v any, renames map[string]string) {
+	requirements, _ := v.([]any)
+	for _, requirement := range requirements {
+		if requirement, ok := requirement.(map[string]any); ok {
+			for name, newName := range renames {
+				if scopes, ok := requirement[name]; ok {
+					delete(requirement, name)
+					requirement[newName] = scopes
+				}
+			}
+		}
+	}
+}
+
+// renameLinks renames the operationId the links of v refer to.
+func renameLinks(v any, id, newID string) {
+	switch v := v.(type) {
+	case map[string]any:
+		if links, ok := v["links"].(map[string]any); ok {
+			for _, link := range links {
+				if link, ok := link.(map[string]any); ok && link["operationId"] == id {
+					link["operationId"] = newID
+				}
+			}
+		}
+		for _, value := range v {
+			renameLinks(value, id, newID)
+		}
+	case []any:
+		for _, value := range v {
+			renameLinks(value, id, newID)
+		}
+	}
+}
+
+var pathParameter = regexp.MustCompile(`\{[^}]*\}`)
+
+// normalizePath returns path with its parameters unnamed.
+func normalizePath(path string) string {
+	return pathParameter.ReplaceAllString(path, "{}")
+}
+
+func isMethod(key string) bool {
+	switch key {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	}
+	return false
+}
+
+func object(m map[string]any, key string) map[string]any {
+	v, _ := m[key].(map[string]any)
+	return v
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[k] = deepCopy(value)
+		}
+		return m
+	case []any:
+		s := make([]any, 0, len(v))
+		for _, value := range v {
+			s = append(s, deepCopy(value))
+		}
+		return s
+	}
+	return v
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,318 @@
+package openapi3merge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const petsSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+servers: [{url: 'https://api.example.com'}]
+tags: [{name: pets}]
+security: [{apiKey: []}]
+paths:
+  /pets/{id}:
+    get:
+      operationId: getItem
+      parameters: [{$ref: '#/components/parameters/Id'}]
+      responses:
+        '200':
+          description: a pet
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Item'}
+components:
+  parameters:
+    Id: {name: id, in: path, required: true, schema: {type: string}}
+  schemas:
+    Error:
+      type: object
+      properties:
+        message: {type: string}
+    Item:
+      type: object
+      properties:
+        name: {type: string}
+  securitySchemes:
+    apiKey: {type: apiKey, name: X-Api-Key, in: header}
+`
+
+const storesSpec = `
+openapi: 3.0.3
+info: {title: Stores, version: 2.0.0}
+servers: [{url: 'https://api.example.com'}, {url: 'https://stores.example.com'}]
+tags: [{name: pets, description: ignored}, {name: stores}]
+security: [{apiKey: []}]
+paths:
+  /stores/{storeId}:
+    get:
+      operationId: getItem
+      parameters:
+      - {name: storeId, in: path, required: true, schema: {type: string}}
+      responses:
+        '200':
+          description: a store
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Store'}
+components:
+  schemas:
+    Error:
+      type: object
+      properties:
+        message: {type: string}
+    Item:
+      type: object
+      properties:
+        address: {type: string}
+    Store:
+      type: object
+      properties:
+        items:
+          type: array
+          items: {$ref: '#/components/schemas/Item'}
+  securitySchemes:
+    apiKey: {type: http, scheme: bearer}
+`
+
+func loadDocs(t *testing.T, specs ...string) []*openapi3.T {
+	loader := openapi3.NewLoader()
+	docs := make([]*openapi3.T, 0, len(specs))
+	for _, spec := range specs {
+		doc, err := loader.LoadFromData([]byte(spec))
+		require.NoError(t, err)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func TestMerge(t *testing.T) {
+	docs := loadDocs(t, petsSpec, storesSpec)
+
+	var conflicts []string
+	merged, err := Merge(docs, WithResolver(func(conflict *Conflict) Resolution {
+		conflicts = append(conflicts, string(conflict.Kind)+" "+conflict.Pointer)
+		return Rename
+	}))
+	require.NoError(t, err)
+	require.NoError(t, merged.Validate(context.Background()))
+	require.Equal(t, []string{
+		"component /components/schemas/Item",
+		"component /components/securitySchemes/apiKey",
+		"operationId /paths/~1pets~1{id}/get",
+	}, conflicts)
+
+	require.Equal(t, "Pets", merged.Info.Title)
+	require.Len(t, merged.Servers, 1)
+	require.Len(t, merged.Tags, 2)
+	require.Empty(t, merged.Tags.Get("pets").Description)
+	require.Equal(t, openapi3.SecurityRequirements{{"apiKey": {}}}, merged.Security)
+
+	schemas := merged.Components.Schemas
+	require.Equal(t, []string{"Error", "Item", "Item_1", "Store"}, sortedKeys(schemas))
+	items := schemas["Store"].Value.Properties["items"].Value.Items
+	require.Equal(t, "#/components/schemas/Item_1", items.Ref)
+	require.Same(t, schemas["Item_1"].Value, items.Value)
+	require.Contains(t, merged.Components.SecuritySchemes, "apiKey_1")
+
+	pets := merged.Paths.Value("/pets/{id}").Get
+	require.Equal(t, "getItem", pets.OperationID)
+	require.Nil(t, pets.Security)
+	require.Nil(t, pets.Servers)
+	stores := merged.Paths.Value("/stores/{storeId}").Get
+	require.Equal(t, "getItem_1", stores.OperationID)
+	// Operations keep the servers and security requirements of their document
+	require.Equal(t, &openapi3.SecurityRequirements{{"apiKey_1": {}}}, stores.Security)
+	require.Len(t, *stores.Servers, 2)
+
+	// Inputs are left untouched
+	require.Equal(t, "#/components/schemas/Item", docs[1].Components.Schemas["Store"].Value.Properties["items"].Value.Items.Ref)
+}
+
+func TestMergeIdentical(t *testing.T) {
+	docs := loadDocs(t, petsSpec, petsSpec)
+	merged, err := Merge(docs)
+	require.NoError(t, err)
+	require.NoError(t, merged.Validate(context.Background()))
+	require.Equal(t, 1, merged.Paths.Len())
+	require.Len(t, merged.Components.Schemas, 2)
+	require.Len(t, merged.Security, 1)
+	require.Nil(t, merged.Paths.Value("/pets/{id}").Get.Security)
+}
+
+func TestMergeTopLevel(t *testing.T) {
+	const public = `
+openapi: 3.0.3
+info: {title: Public, version: 1.0.0}
+paths:
+  /status:
+    get:
+      responses:
+        default: {description: the status}
+  /admin:
+    servers: [{url: 'https://admin.example.com'}]
+    get:
+      security: [{}]
+      responses:
+        default: {description: admin}
+`
+	docs := loadDocs(t, petsSpec, public)
+	merged, err := Merge(docs)
+	require.NoError(t, err)
+	require.NoError(t, merged.Validate(context.Background()))
+	require.Equal(t, openapi3.SecurityRequirements{{"apiKey": {}}}, merged.Security)
+	require.Equal(t, "https://api.example.com", merged.Servers[0].URL)
+
+	status := merged.Paths.Value("/status").Get
+	require.Equal(t, &openapi3.SecurityRequirements{}, status.Security)
+	require.Equal(t, &openapi3.Servers{{URL: "/"}}, status.Servers)
+	admin := merged.Paths.Value("/admin").Get
+	require.Equal(t, &openapi3.SecurityRequirements{{}}, admin.Security)
+	require.Nil(t, admin.Servers)
+}
+
+func TestMergeSecurityProperty(t *testing.T) {
+	const other = `
+openapi: 3.0.3
+info: {title: Other, version: 1.0.0}
+security: [{apiKey: []}]
+paths:
+  /accounts:
+    get:
+      security: [{apiKey: [read]}]
+      responses:
+        '200':
+          description: an account
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Account'}
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        price: {type: number}
+    Account:
+      type: object
+      properties:
+        security:
+          type: object
+          properties:
+            apiKey: {$ref: '#/components/schemas/Item'}
+  securitySchemes:
+    apiKey: {type: oauth2, flows: {implicit: {authorizationUrl: 'https://example.com', scopes: {read: read}}}}
+`
+	docs := loadDocs(t, petsSpec, other)
+	merged, err := Merge(docs, WithResolver(func(*Conflict) Resolution { return Rename }))
+	require.NoError(t, err)
+	require.NoError(t, merged.Validate(context.Background()))
+
+	security := merged.Components.Schemas["Account"].Value.Properties["security"].Value
+	require.Equal(t, "#/components/schemas/Item_1", security.Properties["apiKey"].Ref)
+	require.Equal(t, &openapi3.SecurityRequirements{{"apiKey_1": {"read"}}}, merged.Paths.Value("/accounts").Get.Security)
+}
+
+func TestMergeConflictError(t *testing.T) {
+	docs := loadDocs(t, petsSpec, storesSpec)
+	_, err := Merge(docs)
+	require.EqualError(t, err, `conflicting component "Item" between documents 0 and 1 at /components/schemas/Item`)
+	var conflictErr *ConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	require.Equal(t, "schemas", conflictErr.Conflict.ComponentType)
+}
+
+func TestMergeKeep(t *testing.T) {
+	docs := loadDocs(t, petsSpec, storesSpec)
+	merged, err := Merge(docs, WithResolver(func(conflict *Conflict) Resolution {
+		if conflict.Kind == OperationIDConflict {
+			return KeepExisting
+		}
+		return KeepIncoming
+	}))
+	require.NoError(t, err)
+	require.NoError(t, merged.Validate(context.Background()))
+	require.Contains(t, merged.Components.Schemas["Item"].Value.Properties, "address")
+	require.Equal(t, "http", merged.Components.SecuritySchemes["apiKey"].Value.Type)
+	require.Nil(t, merged.Paths.Value("/stores/{storeId}").Get)
+}
+
+func TestMergePaths(t *testing.T) {
+	const other = `
+openapi: 3.0.3
+info: {title: Other, version: 1.0.0}
+paths:
+  /pets/{petId}:
+    get:
+      parameters:
+      - {name: petId, in: path, required: true, schema: {type: string}}
+      responses:
+        default: {description: a pet}
+`
+	docs := loadDocs(t, petsSpec, other)
+
+	_, err := Merge(docs, WithResolver(func(*Conflict) Resolution { return Rename }))
+	require.EqualError(t, err, `cannot rename path "/pets/{petId}": conflicting path "/pets/{petId}" between documents 0 and 1 at /paths/~1pets~1{id}`)
+
+	merged, err := Merge(docs, WithResolver(func(*Conflict) Resolution { return KeepIncoming }))
+	require.NoError(t, err)
+	require.NoError(t, merged.Validate(context.Background()))
+	require.Nil(t, merged.Paths.Value("/pets/{id}"))
+	require.NotNil(t, merged.Paths.Value("/pets/{petId}"))
+}
+
+func TestMergePathItems(t *testing.T) {
+	const other = `
+openapi: 3.0.3
+info: {title: Other, version: 1.0.0}
+servers: [{url: 'https://api.example.com'}]
+security: [{apiKey: []}]
+paths:
+  /pets/{id}:
+    parameters: [{$ref: '#/components/parameters/Id'}]
+    delete:
+      responses:
+        default: {description: deleted}
+components:
+  parameters:
+    Id: {name: id, in: path, required: true, schema: {type: string}}
+  securitySchemes:
+    apiKey: {type: apiKey, name: X-Api-Key, in: header}
+`
+	docs := loadDocs(t, petsSpec, other)
+
+	_, err := Merge(docs)
+	require.EqualError(t, err, `conflicting path "/pets/{id}" between documents 0 and 1 at /paths/~1pets~1{id}/parameters`)
+
+	merged, err := Merge(docs, WithResolver(func(*Conflict) Resolution { return KeepExisting }))
+	require.NoError(t, err)
+	pathItem := merged.Paths.Value("/pets/{id}")
+	require.Empty(t, pathItem.Parameters)
+	require.NotNil(t, pathItem.Get)
+	require.NotNil(t, pathItem.Delete)
+}
+
+func TestMergeRenamer(t *testing.T) {
+	docs := loadDocs(t, petsSpec, storesSpec)
+	merged, err := Merge(docs,
+		WithResolver(func(*Conflict) Resolution { return Rename }),
+		WithRenamer(func(conflict *Conflict) string {
+			return docs[conflict.Incoming].Info.Title + conflict.Name
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, merged.Validate(context.Background()))
+	require.Contains(t, merged.Components.Schemas, "StoresItem")
+	require.Equal(t, "StoresgetItem", merged.Paths.Value("/stores/{storeId}").Get.OperationID)
+
+	_, err = Merge(docs,
+		WithResolver(func(*Conflict) Resolution { return Rename }),
+		WithRenamer(func(*Conflict) string { return "Error" }),
+	)
+	require.EqualError(t, err, `cannot rename /components/schemas/Item to "Error": name in use`)
+}
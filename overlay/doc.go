@@ -0,0 +1,5 @@
+// Package overlay applies OpenAPI Overlay 1.0 documents, which describe changes
+// to OpenAPI documents as actions on the values that JSONPath expressions select.
+//
+// See https://spec.openapis.org/overlay/v1.0.0.html
+package overlay
@@ -0,0 +1,760 @@
+package overlay
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// path is a JSONPath query, as defined by RFC 9535 without function extensions.
+type path struct {
+	segments []segment
+}
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+// node is a value of a document, along with the means to change it.
+type node struct {
+	value any
+	// set replaces the value in its parent.
+	set func(any)
+	// remove removes the value from its parent, nil for the root.
+	remove func()
+}
+
+type selector interface {
+	// selectFrom appends to out the children of n it selects.
+	selectFrom(n *node, root any, out []*node) []*node
+}
+
+// removed replaces the items removed from arrays until they get compacted,
+// so that the indexes of the other items stay the same.
+var removed any = new(int)
+
+func compilePath(expr string) (*path, error) {
+	p := &parser{expr: expr}
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with $")
+	}
+	segments, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos != len(p.expr) {
+		return nil, p.errorf("unexpected %q", p.expr[p.pos:])
+	}
+	return &path{segments: segments}, nil
+}
+
+// selectNodes returns the nodes of the document root the path selects.
+func (path *path) selectNodes(root *node) []*node {
+	return path.selectFrom(root, root.value)
+}
+
+func (path *path) selectFrom(start *node, root any) []*node {
+	nodes := []*node{start}
+	for _, segment := range path.segments {
+		var selected []*node
+		for _, n := range nodes {
+			inputs := []*node{n}
+			if segment.descendant {
+				inputs = descendants(n, inputs[:0])
+			}
+			for _, input := range inputs {
+				for _, selector := range segment.selectors {
+					selected = selector.selectFrom(input, root, selected)
+				}
+			}
+		}
+		nodes = selected
+	}
+	return nodes
+}
+
+// singular tells whether the path selects at most one node.
+func (path *path) singular() bool {
+	for _, segment := range path.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		switch segment.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// children appends the children of n to out, object members in key order.
+func children(n *node, out []*node) []*node {
+	switch v := n.value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			out = append(out, member(v, key))
+		}
+	case []any:
+		for i := range v {
+			if v[i] != removed {
+				out = append(out, item(v, i))
+			}
+		}
+	}
+	return out
+}
+
+// descendants appends n and its descendants to out, parents first.
+func descendants(n *node, out []*node) []*node {
+	out = append(out, n)
+	for _, child := range children(n, nil) {
+		out = descendants(child, out)
+	}
+	return out
+}
+
+func member(m map[string]any, key string) *node {
+	return &node{
+		value:  m[key],
+		set:    func(v any) { m[key] = v },
+		remove: func() { delete(m, key) },
+	}
+}
+
+func item(s []any, i int) *node {
+	return &node{
+		value:  s[i],
+		set:    func(v any) { s[i] = v },
+		remove: func() { s[i] = removed },
+	}
+}
+
+type nameSelector string
+
+func (name nameSelector) selectFrom(n *node, root any, out []*node) []*node {
+	if m, ok := n.value.(map[string]any); ok {
+		if _, ok := m[string(name)]; ok {
+			out = append(out, member(m, string(name)))
+		}
+	}
+	return out
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) selectFrom(n *node, root any, out []*node) []*node {
+	return children(n, out)
+}
+
+type indexSelector int
+
+func (index indexSelector) selectFrom(n *node, root any, out []*node) []*node {
+	if s, ok := n.value.([]any); ok {
+		i := int(index)
+		if i < 0 {
+			i += len(s)
+		}
+		if 0 <= i && i < len(s) && s[i] != removed {
+			out = append(out, item(s, i))
+		}
+	}
+	return out
+}
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (slice sliceSelector) selectFrom(n *node, root any, out []*node) []*node {
+	s, ok := n.value.([]any)
+	if !ok || slice.step == 0 {
+		return out
+	}
+	length := len(s)
+	bound := func(i *int, def, lower, upper int) int {
+		if i == nil {
+			return def
+		}
+		v := *i
+		if v < 0 {
+			v += length
+		}
+		if v < lower {
+			return lower
+		}
+		if v > upper {
+			return upper
+		}
+		return v
+	}
+	if slice.step > 0 {
+		start, end := bound(slice.start, 0, 0, length), bound(slice.end, length, 0, length)
+		for i := start; i < end; i += slice.step {
+			if s[i] != removed {
+				out = append(out, item(s, i))
+			}
+		}
+		return out
+	}
+	start, end := bound(slice.start, length-1, -1, length-1), bound(slice.end, -1, -1, length-1)
+	for i := start; end < i; i += slice.step {
+		if s[i] != removed {
+			out = append(out, item(s, i))
+		}
+	}
+	return out
+}
+
+type filterSelector struct {
+	expr expr
+}
+
+func (filter filterSelector) selectFrom(n *node, root any, out []*node) []*node {
+	for _, child := range children(n, nil) {
+		if filter.expr.test(child, root) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// expr is a logical expression of a filter selector.
+type expr interface {
+	test(current *node, root any) bool
+}
+
+type orExpr []expr
+
+func (or orExpr) test(current *node, root any) bool {
+	for _, e := range or {
+		if e.test(current, root) {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr []expr
+
+func (and andExpr) test(current *node, root any) bool {
+	for _, e := range and {
+		if !e.test(current, root) {
+			return false
+		}
+	}
+	return true
+}
+
+type notExpr struct {
+	expr expr
+}
+
+func (not notExpr) test(current *node, root any) bool {
+	return !not.expr.test(current, root)
+}
+
+// filterQuery is a query relative to the current node, or absolute if it starts with $.
+type filterQuery struct {
+	absolute bool
+	path     *path
+}
+
+func (query *filterQuery) selectNodes(current *node, root any) []*node {
+	if query.absolute {
+		return query.path.selectFrom(&node{value: root}, root)
+	}
+	return query.path.selectFrom(current, root)
+}
+
+// test tells whether the query selects a node.
+func (query *filterQuery) test(current *node, root any) bool {
+	return len(query.selectNodes(current, root)) != 0
+}
+
+// comparable is a literal or a singular query.
+type comparable interface {
+	// value returns false when a query selects no node.
+	value(current *node, root any) (any, bool)
+}
+
+type literal struct {
+	v any
+}
+
+func (l literal) value(*node, any) (any, bool) {
+	return l.v, true
+}
+
+func (query *filterQuery) value(current *node, root any) (any, bool) {
+	if nodes := query.selectNodes(current, root); len(nodes) == 1 {
+		return nodes[0].value, true
+	}
+	return nil, false
+}
+
+type comparison struct {
+	left, right comparable
+	op          string
+}
+
+func (c comparison) test(current *node, root any) bool {
+	left, lok := c.left.value(current, root)
+	right, rok := c.right.value(current, root)
+	switch c.op {
+	case "==":
+		return equal(left, lok, right, rok)
+	case "!=":
+		return !equal(left, lok, right, rok)
+	case "<":
+		return less(left, lok, right, rok)
+	case "<=":
+		return less(left, lok, right, rok) || equal(left, lok, right, rok)
+	case ">":
+		return less(right, rok, left, lok)
+	default: // ">="
+		return less(right, rok, left, lok) || equal(left, lok, right, rok)
+	}
+}
+
+func equal(a any, aok bool, b any, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func less(a any, aok bool, b any, bok bool) bool {
+	if !aok || !bok {
+		return false
+	}
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x < y
+	}
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		return ok && x < y
+	}
+	return false
+}
+
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+type parser struct {
+	expr string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid JSONPath %q at offset %d: %s", p.expr, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.expr) && strings.IndexByte(" \t\n\r", p.expr[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) segments() ([]segment, error) {
+	var segments []segment
+	for {
+		start := p.pos
+		p.skipSpaces()
+		var seg segment
+		var err error
+		switch {
+		case p.consume(".."):
+			seg.descendant = true
+			if p.peek() == '[' {
+				seg.selectors, err = p.bracket()
+			} else {
+				seg.selectors, err = p.shorthand()
+			}
+		case p.consume("."):
+			seg.selectors, err = p.shorthand()
+		case p.peek() == '[':
+			seg.selectors, err = p.bracket()
+		default:
+			p.pos = start
+			return segments, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+}
+
+// shorthand parses the wildcard or member name following a dot.
+func (p *parser) shorthand() ([]selector, error) {
+	if p.consume("*") {
+		return []selector{wildcardSelector{}}, nil
+	}
+	name := p.name()
+	if name == "" {
+		return nil, p.errorf("expected a member name")
+	}
+	return []selector{nameSelector(name)}, nil
+}
+
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		isDigit := '0' <= r && r <= '9'
+		if !(r == '_' || r == '-' || r >= 0x80 || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || isDigit && p.pos != start) {
+			break
+		}
+		p.pos += size
+	}
+	return p.expr[start:p.pos]
+}
+
+func (p *parser) bracket() ([]selector, error) {
+	p.consume("[")
+	var selectors []selector
+	for {
+		p.skipSpaces()
+		s, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, s)
+		p.skipSpaces()
+		if p.consume("]") {
+			return selectors, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *parser) selector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector(s), nil
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr: e}, nil
+	}
+
+	start, err := p.optionalInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.consume(":") {
+		if start == nil {
+			return nil, p.errorf("expected a selector")
+		}
+		return indexSelector(*start), nil
+	}
+	slice := sliceSelector{start: start, step: 1}
+	p.skipSpaces()
+	if slice.end, err = p.optionalInt(); err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.consume(":") {
+		p.skipSpaces()
+		step, err := p.optionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if step != nil {
+			slice.step = *step
+		}
+	}
+	return slice, nil
+}
+
+func (p *parser) optionalInt() (*int, error) {
+	start := p.pos
+	p.consume("-")
+	for '0' <= p.peek() && p.peek() <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		return nil, p.errorf("invalid integer %q", p.expr[start:p.pos])
+	}
+	return &i, nil
+}
+
+// string parses a single or double quoted string literal.
+func (p *parser) string() (string, error) {
+	quote := p.expr[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		p.pos++
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if p.pos == len(p.expr) {
+				return "", p.errorf("unterminated string")
+			}
+			escaped := p.expr[p.pos]
+			p.pos++
+			switch escaped {
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.expr) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				p.pos += 4
+				b.WriteRune(rune(r))
+			case '\\', '/', '\'', '"':
+				b.WriteByte(escaped)
+			default:
+				return "", p.errorf("invalid escape \\%c", escaped)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) or() (expr, error) {
+	var or orExpr
+	for {
+		e, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, e)
+		p.skipSpaces()
+		if !p.consume("||") {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *parser) and() (expr, error) {
+	var and andExpr
+	for {
+		e, err := p.basic()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, e)
+		p.skipSpaces()
+		if !p.consume("&&") {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *parser) basic() (expr, error) {
+	p.skipSpaces()
+	if p.consume("!") {
+		p.skipSpaces()
+		var e expr
+		var err error
+		if p.peek() == '(' {
+			e, err = p.paren()
+		} else {
+			e, err = p.query()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: e}, nil
+	}
+	if p.peek() == '(' {
+		return p.paren()
+	}
+
+	var left comparable
+	if c := p.peek(); c == '@' || c == '$' {
+		query, err := p.query()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.comparisonOperator() == "" {
+			// Existence test
+			return query, nil
+		}
+		if !query.path.singular() {
+			return nil, p.errorf("only singular queries can be compared")
+		}
+		left = query
+	} else {
+		var err error
+		if left, err = p.comparable(); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+	}
+
+	op := p.comparisonOperator()
+	if op == "" {
+		return nil, p.errorf("expected a comparison")
+	}
+	p.pos += len(op)
+	p.skipSpaces()
+	right, err := p.comparable()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{left: left, right: right, op: op}, nil
+}
+
+func (p *parser) comparisonOperator() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.expr[p.pos:], op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *parser) paren() (expr, error) {
+	p.consume("(")
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.consume(")") {
+		return nil, p.errorf("expected )")
+	}
+	return e, nil
+}
+
+func (p *parser) query() (*filterQuery, error) {
+	query := &filterQuery{}
+	switch {
+	case p.consume("@"):
+	case p.consume("$"):
+		query.absolute = true
+	default:
+		return nil, p.errorf("expected @ or $")
+	}
+	segments, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+	query.path = &path{segments: segments}
+	return query, nil
+}
+
+func (p *parser) comparable() (comparable, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		query, err := p.query()
+		if err != nil {
+			return nil, err
+		}
+		if !query.path.singular() {
+			return nil, p.errorf("only singular queries can be compared")
+		}
+		return query, nil
+	case c == '\'' || c == '"':
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return literal{v: s}, nil
+	case p.consume("true"):
+		return literal{v: true}, nil
+	case p.consume("false"):
+		return literal{v: false}, nil
+	case p.consume("null"):
+		return literal{v: nil}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.expr) && strings.IndexByte("+-.0123456789eE", p.expr[p.pos]) >= 0 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.expr[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		if name := p.name(); name != "" && p.peek() == '(' {
+			return nil, p.errorf("unsupported function %s", name)
+		}
+		return nil, p.errorf("expected a literal or a query")
+	}
+	return literal{v: f}, nil
+}
@@ -0,0 +1,79 @@
+package overlay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	var doc any
+	err := json.Unmarshal([]byte(`{
+  "store": {
+    "book": [
+      {"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+      {"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+      {"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+      {"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+    ],
+    "bicycle": {"color": "red", "price": 399}
+  },
+  "x-rate": 10
+}`), &doc)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		query    string
+		expected []any
+	}{
+		{`$.store.book[*].author`, []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{`$..author`, []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{`$.store.*.color`, []any{"red"}},
+		{`$['store']["bicycle"]['price']`, []any{float64(399)}},
+		{`$.x-rate`, []any{float64(10)}},
+		{`$..book[2].title`, []any{"Moby Dick"}},
+		{`$..book[-1].title`, []any{"The Lord of the Rings"}},
+		{`$..book[0,1].title`, []any{"Sayings of the Century", "Sword of Honour"}},
+		{`$..book[:2].title`, []any{"Sayings of the Century", "Sword of Honour"}},
+		{`$..book[::-2].title`, []any{"The Lord of the Rings", "Sword of Honour"}},
+		{`$..book[?@.isbn].title`, []any{"Moby Dick", "The Lord of the Rings"}},
+		{`$..book[?(!@.isbn)].title`, []any{"Sayings of the Century", "Sword of Honour"}},
+		{`$..book[?@.price < 10].title`, []any{"Sayings of the Century", "Moby Dick"}},
+		{`$..book[?@.category == 'fiction' && @.price > $.x-rate].title`, []any{"Sword of Honour", "The Lord of the Rings"}},
+		{`$..book[?@.author == "Nigel Rees" || @.price >= 22.99].title`, []any{"Sayings of the Century", "The Lord of the Rings"}},
+		{`$..book[?@.missing == @.absent].title`, []any{"Sayings of the Century", "Sword of Honour", "Moby Dick", "The Lord of the Rings"}},
+		{`$.store.bicycle.weight`, nil},
+		{`$.store.book[10]`, nil},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			path, err := compilePath(tc.query)
+			require.NoError(t, err)
+			var got []any
+			for _, n := range path.selectNodes(&node{value: doc}) {
+				got = append(got, n.value)
+			}
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		err   string
+	}{
+		{`store`, `invalid JSONPath "store" at offset 0: query must start with $`},
+		{`$.`, `invalid JSONPath "$." at offset 2: expected a member name`},
+		{`$[1`, `invalid JSONPath "$[1" at offset 3: expected , or ]`},
+		{`$['a]`, `invalid JSONPath "$['a]" at offset 5: unterminated string`},
+		{`$[?@..a == 1]`, `invalid JSONPath "$[?@..a == 1]" at offset 8: only singular queries can be compared`},
+		{`$[?length(@) == 1]`, `invalid JSONPath "$[?length(@) == 1]" at offset 9: unsupported function length`},
+		{`$[?1]`, `invalid JSONPath "$[?1]" at offset 4: expected a comparison`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := compilePath(tc.query)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package overlay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/invopop/yaml"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Overlay is an OpenAPI Overlay document.
+// See https://spec.openapis.org/overlay/v1.0.0.html#overlay-object
+type Overlay struct {
+	Extensions map[string]any `json:"-" yaml:"-"`
+
+	Overlay string   `json:"overlay" yaml:"overlay"` // Required
+	Info    *Info    `json:"info" yaml:"info"`       // Required
+	Extends string   `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Actions []Action `json:"actions" yaml:"actions"` // Required
+}
+
+// Info is the metadata of an overlay.
+// See https://spec.openapis.org/overlay/v1.0.0.html#info-object
+type Info struct {
+	Extensions map[string]any `json:"-" yaml:"-"`
+
+	Title   string `json:"title" yaml:"title"`     // Required
+	Version string `json:"version" yaml:"version"` // Required
+}
+
+// Action changes the values its target selects.
+// See https://spec.openapis.org/overlay/v1.0.0.html#action-object
+type Action struct {
+	Extensions map[string]any `json:"-" yaml:"-"`
+
+	// Target is a JSONPath query selecting the values to change.
+	Target      string `json:"target" yaml:"target"` // Required
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Update is merged into the selected objects, or appended to the selected arrays.
+	Update any `json:"update,omitempty" yaml:"update,omitempty"`
+	// Remove removes the selected values, in which case Update is ignored.
+	Remove bool `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// Load returns the overlay of data, a JSON or YAML document.
+func Load(data []byte) (*Overlay, error) {
+	overlay := &Overlay{}
+	if err := yaml.Unmarshal(data, overlay); err != nil {
+		return nil, err
+	}
+	return overlay, nil
+}
+
+// LoadFile returns the overlay of the JSON or YAML file at path.
+func LoadFile(path string) (*Overlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Load(data)
+}
+
+// MarshalJSON returns the JSON encoding of Overlay.
+func (overlay Overlay) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, 4+len(overlay.Extensions))
+	for k, v := range overlay.Extensions {
+		m[k] = v
+	}
+	m["overlay"] = overlay.Overlay
+	m["info"] = overlay.Info
+	if x := overlay.Extends; x != "" {
+		m["extends"] = x
+	}
+	m["actions"] = overlay.Actions
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON sets Overlay to a copy of data.
+func (overlay *Overlay) UnmarshalJSON(data []byte) error {
+	type OverlayBis Overlay
+	var x OverlayBis
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	_ = json.Unmarshal(data, &x.Extensions)
+	delete(x.Extensions, "overlay")
+	delete(x.Extensions, "info")
+	delete(x.Extensions, "extends")
+	delete(x.Extensions, "actions")
+	if len(x.Extensions) == 0 {
+		x.Extensions = nil
+	}
+	*overlay = Overlay(x)
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of Info.
+func (info Info) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, 2+len(info.Extensions))
+	for k, v := range info.Extensions {
+		m[k] = v
+	}
+	m["title"] = info.Title
+	m["version"] = info.Version
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON sets Info to a copy of data.
+func (info *Info) UnmarshalJSON(data []byte) error {
+	type InfoBis Info
+	var x InfoBis
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	_ = json.Unmarshal(data, &x.Extensions)
+	delete(x.Extensions, "title")
+	delete(x.Extensions, "version")
+	if len(x.Extensions) == 0 {
+		x.Extensions = nil
+	}
+	*info = Info(x)
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of Action.
+func (action Action) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, 4+len(action.Extensions))
+	for k, v := range action.Extensions {
+		m[k] = v
+	}
+	m["target"] = action.Target
+	if x := action.Description; x != "" {
+		m["description"] = x
+	}
+	if x := action.Update; x != nil {
+		m["update"] = x
+	}
+	if x := action.Remove; x {
+		m["remove"] = x
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON sets Action to a copy of data.
+func (action *Action) UnmarshalJSON(data []byte) error {
+	type ActionBis Action
+	var x ActionBis
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	_ = json.Unmarshal(data, &x.Extensions)
+	delete(x.Extensions, "target")
+	delete(x.Extensions, "description")
+	delete(x.Extensions, "update")
+	delete(x.Extensions, "remove")
+	if len(x.Extensions) == 0 {
+		x.Extensions = nil
+	}
+	*action = Action(x)
+	return nil
+}
+
+// Validate returns an error if Overlay does not comply with the Overlay specification.
+func (overlay *Overlay) Validate() error {
+	if !strings.HasPrefix(overlay.Overlay, "1.0.") {
+		return fmt.Errorf("unsupported overlay version %q", overlay.Overlay)
+	}
+	if overlay.Info == nil {
+		return errors.New("value of info must be an object")
+	}
+	if overlay.Info.Title == "" {
+		return errors.New("value of info title must be a non-empty string")
+	}
+	if overlay.Info.Version == "" {
+		return errors.New("value of info version must be a non-empty string")
+	}
+	if len(overlay.Actions) == 0 {
+		return errors.New("value of actions must be a non-empty array")
+	}
+	for i, action := range overlay.Actions {
+		if err := action.validate(); err != nil {
+			return fmt.Errorf("invalid action %d: %w", i, err)
+		}
+	}
+	return validateExtensions(overlay.Extensions)
+}
+
+func (action *Action) validate() error {
+	if action.Target == "" {
+		return errors.New("value of target must be a non-empty string")
+	}
+	if _, err := compilePath(action.Target); err != nil {
+		return err
+	}
+	if action.Update == nil && !action.Remove {
+		return errors.New("either update or remove must be set")
+	}
+	return validateExtensions(action.Extensions)
+}
+
+func validateExtensions(extensions map[string]any) error {
+	for k := range extensions {
+		if !strings.HasPrefix(k, "x-") {
+			return fmt.Errorf("unsupported field %q", k)
+		}
+	}
+	return nil
+}
+
+// Report tells what the actions of an overlay changed.
+type Report struct {
+	Actions []ActionReport
+}
+
+// ActionReport tells what an action changed.
+type ActionReport struct {
+	// Index is the index of the action in the overlay.
+	Index int
+	// Target is the target of the action.
+	Target string
+	// Matched is the number of values the target selected.
+	Matched int
+}
+
+// Unmatched returns the reports of the actions whose target selected nothing.
+func (report *Report) Unmatched() []ActionReport {
+	var unmatched []ActionReport
+	for _, action := range report.Actions {
+		if action.Matched == 0 {
+			unmatched = append(unmatched, action)
+		}
+	}
+	return unmatched
+}
+
+// Apply applies the actions of the overlay in order to doc, a document decoded
+// from JSON or YAML into map[string]any, []any and scalar values,
+// and returns the changed document. doc is left untouched.
+func (overlay *Overlay) Apply(doc any) (any, *Report, error) {
+	root := &node{value: deepCopy(doc)}
+	root.set = func(v any) { root.value = v }
+
+	report := &Report{Actions: make([]ActionReport, 0, len(overlay.Actions))}
+	for i, action := range overlay.Actions {
+		target, err := compilePath(action.Target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid action %d: %w", i, err)
+		}
+		nodes := target.selectNodes(root)
+		report.Actions = append(report.Actions, ActionReport{Index: i, Target: action.Target, Matched: len(nodes)})
+
+		switch {
+		case action.Remove:
+			for _, n := range nodes {
+				if n.remove == nil {
+					return nil, nil, fmt.Errorf("invalid action %d: cannot remove the root of the document", i)
+				}
+				n.remove()
+			}
+			root.value = compact(root.value)
+		case action.Update != nil:
+			for _, n := range nodes {
+				update(n, action.Update)
+			}
+		}
+	}
+	return root.value, report, nil
+}
+
+// ApplyToData applies the overlay to data, a JSON or YAML document,
+// and returns the changed document as JSON. Apply overlays this way
+// before resolving references:
+//
+//	data, report, err := overlay.ApplyToData(data)
+//	...
+//	doc, err := loader.LoadFromData(data)
+func (overlay *Overlay) ApplyToData(data []byte) ([]byte, *Report, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	changed, report, err := overlay.Apply(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = json.Marshal(changed); err != nil {
+		return nil, nil, err
+	}
+	return data, report, nil
+}
+
+// ApplyToDocument applies the overlay to doc, usually after resolving its references,
+// and returns the changed document. Targets select references as $ref objects,
+// not the values they refer to. doc is left untouched.
+// References of the returned document are not resolved, see openapi3.Loader.ResolveRefsIn.
+func (overlay *Overlay) ApplyToDocument(doc *openapi3.T) (*openapi3.T, *Report, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+	changed, report, err := overlay.Apply(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = json.Marshal(changed); err != nil {
+		return nil, nil, err
+	}
+	out := &openapi3.T{}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, nil, err
+	}
+	return out, report, nil
+}
+
+// update merges value into the object n, appends it to the array n,
+// or replaces n otherwise.
+func update(n *node, value any) {
+	switch target := n.value.(type) {
+	case map[string]any:
+		if m, ok := value.(map[string]any); ok {
+			merge(target, m)
+			return
+		}
+	case []any:
+		n.set(append(target, deepCopy(value)))
+		return
+	}
+	n.set(deepCopy(value))
+}
+
+// merge merges the members of src into dst, recursively for objects.
+func merge(dst, src map[string]any) {
+	for k, v := range src {
+		if d, ok := dst[k].(map[string]any); ok {
+			if s, ok := v.(map[string]any); ok {
+				merge(d, s)
+				continue
+			}
+		}
+		dst[k] = deepCopy(v)
+	}
+}
+
+// compact drops the removed items of the arrays of v.
+func compact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			v[k] = compact(value)
+		}
+	case []any:
+		items := v[:0]
+		for _, value := range v {
+			if value != removed {
+				items = append(items, compact(value))
+			}
+		}
+		return items
+	}
+	return v
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[k] = deepCopy(value)
+		}
+		return m
+	case []any:
+		s := make([]any, 0, len(v))
+		for _, value := range v {
+			s = append(s, deepCopy(value))
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,146 @@
+package overlay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var spec = []byte(`
+openapi: 3.0.3
+info:
+  title: Vendor API
+  version: 1.0.0
+tags:
+  - name: pets
+paths:
+  /pets:
+    get:
+      summary: List pets
+      x-internal: true
+      responses:
+        '200':
+          description: Pets
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pets'
+    delete:
+      x-internal: true
+      responses:
+        '204':
+          description: Deleted
+components:
+  schemas:
+    Pets:
+      type: array
+      items:
+        type: string
+`[1:])
+
+var patch = []byte(`
+overlay: 1.0.0
+info:
+  title: Public API
+  version: 1.0.0
+actions:
+  - target: $.info
+    update:
+      title: Public API
+      contact:
+        name: Support
+  - target: $.tags
+    update:
+      name: owners
+  - target: $.paths.*[?@.x-internal == true && @.summary]
+    update:
+      deprecated: true
+  - target: $.paths.*.delete
+    remove: true
+  - target: $.webhooks
+    description: Vendors may add webhooks
+    remove: true
+`[1:])
+
+func TestApplyToData(t *testing.T) {
+	overlay, err := Load(patch)
+	require.NoError(t, err)
+	require.NoError(t, overlay.Validate())
+
+	data, report, err := overlay.ApplyToData(spec)
+	require.NoError(t, err)
+	require.Equal(t, []ActionReport{{Index: 4, Target: "$.webhooks"}}, report.Unmatched())
+	require.Equal(t, 1, report.Actions[2].Matched)
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(data)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	require.Equal(t, "Public API", doc.Info.Title)
+	require.Equal(t, "Support", doc.Info.Contact.Name)
+	require.Equal(t, "1.0.0", doc.Info.Version)
+	require.Len(t, doc.Tags, 2)
+	require.Equal(t, "owners", doc.Tags[1].Name)
+	pets := doc.Paths.Value("/pets")
+	require.True(t, pets.Get.Deprecated)
+	require.Nil(t, pets.Delete)
+	// References got resolved after applying the overlay
+	require.Equal(t, &openapi3.Types{"array"}, pets.Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Value.Type)
+}
+
+func TestApplyToDocument(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	require.NoError(t, err)
+
+	overlay, err := Load(patch)
+	require.NoError(t, err)
+	changed, report, err := overlay.ApplyToDocument(doc)
+	require.NoError(t, err)
+	require.Len(t, report.Unmatched(), 1)
+	require.NoError(t, loader.ResolveRefsIn(changed, nil))
+
+	require.Equal(t, "Vendor API", doc.Info.Title)
+	require.NotNil(t, doc.Paths.Value("/pets").Delete)
+	require.Equal(t, "Public API", changed.Info.Title)
+	require.Nil(t, changed.Paths.Value("/pets").Delete)
+	require.Equal(t, "#/components/schemas/Pets", changed.Paths.Value("/pets").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Ref)
+	require.NotNil(t, changed.Paths.Value("/pets").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Value)
+}
+
+func TestApplyLeavesDocumentUntouched(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{"tags": [{"name": "a"}, {"name": "b"}, {"name": "c"}]}`), &doc))
+	overlay := &Overlay{Actions: []Action{
+		{Target: `$.tags[?@.name != 'b']`, Remove: true},
+		{Target: `$.tags`, Update: map[string]any{"name": "d"}},
+	}}
+	changed, _, err := overlay.Apply(doc)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"tags": []any{map[string]any{"name": "b"}, map[string]any{"name": "d"}}}, changed)
+	require.Len(t, doc.(map[string]any)["tags"], 3)
+
+	_, _, err = (&Overlay{Actions: []Action{{Target: `$`, Remove: true}}}).Apply(doc)
+	require.EqualError(t, err, `invalid action 0: cannot remove the root of the document`)
+}
+
+func TestValidate(t *testing.T) {
+	overlay, err := Load([]byte(`{
+  "overlay": "1.0.0",
+  "info": {"title": "Fixes", "version": "1"},
+  "actions": [{"target": "$.paths[", "remove": true}]
+}`))
+	require.NoError(t, err)
+	require.EqualError(t, overlay.Validate(), `invalid action 0: invalid JSONPath "$.paths[" at offset 8: expected a selector`)
+
+	overlay.Actions[0] = Action{Target: "$.paths"}
+	require.EqualError(t, overlay.Validate(), `invalid action 0: either update or remove must be set`)
+
+	overlay.Overlay = "2.0.0"
+	require.EqualError(t, overlay.Validate(), `unsupported overlay version "2.0.0"`)
+}